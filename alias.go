@@ -0,0 +1,68 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandLineAliases registers user-defined aliases that expand to command-lines before parsing, like git's
+// aliases, eg. {"co": "checkout --fancy"} expands "app co main" to "app checkout --fancy main".
+//
+// Aliases are typically loaded from a configuration file. An alias is only expanded as the first argument, and its
+// expansion, which is split into words with shell-style quoting, may itself start with another alias. An alias may
+// not shadow a top-level command, nor expand to itself either directly or indirectly.
+func CommandLineAliases(aliases map[string]string) Option {
+	return PostBuild(func(k *Kong) error {
+		if k.aliases == nil {
+			k.aliases = map[string][]string{}
+		}
+		for name, expansion := range aliases {
+			for _, child := range k.Model.Children {
+				if child.Type == CommandNode && (child.Name == name || hasAlias(child, name)) {
+					return fmt.Errorf("alias %q shadows a command", name)
+				}
+			}
+			words, err := splitShellWords(expansion)
+			if err != nil {
+				return fmt.Errorf("alias %q: %w", name, err)
+			}
+			if len(words) == 0 {
+				return fmt.Errorf("alias %q is empty", name)
+			}
+			k.aliases[name] = words
+		}
+		return nil
+	})
+}
+
+// Aliases returns the command-line aliases registered with CommandLineAliases, and their expansions.
+func (k *Kong) Aliases() map[string]string {
+	out := map[string]string{}
+	for name, words := range k.aliases {
+		quoted := make([]string, len(words))
+		for i, word := range words {
+			quoted[i] = quoteArg(word)
+		}
+		out[name] = strings.Join(quoted, " ")
+	}
+	return out
+}
+
+// expandAliases expands any alias at the start of "args".
+func (k *Kong) expandAliases(args []string) ([]string, error) {
+	expanded := []string{}
+	for len(args) > 0 {
+		words, ok := k.aliases[args[0]]
+		if !ok {
+			break
+		}
+		for _, name := range expanded {
+			if name == args[0] {
+				return nil, fmt.Errorf("alias %q expands to itself", args[0])
+			}
+		}
+		expanded = append(expanded, args[0])
+		args = append(append([]string{}, words...), args[1:]...)
+	}
+	return args, nil
+}
@@ -0,0 +1,45 @@
+package kong_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestCommandLineAliases(t *testing.T) {
+	var cli struct {
+		Checkout struct {
+			Fancy  bool
+			Branch string `arg:""`
+		} `cmd:""`
+	}
+	p := mustNew(t, &cli, kong.CommandLineAliases(map[string]string{
+		"co":   "checkout --fancy",
+		"main": "co main",
+		"a":    "b",
+		"b":    "a",
+	}))
+	_, err := p.Parse([]string{"co", "dev"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Checkout.Fancy)
+	assert.Equal(t, "dev", cli.Checkout.Branch)
+
+	_, err = p.Parse([]string{"main"})
+	assert.NoError(t, err)
+	assert.Equal(t, "main", cli.Checkout.Branch)
+
+	_, err = p.Parse([]string{"a"})
+	assert.EqualError(t, err, `alias "a" expands to itself`)
+	var parseErr *kong.ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, kong.ExitUsageError, parseErr.Category())
+	assert.Equal(t, 80, parseErr.ExitCode())
+
+	assert.Equal(t, map[string]string{"co": "checkout --fancy", "main": "co main", "a": "b", "b": "a"}, p.Aliases())
+
+	_, err = kong.New(&cli, kong.CommandLineAliases(map[string]string{"checkout": "checkout --fancy"}))
+	assert.EqualError(t, err, `alias "checkout" shadows a command`)
+}
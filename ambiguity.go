@@ -0,0 +1,115 @@
+package kong
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Ambiguity is a command-line that is parsed differently than a user may expect, reported by Ambiguities.
+type Ambiguity struct {
+	// Path is the full path of the node the ambiguity was found on.
+	Path string
+	// Example is an example command-line fragment that is ambiguous.
+	Example string
+	// Message describes how the example is parsed.
+	Message string
+}
+
+func (a Ambiguity) String() string {
+	return a.Path + ": " + a.Example + ": " + a.Message
+}
+
+// Ambiguities analyses a Kong model and reports command-lines that are ambiguous under the options the parser was
+// constructed with, so that user experience hazards can be detected before shipping. Like Lint, it is intended to
+// be called from tests.
+//
+// The following are reported:
+//
+//   - short flags taking a value, which swallow any short flags clustered after them, eg. "-ov"
+//   - signed numeric flags and positional arguments, whose negative values are parsed as short flags
+//   - short flags that are digits, which can not be given if negative numbers are parsed as values
+func Ambiguities(app *Application) []Ambiguity {
+	negativeValues, hyphenated := false, false
+	if app.parser != nil {
+		negativeValues = app.parser.negativeNumbersAreValues()
+		hyphenated = app.parser.allowHyphenated
+	}
+	ambiguities := []Ambiguity{}
+	_ = Visit(app, func(v Visitable, next Next) error {
+		node, ok := v.(*Node)
+		if !ok {
+			if app, ok := v.(*Application); ok {
+				node = app.Node
+			} else {
+				return next(nil)
+			}
+		}
+		report := func(example, format string, args ...any) {
+			ambiguities = append(ambiguities, Ambiguity{Path: node.FullPath(), Example: example, Message: fmt.Sprintf(format, args...)})
+		}
+		inherited := []*Flag{}
+		for parent := node.Parent; parent != nil; parent = parent.Parent {
+			inherited = append(inherited, parent.Flags...)
+		}
+		ambiguousClusters(node.Flags, inherited, report)
+		for _, flag := range node.Flags {
+			switch {
+			case flag.Short >= '0' && flag.Short <= '9' && negativeValues:
+				report("-"+string(flag.Short), "parsed as a negative number, so -%c can not be given", flag.Short)
+			case !negativeValues && !hyphenated && !flag.IsBool() && !flag.IsCounter() && isSignedNumber(flag.Target.Type()):
+				report("--"+flag.Name+" -1", "-1 is parsed as a short flag; use --%s=-1", flag.Name)
+			}
+		}
+		for _, positional := range node.Positional {
+			if !negativeValues && isSignedNumber(positional.Target.Type()) {
+				report("-1", "a negative <%s> is parsed as a short flag", positional.Name)
+			}
+		}
+		return next(nil)
+	})
+	return ambiguities
+}
+
+// ambiguousClusters reports short flags taking a value that swallow boolean short flags clustered after them.
+//
+// Pairs where both flags are inherited are reported on the ancestor declaring them.
+func ambiguousClusters(declared, inherited []*Flag, report func(example, format string, args ...any)) {
+	type pair struct{ value, bool *Flag }
+	pairs := []pair{}
+	scope := append(append([]*Flag{}, declared...), inherited...)
+	for i, flag := range scope {
+		if flag.Short == 0 || flag.IsBool() || flag.IsCounter() {
+			continue
+		}
+		var swallowed *Flag
+		for j, other := range scope {
+			if other.Short == 0 || !(other.IsBool() || other.IsCounter()) || (i >= len(declared) && j >= len(declared)) {
+				continue
+			}
+			if swallowed == nil || other.Short < swallowed.Short {
+				swallowed = other
+			}
+		}
+		if swallowed != nil {
+			pairs = append(pairs, pair{flag, swallowed})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].value.Short < pairs[j].value.Short })
+	for _, p := range pairs {
+		report(fmt.Sprintf("-%c%c", p.value.Short, p.bool.Short), "sets -%c to %q rather than also setting -%c",
+			p.value.Short, string(p.bool.Short), p.bool.Short)
+	}
+}
+
+// isSignedNumber returns true if "typ", or its element type, is a signed integer or float.
+func isSignedNumber(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		return isSignedNumber(typ.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,46 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestAmbiguities(t *testing.T) {
+	var cli struct {
+		Output  string `short:"o"`
+		Verbose bool   `short:"v"`
+		Offset  int
+		Sub     struct {
+			All   bool `short:"a"`
+			Count int  `arg:""`
+		} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	issues := []string{}
+	for _, ambiguity := range kong.Ambiguities(p.Model) {
+		issues = append(issues, ambiguity.String())
+	}
+	assert.Equal(t, []string{
+		`test: -oh: sets -o to "h" rather than also setting -h`,
+		`test: --offset -1: -1 is parsed as a short flag; use --offset=-1`,
+		`test sub: -oa: sets -o to "a" rather than also setting -a`,
+		`test sub: -1: a negative <count> is parsed as a short flag`,
+	}, issues)
+
+	_, err := p.Parse([]string{"-oh", "sub", "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "h", cli.Output)
+
+	p = mustNew(t, &cli, kong.NegativeNumbers(kong.NegativeNumbersAsValues))
+	issues = []string{}
+	for _, ambiguity := range kong.Ambiguities(p.Model) {
+		issues = append(issues, ambiguity.String())
+	}
+	assert.Equal(t, []string{
+		`test: -oh: sets -o to "h" rather than also setting -h`,
+		`test sub: -oa: sets -o to "a" rather than also setting -a`,
+	}, issues)
+}
@@ -0,0 +1,48 @@
+package kong
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Applets selects the grammar from "applets" named after the binary the application was invoked as, in the style of
+// busybox, where eg. "ls" is a symlink to a binary containing many tools. All applets share the options passed to
+// New().
+//
+// If the binary name isn't that of an applet, the grammar passed to New() is used as a fallback. If that is nil, the
+// fallback has each applet as a command, eg. "busybox ls -l".
+func Applets(applets map[string]any) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.applets = applets
+		return nil
+	})
+}
+
+// appletGrammar returns the grammar to build, selected with Applets, or "grammar".
+func (k *Kong) appletGrammar(grammar any) any {
+	if k.applets == nil {
+		return grammar
+	}
+	if applet, ok := k.applets[appletName(os.Args[0])]; ok {
+		return applet
+	}
+	if grammar != nil {
+		return grammar
+	}
+	names := make([]string, 0, len(k.applets))
+	for name := range k.applets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		k.dynamicCommands = append(k.dynamicCommands, &dynamicCommand{name: name, cmd: k.applets[name]})
+	}
+	return &struct{}{}
+}
+
+// appletName returns the name of the applet invoked by the command "arg0", eg. "ls" for "/bin/ls" or "ls.exe".
+func appletName(arg0 string) string {
+	return strings.TrimSuffix(filepath.Base(arg0), ".exe")
+}
@@ -0,0 +1,64 @@
+package kong_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type lsApplet struct {
+	Long  bool     `short:"l"`
+	Paths []string `arg:"" optional:""`
+}
+
+type catApplet struct {
+	Files []string `arg:""`
+}
+
+func withArg0(t *testing.T, arg0 string) {
+	t.Helper()
+	args := os.Args
+	t.Cleanup(func() { os.Args = args })
+	os.Args = append([]string{arg0}, args[1:]...)
+}
+
+func TestApplets(t *testing.T) {
+	ls := &lsApplet{}
+	withArg0(t, "/usr/bin/ls")
+	p, err := kong.New(nil, kong.Applets(map[string]any{"ls": ls, "cat": &catApplet{}}))
+	assert.NoError(t, err)
+	assert.Equal(t, "ls", p.Model.Name)
+	_, err = p.Parse([]string{"-l", "/tmp"})
+	assert.NoError(t, err)
+	assert.Equal(t, &lsApplet{Long: true, Paths: []string{"/tmp"}}, ls)
+}
+
+func TestAppletsCombinedFallback(t *testing.T) {
+	ls := &lsApplet{}
+	cat := &catApplet{}
+	withArg0(t, "busybox.exe")
+	p := mustNew(t, nil, kong.Applets(map[string]any{"ls": ls, "cat": cat}))
+	ctx, err := p.Parse([]string{"cat", "a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "cat <files>", ctx.Command())
+	assert.Equal(t, []string{"a", "b"}, cat.Files)
+
+	ctx, err = p.Parse([]string{"ls", "-l"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ls", ctx.Command())
+	assert.True(t, ls.Long)
+}
+
+func TestAppletsGrammarFallback(t *testing.T) {
+	var cli struct {
+		Version bool
+	}
+	withArg0(t, "tools")
+	p := mustNew(t, &cli, kong.Applets(map[string]any{"ls": &lsApplet{}}))
+	_, err := p.Parse([]string{"--version"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Version)
+}
@@ -0,0 +1,42 @@
+package kong
+
+import (
+	"time"
+)
+
+// AuditRecord describes a command run with Context.Run, reported by AuditLog.
+type AuditRecord struct {
+	// Command is the selected command path, eg. "user create <id>".
+	Command string
+	// Flags maps the names of the flags on the command path to their values, with secrets redacted.
+	Flags map[string]string
+	// Start is when the command started running.
+	Start time.Time
+	// Duration is how long the command ran for.
+	Duration time.Duration
+	// Err is the error returned by the command or its AfterRun hooks, if any.
+	Err error
+}
+
+// AuditLog calls "log" with an AuditRecord each time a command completes, or panics, in Context.Run, eg. for
+// centralised audit logging of administrative CLIs.
+//
+// It is called synchronously, after any AfterRun hooks.
+func AuditLog(log func(AuditRecord)) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.auditLog = log
+		return nil
+	})
+}
+
+// newAuditRecord creates an AuditRecord for the command about to be run by "c", without its duration or error.
+func (c *Context) newAuditRecord() AuditRecord {
+	record := AuditRecord{Command: c.Command(), Flags: map[string]string{}, Start: time.Now()}
+	for _, flag := range c.Flags() {
+		if flag == c.Kong.helpFlag {
+			continue
+		}
+		record.Flags[flag.Name] = flag.FormatValue(flag.Target)
+	}
+	return record
+}
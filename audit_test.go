@@ -0,0 +1,50 @@
+package kong_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type auditCreateCmd struct {
+	ID    string `arg:""`
+	Token string `secret:""`
+	Fail  bool
+}
+
+func (c *auditCreateCmd) Run() error {
+	if c.Fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestAuditLog(t *testing.T) {
+	var cli struct {
+		Region string `default:"eu"`
+		User   struct {
+			Create auditCreateCmd `cmd:""`
+		} `cmd:""`
+	}
+	var records []kong.AuditRecord
+	p := mustNew(t, &cli, kong.AuditLog(func(record kong.AuditRecord) {
+		records = append(records, record)
+	}))
+	ctx, err := p.Parse([]string{"user", "create", "alice", "--token=hunter2"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	ctx, err = p.Parse([]string{"user", "create", "bob", "--fail"})
+	assert.NoError(t, err)
+	assert.Error(t, ctx.Run())
+
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, "user create <id>", records[0].Command)
+	assert.Equal(t, map[string]string{"region": "eu", "token": "********", "fail": "false"}, records[0].Flags)
+	assert.NoError(t, records[0].Err)
+	assert.False(t, records[0].Start.IsZero())
+	assert.EqualError(t, records[1].Err, "failed")
+	assert.Equal(t, "true", records[1].Flags["fail"])
+}
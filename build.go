@@ -3,6 +3,7 @@ package kong
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -18,17 +19,22 @@ func build(k *Kong, ast any) (app *Application, err error) {
 		return nil, fmt.Errorf("expected a pointer to a struct but got %T", ast)
 	}
 
-	app = &Application{}
+	app = &Application{parser: k}
 	extraFlags := k.extraFlags()
 	seenFlags := map[string]bool{}
+	k.builtinKeys, k.shadowedBuiltins = map[string]bool{}, map[string]bool{}
 	for _, flag := range extraFlags {
-		seenFlags[flag.Name] = true
+		for _, key := range flag.keys() {
+			seenFlags[key] = true
+			k.builtinKeys[key] = true
+		}
 	}
 
 	node, err := buildNode(k, iv, ApplicationNode, newEmptyTag(), seenFlags)
 	if err != nil {
 		return nil, err
 	}
+	extraFlags = k.stripShadowedBuiltins(extraFlags)
 	if len(node.Positional) > 0 && len(node.Children) > 0 {
 		return nil, fmt.Errorf("can't mix positional arguments and branching arguments on %T", ast)
 	}
@@ -49,7 +55,7 @@ type flattenedField struct {
 	tag   *Tag
 }
 
-func flattenedFields(v reflect.Value, ptag *Tag) (out []flattenedField, err error) {
+func flattenedFields(k *Kong, v reflect.Value, ptag *Tag) (out []flattenedField, err error) {
 	v = reflect.Indirect(v)
 	if v.Kind() != reflect.Struct {
 		return out, nil
@@ -58,7 +64,7 @@ func flattenedFields(v reflect.Value, ptag *Tag) (out []flattenedField, err erro
 	for i := 0; i < v.NumField(); i++ {
 		ft := v.Type().Field(i)
 		fv := v.Field(i)
-		tag, err := parseTag(v, ft)
+		tag, err := parseTag(k, v, ft)
 		if err != nil {
 			return nil, err
 		}
@@ -70,12 +76,19 @@ func flattenedFields(v reflect.Value, ptag *Tag) (out []flattenedField, err erro
 		if tag.Group == "" {
 			tag.Group = ptag.Group
 		}
-		// Accumulate prefixes.
+		// Combine parent vars.
+		tag.Vars = ptag.Vars.CloneWith(tag.Vars)
+		// Interpolate and accumulate prefixes, so one embedded type can produce several groups of flags.
+		for _, prefix := range []*string{&tag.Prefix, &tag.EnvPrefix, &tag.XorPrefix} {
+			if *prefix, err = interpolate(*prefix, k.withLazyVars(k.vars.CloneWith(tag.Vars), *prefix), nil); err != nil {
+				return nil, failField(v, ft, "prefix: %s", err)
+			}
+		}
 		tag.Prefix = ptag.Prefix + tag.Prefix
 		tag.EnvPrefix = ptag.EnvPrefix + tag.EnvPrefix
+		tag.envPrefixed = ptag.envPrefixed || (tag.Cmd && tag.Has("envprefix"))
 		tag.XorPrefix = ptag.XorPrefix + tag.XorPrefix
-		// Combine parent vars.
-		tag.Vars = ptag.Vars.CloneWith(tag.Vars)
+		tag.indexed, tag.instance = ptag.indexed, ptag.instance
 		// Command and embedded structs can be pointers, so we hydrate them now.
 		if (tag.Cmd || tag.Embed) && ft.Type.Kind() == reflect.Ptr {
 			fv = reflect.New(ft.Type.Elem()).Elem()
@@ -89,12 +102,38 @@ func flattenedFields(v reflect.Value, ptag *Tag) (out []flattenedField, err erro
 			continue
 		}
 
+		// Embedded slice of structs, with an instance for each index.
+		if tag.Embed && fv.Kind() == reflect.Slice {
+			if fv.Type().Elem().Kind() != reflect.Struct {
+				return nil, failField(v, ft, "embedded slices must be of structs")
+			}
+			if tag.Instances == 0 {
+				return nil, failField(v, ft, "embedded slices require an instances:\"N\" tag")
+			}
+			group := &indexedGroup{field: fv, backing: reflect.MakeSlice(fv.Type(), tag.Instances, tag.Instances), prefix: tag.Prefix}
+			fv.Set(group.backing)
+			k.indexedGroups = append(k.indexedGroups, group)
+			for i := 0; i < tag.Instances; i++ {
+				itag := *tag
+				itag.indexed, itag.instance = group, i
+				for _, prefix := range []*string{&itag.Prefix, &itag.EnvPrefix, &itag.XorPrefix} {
+					*prefix = strings.ReplaceAll(*prefix, "{i}", strconv.Itoa(i))
+				}
+				sub, err := flattenedFields(k, group.backing.Index(i), &itag)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, sub...)
+			}
+			continue
+		}
+
 		// Embedded type.
 		if fv.Kind() == reflect.Interface {
 			fv = fv.Elem()
 		} else if fv.Type() == reflect.TypeOf(Plugins{}) {
 			for i := 0; i < fv.Len(); i++ {
-				fields, ferr := flattenedFields(fv.Index(i).Elem(), tag)
+				fields, ferr := flattenedFields(k, fv.Index(i).Elem(), tag)
 				if ferr != nil {
 					return nil, ferr
 				}
@@ -102,7 +141,7 @@ func flattenedFields(v reflect.Value, ptag *Tag) (out []flattenedField, err erro
 			}
 			continue
 		}
-		sub, err := flattenedFields(fv, tag)
+		sub, err := flattenedFields(k, fv, tag)
 		if err != nil {
 			return nil, err
 		}
@@ -138,7 +177,7 @@ func buildNode(k *Kong, v reflect.Value, typ NodeType, tag *Tag, seenFlags map[s
 		Target: v,
 		Tag:    tag,
 	}
-	fields, err := flattenedFields(v, tag)
+	fields, err := flattenedFields(k, v, tag)
 	if err != nil {
 		return nil, err
 	}
@@ -156,12 +195,24 @@ MAIN:
 
 		tag := field.tag
 		name := tag.Name
-		if name == "" {
+		if name == "" && tag.Cmd && k.commandNamer != nil {
+			name = tag.Prefix + k.commandNamer(ft.Name)
+		} else if name == "" {
 			name = tag.Prefix + k.flagNamer(ft.Name)
 		} else {
 			name = tag.Prefix + name
 		}
 
+		// Flags beneath a command with an envprefix derive their envar from their name.
+		if tag.envPrefixed && !tag.Cmd && !tag.Arg {
+			switch {
+			case len(tag.Envs) == 1 && tag.Envs[0] == "-":
+				tag.Envs = nil
+			case len(tag.Envs) == 0:
+				tag.Envs = []string{envarName("", name)}
+			}
+		}
+
 		if len(tag.Envs) != 0 {
 			for i := range tag.Envs {
 				tag.Envs[i] = tag.EnvPrefix + tag.Envs[i]
@@ -180,6 +231,15 @@ MAIN:
 			}
 		}
 
+		if tag.Rest {
+			if node.Rest != nil {
+				return nil, failField(v, ft, "duplicate rest field, already declared by %s", node.Rest.Name)
+			}
+			node.Rest = &Value{Name: name, Help: tag.Help, OrigHelp: tag.Help, Tag: tag, Target: fv}
+			k.hasRest = true
+			continue
+		}
+
 		// Nested structs are either commands or args, unless they implement the Mapper interface.
 		if field.value.Kind() == reflect.Struct && (tag.Cmd || tag.Arg) && k.registry.ForValue(fv) == nil {
 			typ := CommandNode
@@ -200,17 +260,16 @@ MAIN:
 		return nil, err
 	}
 
-	// "Unsee" flags.
+	// "Unsee" flags, other than those of ancestors shadowed by overriding flags.
 	for _, flag := range node.Flags {
-		delete(seenFlags, "--"+flag.Name)
-		if flag.Short != 0 {
-			delete(seenFlags, "-"+string(flag.Short))
-		}
-		if negFlag := negatableFlagName(flag.Name, flag.Tag.Negatable); negFlag != "" {
-			delete(seenFlags, negFlag)
+		shadowed := map[string]bool{}
+		for _, key := range flag.shadows {
+			shadowed[key] = true
 		}
-		for _, aflag := range flag.Aliases {
-			delete(seenFlags, "--"+aflag)
+		for _, key := range flag.keys() {
+			if !shadowed[key] {
+				delete(seenFlags, key)
+			}
 		}
 	}
 
@@ -244,15 +303,24 @@ func validatePositionalArguments(node *Node) error {
 }
 
 func buildChild(k *Kong, node *Node, typ NodeType, v reflect.Value, ft reflect.StructField, fv reflect.Value, tag *Tag, name string, seenFlags map[string]bool) error {
-	child, err := buildNode(k, fv, typ, newEmptyTag(), seenFlags)
+	// Envar prefixes compose down through commands.
+	ctag := newEmptyTag()
+	ctag.EnvPrefix = tag.EnvPrefix
+	ctag.envPrefixed = tag.envPrefixed
+	ctag.indexed, ctag.instance = tag.indexed, tag.instance
+	child, err := buildNode(k, fv, typ, ctag, seenFlags)
 	if err != nil {
 		return err
 	}
 	child.Name = name
 	child.Tag = tag
 	child.Parent = node
+	if tag.UnknownArgs == unknownArgsCapture && child.Rest == nil {
+		return failField(v, ft, "unknownargs:\"capture\" requires a rest:\"\" field to capture into")
+	}
 	child.Help = tag.Help
-	child.Hidden = tag.Hidden
+	child.Hidden = tag.Hidden || tag.Stability == StabilityAlpha
+	child.Stability = tag.Stability
 	child.Group = buildGroupForKey(k, tag.Group)
 	child.Aliases = tag.Aliases
 
@@ -280,9 +348,13 @@ func buildChild(k *Kong, node *Node, typ NodeType, v reflect.Value, ft reflect.S
 			if node.DefaultCmd != nil {
 				return failField(v, ft, "can't have more than one default command under %s", node.Summary())
 			}
-			if tag.Default != "withargs" && (len(child.Children) > 0 || len(child.Positional) > 0) {
+			if tag.Default != "withargs" && (len(child.Positional) > 0 || hasArgumentChild(child)) {
 				return failField(v, ft, "default command %s must not have subcommands or arguments", child.Summary())
 			}
+			// A default command with subcommands is only unambiguous if it in turn has a default subcommand.
+			if tag.Default != "withargs" && len(child.Children) > 0 && child.DefaultCmd == nil {
+				return failField(v, ft, "default command %s has subcommands, so one of them must also be a default command", child.Summary())
+			}
 			node.DefaultCmd = child
 		}
 		if tag.Passthrough {
@@ -313,6 +385,29 @@ func buildField(k *Kong, node *Node, v reflect.Value, ft reflect.StructField, fv
 		return failField(v, ft, "unsupported field type %s, perhaps missing a cmd:\"\" tag?", ft.Type)
 	}
 
+	if tag.Unit != "" {
+		mapper = unitMapper(tag.Unit)
+	}
+	if tag.EnumFrom != "" && k.enumProviders[tag.EnumFrom] == nil {
+		return failField(v, ft, "unknown enum provider %q, perhaps missing an EnumProvider() option?", tag.EnumFrom)
+	}
+	if k.expandPaths {
+		tag.Expand = true
+	}
+	if tag.Help == "" && fv.Type() == reflect.TypeOf(DryRunFlag(false)) {
+		tag.Help = dryRunHelp
+	}
+	if fv.Type() == reflect.TypeOf(OutputFormatFlag("")) {
+		if tag.Enum == "" {
+			tag.Enum = strings.Join([]string{OutputJSON, OutputYAML, OutputTable}, ",")
+		}
+		if !tag.HasDefault {
+			tag.HasDefault, tag.Default = true, OutputTable
+		}
+		if tag.Help == "" {
+			tag.Help = outputFormatHelp
+		}
+	}
 	value := &Value{
 		Name:            name,
 		Help:            tag.Help,
@@ -331,33 +426,83 @@ func buildField(k *Kong, node *Node, v reflect.Value, ft reflect.StructField, fv
 		Required: (!tag.Arg && tag.Required) || (tag.Arg && !tag.Optional),
 		Format:   tag.Format,
 	}
+	typ := fv.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if formatter, ok := k.typeFormatters[typ]; ok {
+		value.formatter = formatter
+	} else {
+		value.formatter = builtinFormatter(typ, tag)
+	}
+	if tag.Unit != "" {
+		value.formatter = unitFormatter(tag.Unit)
+	}
 
 	if tag.Arg {
 		node.Positional = append(node.Positional, value)
 	} else {
-		if seenFlags["--"+value.Name] {
-			return failField(v, ft, "duplicate flag --%s", value.Name)
+		if k.autoNegatable && tag.Negatable == "" && tag.HasDefault && tag.Default == "true" && isBoolType(ft.Type) {
+			tag.Negatable = negatableDefault
+		}
+		if tag.Negatable == negatableDefault && k.negationPrefix != "" {
+			tag.Negatable = k.negationPrefix + value.Name
+		}
+		shadows := []string{}
+		// seeFlag marks "key" as seen, allowing ancestor flags to be shadowed by overriding flags. Collisions are
+		// otherwise resolved by the FlagCollisions policy, with "rename" true if the key should be renamed or dropped.
+		seeFlag := func(key, format string, args ...any) (rename bool, err error) {
+			if seenFlags[key] {
+				policy := CollisionShadow
+				if !tag.Override {
+					policy = k.collision(Collision{Flag: value.Name, Key: key, Builtin: k.builtinKeys[key]})
+				}
+				switch {
+				case policy == CollisionRename:
+					return true, nil
+				case policy != CollisionShadow || nodeHasFlagKey(node, key):
+					return false, failField(v, ft, format, args...)
+				case k.builtinKeys[key] && node.Parent == nil:
+					k.shadowedBuiltins[key] = true
+				default:
+					shadows = append(shadows, key)
+				}
+			}
+			seenFlags[key] = true
+			return false, nil
 		}
-		seenFlags["--"+value.Name] = true
+		rename, err := seeFlag("--"+value.Name, "duplicate flag --%s", value.Name)
+		if err != nil {
+			return err
+		}
+		if rename {
+			value.Name = renamedFlag(value.Name, seenFlags)
+			seenFlags["--"+value.Name] = true
+		}
+		aliases := []string{}
 		for _, alias := range tag.Aliases {
 			aliasFlag := "--" + alias
-			if seenFlags[aliasFlag] {
-				return failField(v, ft, "duplicate flag %s", aliasFlag)
+			if rename, err = seeFlag(aliasFlag, "duplicate flag %s", aliasFlag); err != nil {
+				return err
+			} else if !rename {
+				aliases = append(aliases, alias)
 			}
-			seenFlags[aliasFlag] = true
 		}
+		tag.Aliases = aliases
 		if tag.Short != 0 {
-			if seenFlags["-"+string(tag.Short)] {
-				return failField(v, ft, "duplicate short flag -%c", tag.Short)
+			if rename, err = seeFlag("-"+string(tag.Short), "duplicate short flag -%c", tag.Short); err != nil {
+				return err
+			} else if rename {
+				tag.Short = 0
 			}
-			seenFlags["-"+string(tag.Short)] = true
 		}
 		if tag.Negatable != "" {
 			negFlag := negatableFlagName(value.Name, tag.Negatable)
-			if seenFlags[negFlag] {
-				return failField(v, ft, "duplicate negation flag %s", negFlag)
+			if rename, err = seeFlag(negFlag, "duplicate negation flag %s", negFlag); err != nil {
+				return err
+			} else if rename {
+				tag.Negatable = ""
 			}
-			seenFlags[negFlag] = true
 		}
 		flag := &Flag{
 			Value:       value,
@@ -368,7 +513,11 @@ func buildField(k *Kong, node *Node, v reflect.Value, ft reflect.StructField, fv
 			Group:       buildGroupForKey(k, tag.Group),
 			Xor:         tag.Xor,
 			And:         tag.And,
-			Hidden:      tag.Hidden,
+			Hidden:      tag.Hidden || tag.Stability == StabilityAlpha,
+			Stability:   tag.Stability,
+
+			placeHolderStyle: k.placeHolderStyle,
+			shadows:          shadows,
 		}
 		value.Flag = flag
 		node.Flags = append(node.Flags, flag)
@@ -376,6 +525,18 @@ func buildField(k *Kong, node *Node, v reflect.Value, ft reflect.StructField, fv
 	return nil
 }
 
+// nodeHasFlagKey returns true if a flag of "node" itself, rather than of an ancestor, is spelled "key".
+func nodeHasFlagKey(node *Node, key string) bool {
+	for _, flag := range node.Flags {
+		for _, k := range flag.keys() {
+			if k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func buildGroupForKey(k *Kong, key string) *Group {
 	if key == "" {
 		return nil
@@ -409,3 +570,12 @@ func checkDuplicateNames(node *Node, v reflect.Value) error {
 
 	return nil
 }
+
+func hasArgumentChild(node *Node) bool {
+	for _, child := range node.Children {
+		if child.Type == ArgumentNode {
+			return true
+		}
+	}
+	return false
+}
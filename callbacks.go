@@ -127,6 +127,16 @@ func getMethod(value reflect.Value, name string) reflect.Value {
 	return method
 }
 
+// getRunMethod returns the first entry point method of "value", as configured by RunMethods.
+func (k *Kong) getRunMethod(value reflect.Value) reflect.Value {
+	for _, name := range k.runMethods {
+		if method := getMethod(value, name); method.IsValid() {
+			return method
+		}
+	}
+	return reflect.Value{}
+}
+
 // getMethods gets all methods with the given name from the given value
 // and any embedded fields.
 //
@@ -199,33 +209,58 @@ func callAnyFunction(f reflect.Value, bindings bindings) (out []any, err error)
 	for i := 0; i < t.NumIn(); i++ {
 		pt := t.In(i)
 		binding, ok := bindings[pt]
+		if !ok && t.IsVariadic() && i == t.NumIn()-1 {
+			// Variadic parameters receive a bound value of the element type if there is one, otherwise nothing.
+			extras := reflect.MakeSlice(pt, 0, 1)
+			if binding, ok := bindings[pt.Elem()]; ok {
+				val, err := resolveBinding(pt.Elem(), binding, bindings)
+				if err != nil {
+					return nil, err
+				}
+				extras = reflect.Append(extras, val)
+			}
+			in = append(in, extras)
+			continue
+		}
 		if !ok {
 			return nil, fmt.Errorf("couldn't find binding of type %s for parameter %d of %s(), use kong.Bind(%s)", pt, i, t, pt)
 		}
-
-		// Don't need to call the function if the value is already resolved.
-		if val, ok := binding.Get(); ok {
-			in = append(in, val)
-			continue
-		}
-
-		// Recursively resolve binding functions.
-		argv, err := callAnyFunction(binding.fn, bindings)
+		val, err := resolveBinding(pt, binding, bindings)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", pt, err)
-		}
-		if ferrv := reflect.ValueOf(argv[len(argv)-1]); ferrv.IsValid() && ferrv.Type().Implements(callbackReturnSignature) && !ferrv.IsNil() {
-			return nil, ferrv.Interface().(error) //nolint:forcetypeassert
+			return nil, err
 		}
-
-		val := reflect.ValueOf(argv[0])
-		binding.Set(val)
 		in = append(in, val)
 	}
-	outv := f.Call(in)
+	var outv []reflect.Value
+	if t.IsVariadic() {
+		outv = f.CallSlice(in)
+	} else {
+		outv = f.Call(in)
+	}
 	out = make([]any, len(outv))
 	for i, v := range outv {
 		out[i] = v.Interface()
 	}
 	return out, nil
 }
+
+// resolveBinding returns the value of a binding of type "pt".
+func resolveBinding(pt reflect.Type, binding *binding, bindings bindings) (reflect.Value, error) {
+	// Don't need to call the function if the value is already resolved.
+	if val, ok := binding.Get(); ok {
+		return val, nil
+	}
+
+	// Recursively resolve binding functions.
+	argv, err := callAnyFunction(binding.fn, bindings)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: %w", pt, err)
+	}
+	if ferrv := reflect.ValueOf(argv[len(argv)-1]); ferrv.IsValid() && ferrv.Type().Implements(callbackReturnSignature) && !ferrv.IsNil() {
+		return reflect.Value{}, ferrv.Interface().(error) //nolint:forcetypeassert
+	}
+
+	val := reflect.ValueOf(argv[0])
+	binding.Set(val)
+	return val, nil
+}
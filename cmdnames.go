@@ -0,0 +1,71 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandNamer overrides how command names are derived from field names, which is otherwise the same as for
+// flags (see FlagNamer). Names given with a `name:""` tag are used as is.
+func CommandNamer(namer func(fieldName string) string) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.commandNamer = namer
+		return nil
+	})
+}
+
+// CommandMatcher matches commands given on the command-line against command names and aliases after normalising
+// both with "normalize", so that commands can keep a canonical name for display, eg. "Users.List", while being
+// matched more leniently, eg. as "users.list" or "users-list". See NormalizeCommandName.
+//
+// Commands of the same parent whose names normalise to the same value are an error.
+func CommandMatcher(normalize func(name string) string) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.commandMatcher = normalize
+		return nil
+	})
+}
+
+// NormalizeCommandName lower-cases "name" and replaces "." and "_" with "-", for use with CommandMatcher.
+func NormalizeCommandName(name string) string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(strings.ToLower(name))
+}
+
+// commandMatches returns true if the argument "arg" selects a command or alias called "name".
+func (k *Kong) commandMatches(name string, arg any) bool {
+	s, ok := arg.(string)
+	if !ok {
+		return false
+	}
+	if k.commandMatcher == nil {
+		return name == s
+	}
+	return k.commandMatcher(name) == k.commandMatcher(s)
+}
+
+// checkCommandMatches returns an error if two commands of the same parent can't be told apart by CommandMatcher.
+func (k *Kong) checkCommandMatches(node *Node) error {
+	if k.commandMatcher == nil {
+		return nil
+	}
+	return Visit(node, func(v Visitable, next Next) error {
+		n, ok := v.(*Node)
+		if !ok {
+			return next(nil)
+		}
+		seen := map[string]string{}
+		for _, child := range n.Children {
+			if child.Type != CommandNode {
+				continue
+			}
+			for _, name := range append([]string{child.Name}, child.Aliases...) {
+				key := k.commandMatcher(name)
+				if other, ok := seen[key]; ok && other != child.Name {
+					return fmt.Errorf("commands %q and %q can't be told apart once normalised to %q", other, child.Name, key)
+				}
+				seen[key] = child.Name
+			}
+		}
+		return next(nil)
+	})
+}
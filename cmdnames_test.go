@@ -0,0 +1,54 @@
+package kong_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestCommandMatcher(t *testing.T) {
+	var cli struct {
+		UsersList struct{} `cmd:"" name:"Users.List" aliases:"Users.Ls" help:"List users."`
+		UsersGet  struct{} `cmd:"" name:"Users.Get" help:"Get a user."`
+	}
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(w, w), kong.Exit(func(int) {}), kong.CommandMatcher(kong.NormalizeCommandName))
+
+	for _, arg := range []string{"Users.List", "users.list", "users-list", "USERS_LIST", "users.ls"} {
+		ctx, err := p.Parse([]string{arg})
+		assert.NoError(t, err, arg)
+		assert.Equal(t, "Users.List", ctx.Command(), arg)
+	}
+
+	_, _ = p.Parse([]string{"--help"})
+	assert.Contains(t, w.String(), "Users.List")
+
+	p = mustNew(t, &cli)
+	_, err := p.Parse([]string{"users.list"})
+	assert.Error(t, err)
+}
+
+func TestCommandMatcherIndistinguishable(t *testing.T) {
+	var cli struct {
+		A struct{} `cmd:"" name:"users.list"`
+		B struct{} `cmd:"" name:"Users_List"`
+	}
+	_, err := kong.New(&cli, kong.CommandMatcher(kong.NormalizeCommandName))
+	assert.EqualError(t, err, `commands "users.list" and "Users_List" can't be told apart once normalised to "users-list"`)
+}
+
+func TestCommandNamer(t *testing.T) {
+	var cli struct {
+		ListUsers struct {
+			ShowAll bool
+		} `cmd:""`
+	}
+	p := mustNew(t, &cli, kong.CommandNamer(func(name string) string { return name }))
+	ctx, err := p.Parse([]string{"ListUsers", "--show-all"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ListUsers", ctx.Command())
+	assert.True(t, cli.ListUsers.ShowAll)
+}
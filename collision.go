@@ -0,0 +1,76 @@
+package kong
+
+import (
+	"strconv"
+)
+
+// CollisionPolicy decides what happens when a flag's name, alias, short name or negation collides with a flag
+// already defined in its scope. See FlagCollisions.
+type CollisionPolicy int
+
+const (
+	// CollisionError fails to build the parser. This is the default.
+	CollisionError CollisionPolicy = iota
+	// CollisionRename renames the flag with a numeric suffix, eg. "--help-2", if its name collides. Colliding
+	// aliases, short names and negations are dropped instead.
+	CollisionRename
+	// CollisionShadow lets the flag take precedence, as if tagged "override". A built-in flag such as --help loses
+	// the colliding name, and is removed if its long name collides. Flags of the same command can't shadow each
+	// other.
+	CollisionShadow
+)
+
+// Collision describes a flag colliding with one already defined in its scope.
+type Collision struct {
+	// Flag is the name of the flag being defined.
+	Flag string
+	// Key is the colliding spelling, eg. "--help", "-h" or "--no-verbose".
+	Key string
+	// Builtin is true if the flag collides with one added by Kong, such as --help.
+	Builtin bool
+}
+
+// FlagCollisions calls "policy" to decide what happens when a flag collides with another in its scope, rather
+// than always failing. Flags tagged "override" always shadow ancestor flags.
+func FlagCollisions(policy func(Collision) CollisionPolicy) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.collisionPolicy = policy
+		return nil
+	})
+}
+
+func (k *Kong) collision(collision Collision) CollisionPolicy {
+	if k.collisionPolicy == nil {
+		return CollisionError
+	}
+	return k.collisionPolicy(collision)
+}
+
+// renamedFlag returns "name" with the lowest numeric suffix that doesn't collide with "seenFlags".
+func renamedFlag(name string, seenFlags map[string]bool) string {
+	for i := 2; ; i++ {
+		candidate := name + "-" + strconv.Itoa(i)
+		if !seenFlags["--"+candidate] {
+			return candidate
+		}
+	}
+}
+
+// stripShadowedBuiltins removes the keys of built-in flags shadowed by flags of the application, and returns the
+// built-in flags that remain.
+func (k *Kong) stripShadowedBuiltins(builtins []*Flag) []*Flag {
+	out := []*Flag{}
+	for _, flag := range builtins {
+		if k.shadowedBuiltins["--"+flag.Name] {
+			if flag == k.helpFlag {
+				k.helpFlag = nil
+			}
+			continue
+		}
+		if flag.Short != 0 && k.shadowedBuiltins["-"+string(flag.Short)] {
+			flag.Short = 0
+		}
+		out = append(out, flag)
+	}
+	return out
+}
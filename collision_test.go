@@ -0,0 +1,68 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestFlagCollisions(t *testing.T) {
+	type cli struct {
+		Host    string `short:"h"`
+		Verbose bool   `negatable:""`
+		Deploy  struct {
+			Verbose   bool
+			NoVerbose bool
+		} `cmd:""`
+	}
+	_, err := kong.New(&cli{})
+	assert.EqualError(t, err, "cli.Host: duplicate short flag -h")
+
+	var collisions []kong.Collision
+	var renamed cli
+	p := mustNew(t, &renamed, kong.FlagCollisions(func(c kong.Collision) kong.CollisionPolicy {
+		collisions = append(collisions, c)
+		return kong.CollisionRename
+	}))
+	assert.Equal(t, []kong.Collision{
+		{Flag: "host", Key: "-h", Builtin: true},
+		{Flag: "verbose", Key: "--verbose"},
+		{Flag: "no-verbose", Key: "--no-verbose"},
+	}, collisions)
+	assert.Equal(t, 'h', p.Model.HelpFlag.Short)
+	_, err = p.Parse([]string{"deploy", "--verbose-2", "--no-verbose-2", "--no-verbose"})
+	assert.NoError(t, err)
+	assert.Equal(t, true, renamed.Deploy.Verbose)
+	assert.Equal(t, true, renamed.Deploy.NoVerbose)
+	assert.Equal(t, false, renamed.Verbose)
+
+	var shadowed cli
+	p = mustNew(t, &shadowed, kong.FlagCollisions(func(c kong.Collision) kong.CollisionPolicy {
+		return kong.CollisionShadow
+	}))
+	_, err = p.Parse([]string{"-h", "example.com", "deploy", "--no-verbose"})
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", shadowed.Host)
+	assert.Equal(t, true, shadowed.Deploy.NoVerbose)
+	assert.Equal(t, rune(0), p.Model.HelpFlag.Short)
+}
+
+func TestFlagCollisionsShadowHelp(t *testing.T) {
+	var cli struct {
+		Help bool
+	}
+	p := mustNew(t, &cli, kong.FlagCollisions(func(c kong.Collision) kong.CollisionPolicy { return kong.CollisionShadow }))
+	assert.Zero(t, p.Model.HelpFlag)
+	_, err := p.Parse([]string{"--help"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Help)
+
+	var same struct {
+		A bool `name:"same"`
+		B bool `name:"same"`
+	}
+	_, err = kong.New(&same, kong.FlagCollisions(func(c kong.Collision) kong.CollisionPolicy { return kong.CollisionShadow }))
+	assert.EqualError(t, err, "<anonymous struct>.B: duplicate flag --same")
+}
@@ -0,0 +1,43 @@
+package kong
+
+import (
+	"fmt"
+	"io"
+)
+
+// CommandLineFlag is a flag value that parses a command-line, given as a single shell-quoted string, against its
+// own grammar, eg. --exec 'curl --retry 3 https://example.com' into an ExecSpec struct.
+//
+// The command-line is parsed by a separate Kong with T as its grammar, so T may use any tags, hooks and validation.
+// Errors are reported with the flag and the nested command-line, eg.
+//
+//	--exec: "curl --retri 3": unknown flag --retri, did you mean "--retry"?
+type CommandLineFlag[T any] struct {
+	Args  []string // Words of the command-line.
+	Value T        // Result of parsing Args.
+}
+
+func (f *CommandLineFlag[T]) Decode(ctx *DecodeContext) error { //nolint: revive
+	var line string
+	if err := ctx.Scan.PopValueInto("command-line", &line); err != nil {
+		return err
+	}
+	args, err := splitShellWords(line)
+	if err != nil {
+		return fmt.Errorf("%q: %w", line, err)
+	}
+	*f = CommandLineFlag[T]{Args: args}
+	parser, err := New(&f.Value,
+		Name(ctx.Value.Name),
+		NoDefaultHelp(),
+		Writers(io.Discard, io.Discard),
+		Exit(func(int) {}))
+	if err != nil {
+		return err
+	}
+	if _, err := parser.Parse(args); err != nil {
+		// The nested ParseError is not wrapped, so it isn't mistaken for an error in the outer command-line.
+		return fmt.Errorf("%q: %s", line, err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package kong_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type execSpec struct {
+	Retry   int      `help:"Number of retries."`
+	Program string   `arg:""`
+	Args    []string `arg:"" optional:""`
+}
+
+func (e *execSpec) Validate() error {
+	if e.Retry > 5 {
+		return errors.New("too many retries")
+	}
+	return nil
+}
+
+func TestCommandLineFlag(t *testing.T) {
+	var cli struct {
+		Exec kong.CommandLineFlag[execSpec] `help:"Command to run."`
+	}
+	p := mustNew(t, &cli)
+
+	_, err := p.Parse([]string{"--exec", `curl --retry 3 "https://example.com/a b"`})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"curl", "--retry", "3", "https://example.com/a b"}, cli.Exec.Args)
+	assert.Equal(t, execSpec{Retry: 3, Program: "curl", Args: []string{"https://example.com/a b"}}, cli.Exec.Value)
+
+	_, err = p.Parse([]string{"--exec=curl --retri 3"})
+	assert.EqualError(t, err, `--exec: "curl --retri 3": unknown flag --retri, did you mean "--retry"?`)
+	var parseErr *kong.ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, p.Model, parseErr.Context.Model)
+
+	_, err = p.Parse([]string{"--exec=curl --retry 9"})
+	assert.EqualError(t, err, `--exec: "curl --retry 9": too many retries`)
+
+	assert.Equal(t, "COMMAND-LINE-FLAG", p.Model.Flags[1].FormatPlaceHolder())
+
+	_, err = p.Parse([]string{"--exec=curl 'x"})
+	assert.EqualError(t, err, `--exec: "curl 'x": unterminated ' quote`)
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Path records the nodes and parsed values from the current command-line.
@@ -90,16 +91,49 @@ type Context struct {
 	bindings  bindings
 	resolvers []Resolver // Extra context-specific resolvers.
 	scan      *Scanner
+	errorArg  int         // Index into Args of the argument that caused Error or a validation error, or -1.
+	rest      []string    // Arguments after the first bare "--", if the grammar has "rest" fields.
+	store     map[any]any // Values stored with Set.
+	shared    bool        // The grammar is shared with other Contexts, see ParseInvocations.
+	cleanups  []func() error
+	term      *Term // Detected by Term.
 }
 
 // Trace path of "args" through the grammar tree.
 //
-// The returned Context will include a Path of all commands, arguments, positionals and flags.
+// The returned Context will include a Path of all commands, arguments, positionals and flags, in the order they
+// were encountered. The first element is always the application. Each command, branching argument and positional
+// argument records the Node it was found under as its Parent, and each command and branching argument records the
+// flags it adds. Flags can be given more than once, in which case they appear once for each occurrence.
+//
+// Trace does not fail on an incomplete command-line, such as one missing required flags or positional arguments,
+// and syntax errors are recorded in Context.Error rather than returned, so Trace can be used on partial
+// command-lines. Context.Expected() then describes what may come next. Trace, Path and Expected are stable APIs.
 //
 // This just constructs a new trace. To fully apply the trace you must call Reset(), Resolve(),
 // Validate() and Apply().
 func Trace(k *Kong, args []string) (*Context, error) {
-	s := Scan(args...).AllowHyphenPrefixedParameters(k.allowHyphenated)
+	c := newTrace(k, args, k.hasRest)
+	// Only commands with a rest field take the arguments after "--", so for others they are parsed as usual.
+	if c.rest != nil && !c.pathHasRest() {
+		c = newTrace(k, args, false)
+	}
+	return c, nil
+}
+
+// newTrace traces "args", splitting off the arguments after the first bare "--" into Context.rest if "splitRest" is
+// true.
+func newTrace(k *Kong, args []string, splitRest bool) *Context {
+	scanArgs, rest := args, []string(nil)
+	if splitRest {
+		for i, arg := range args {
+			if arg == "--" {
+				scanArgs, rest = args[:i], args[i+1:]
+				break
+			}
+		}
+	}
+	s := Scan(scanArgs...).AllowHyphenPrefixedParameters(k.allowHyphenated).AllowNegativeNumbers(k.negativeNumbersAreValues())
 	c := &Context{
 		Kong: k,
 		Args: args,
@@ -109,9 +143,33 @@ func Trace(k *Kong, args []string) (*Context, error) {
 		values:   map[*Value]reflect.Value{},
 		scan:     s,
 		bindings: bindings{},
+		rest:     rest,
+		errorArg: -1,
 	}
+	c.bindings.add(c.Term())
 	c.Error = c.trace(c.Model.Node)
-	return c, nil
+	if c.Error != nil {
+		c.errorArg = c.argIndex(s.PeekAll())
+	}
+	return c
+}
+
+// errorAt records the last argument consumed by "path" as the cause of an error, unless its value was resolved
+// rather than parsed.
+func (c *Context) errorAt(path *Path) {
+	if !path.Resolved {
+		c.errorArg = c.argIndex(path.remainder)
+	}
+}
+
+// pathHasRest returns true if a node in the traced path has a rest field.
+func (c *Context) pathHasRest() bool {
+	for _, trace := range c.Path {
+		if node := trace.Node(); node != nil && node.Rest != nil {
+			return true
+		}
+	}
+	return false
 }
 
 // Bind adds bindings to the Context.
@@ -148,6 +206,53 @@ func (c *Context) BindSingletonProvider(provider any) error {
 	return c.bindings.addProvider(provider, true /* singleton */)
 }
 
+// Set stores "value" under "key", for sharing state between hooks, resolvers and Run() methods.
+//
+// As with context.Context, keys should be of an unexported type to avoid collisions between packages.
+func (c *Context) Set(key, value any) {
+	if c.store == nil {
+		c.store = map[any]any{}
+	}
+	c.store[key] = value
+}
+
+// Get returns the value stored under "key" with Set.
+func (c *Context) Get(key any) (value any, ok bool) {
+	value, ok = c.store[key]
+	return value, ok
+}
+
+// ContextValue returns the value stored under "key" with Context.Set, if it is of type T.
+func ContextValue[T any](ctx *Context, key any) (value T, ok bool) {
+	stored, ok := ctx.Get(key)
+	if !ok {
+		return value, false
+	}
+	value, ok = stored.(T)
+	return value, ok
+}
+
+// OnExit registers "cleanup" to release a resource, such as a file or connection, opened by a hook or provider.
+//
+// Cleanups are called in reverse order of registration once Run() and AfterRun hooks have completed, even if they
+// failed, and their errors are returned from Run(). If parsing fails after cleanups are registered they are called
+// before Parse() returns, and their errors are discarded in favour of the parse error.
+func (c *Context) OnExit(cleanup func() error) {
+	c.cleanups = append(c.cleanups, cleanup)
+}
+
+// exit calls and clears the cleanups registered with OnExit, in reverse order.
+func (c *Context) exit() error {
+	errs := []error{}
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		if err := c.cleanups[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.cleanups = nil
+	return errors.Join(errs...)
+}
+
 // Value returns the value for a particular path element.
 func (c *Context) Value(path *Path) reflect.Value {
 	switch {
@@ -190,17 +295,32 @@ func (c *Context) Validate() error { //nolint: gocyclo
 	err := Visit(c.Model, func(node Visitable, next Next) error {
 		switch node := node.(type) {
 		case *Value:
+			if node.EnvVar != "" {
+				if err := checkConstraints(c.messages, node, node.Target); err != nil {
+					return err
+				}
+			}
 			ok := atLeastOneEnvSet(node.Tag.Envs)
-			if node.Enum != "" && (!node.Required || node.HasDefault || (len(node.Tag.Envs) != 0 && ok)) {
-				if err := checkEnum(node, node.Target); err != nil {
+			if node.Enum != "" && node.Tag.EnumFrom == "" && (!node.Required || node.HasDefault || (len(node.Tag.Envs) != 0 && ok)) {
+				if err := checkEnum(c.messages, node, node.Target); err != nil {
 					return err
 				}
 			}
 
 		case *Flag:
+			if node.EnvVar != "" {
+				if err := checkConstraints(c.messages, node.Value, node.Target); err != nil {
+					return err
+				}
+			}
 			ok := atLeastOneEnvSet(node.Tag.Envs)
-			if node.Enum != "" && (!node.Required || node.HasDefault || (len(node.Tag.Envs) != 0 && ok)) {
-				if err := checkEnum(node.Value, node.Target); err != nil {
+			if node.Enum != "" && node.Tag.EnumFrom == "" && (!node.Required || node.HasDefault || (len(node.Tag.Envs) != 0 && ok)) {
+				if err := checkEnum(c.messages, node.Value, node.Target); err != nil {
+					for _, path := range c.Path {
+						if path.Flag == node {
+							c.errorAt(path)
+						}
+					}
 					return err
 				}
 			}
@@ -212,17 +332,20 @@ func (c *Context) Validate() error { //nolint: gocyclo
 	}
 	for _, el := range c.Path {
 		var (
-			value reflect.Value
-			desc  string
+			value    reflect.Value
+			desc     string
+			errValue *Value
 		)
 		switch node := el.Visitable().(type) {
 		case *Value:
 			value = node.Target
 			desc = node.ShortSummary()
+			errValue = node
 
 		case *Flag:
 			value = node.Target
 			desc = node.ShortSummary()
+			errValue = node.Value
 
 		case *Application:
 			value = node.Target
@@ -234,6 +357,10 @@ func (c *Context) Validate() error { //nolint: gocyclo
 		}
 		if validate := isValidatable(value); validate != nil {
 			if err := validate.Validate(c); err != nil {
+				c.errorAt(el)
+				if errValue != nil {
+					err = errValue.withErrHelp(err)
+				}
 				if desc != "" {
 					return fmt.Errorf("%s: %w", desc, err)
 				}
@@ -246,6 +373,7 @@ func (c *Context) Validate() error { //nolint: gocyclo
 			return err
 		}
 	}
+	selected := c.selectedCommands()
 	for _, path := range c.Path {
 		var value *Value
 		switch {
@@ -255,12 +383,22 @@ func (c *Context) Validate() error { //nolint: gocyclo
 		case path.Positional != nil:
 			value = path.Positional
 		}
-		if value != nil && value.Tag.Enum != "" {
-			if err := checkEnum(value, value.Target); err != nil {
+		if value != nil && (value.Tag.Enum != "" || value.Tag.EnumFrom != "") {
+			if err := c.resolveEnum(value); err != nil {
+				return err
+			}
+			if err := checkEnum(c.messages, value, value.Target); err != nil {
+				c.errorAt(path)
 				return err
 			}
 		}
-		if err := checkMissingFlags(path.Flags); err != nil {
+		if value != nil {
+			if err := checkConstraints(c.messages, value, value.Target); err != nil {
+				c.errorAt(path)
+				return err
+			}
+		}
+		if err := checkMissingFlags(c.messages, path.Flags, c.groupMissing, selected); err != nil {
 			return err
 		}
 	}
@@ -278,20 +416,22 @@ func (c *Context) Validate() error { //nolint: gocyclo
 		}
 	}
 
-	if err := checkMissingChildren(node); err != nil {
+	// A command that captured unknown arguments is complete without a subcommand.
+	captured := node.Tag != nil && node.Tag.UnknownArgs == unknownArgsCapture && len(c.rest) > 0
+	if err := checkMissingChildren(c.messages, node, !captured); err != nil {
 		return err
 	}
-	if err := checkMissingPositionals(positionals, node.Positional); err != nil {
+	if err := checkMissingPositionals(c.messages, positionals, node.Positional); err != nil {
 		return err
 	}
-	if err := checkXorDuplicatedAndAndMissing(c.Path); err != nil {
+	if err := checkXorDuplicatedAndAndMissing(c.messages, c.Path); err != nil {
 		return err
 	}
 
 	if node.Type == ArgumentNode {
 		value := node.Argument
 		if value.Required && !value.Set {
-			return fmt.Errorf("%s is required", node.Summary())
+			return c.messages.errorf(MessageRequired, node.Summary())
 		}
 	}
 	return nil
@@ -323,6 +463,69 @@ func (c *Context) Command() string {
 	return strings.Join(command, " ")
 }
 
+// SelectedCommand returns the names of the selected command and its parents, eg. "user create", or an empty string
+// if no command was selected.
+//
+// Unlike Command(), arguments are omitted, and commands selected by an alias are returned by name, so the result
+// is suitable for switching on.
+func (c *Context) SelectedCommand() string {
+	command := []string{}
+	for _, trace := range c.Path {
+		if trace.Command != nil {
+			command = append(command, trace.Command.Name)
+		}
+	}
+	return strings.Join(command, " ")
+}
+
+// FlagSet returns true if the flag "name", eg. "--name", "name" or "-n", was given on the command-line or set from
+// an environment variable or resolver, rather than left at its default.
+//
+// Only flags available to the selected command are considered, so unknown names return false.
+func (c *Context) FlagSet(name string) bool {
+	name = strings.TrimLeft(name, "-")
+	for _, flag := range c.Flags() {
+		if !flagHasName(flag, name) {
+			continue
+		}
+		if flag.EnvVar != "" {
+			return true
+		}
+		for _, trace := range c.Path {
+			if trace.Flag == flag {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// flagHasName returns true if "name", without leading dashes, is the name, an alias or the short name of "flag".
+func flagHasName(flag *Flag, name string) bool {
+	if name == flag.Name || (flag.Short != 0 && name == string(flag.Short)) {
+		return true
+	}
+	for _, alias := range flag.Aliases {
+		if name == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// PositionalValues returns the values of the positional arguments of the selected command and its parents, keyed
+// by name, as bound to Run() methods as Args.
+//
+// Values are available once they have been applied, eg. in AfterApply hooks and Run() methods.
+func (c *Context) PositionalValues() Args {
+	node := c.Selected()
+	if node == nil {
+		node = c.Model.Node
+	}
+	return positionalArgs(node)
+}
+
 // AddResolver adds a context-specific resolver.
 //
 // This is most useful in the BeforeResolve() hook.
@@ -350,8 +553,13 @@ func (c *Context) FlagValue(flag *Flag) any {
 // Reset recursively resets values to defaults (as specified in the grammar) or the zero value.
 func (c *Context) Reset() error {
 	return Visit(c.Model.Node, func(node Visitable, next Next) error {
-		if value, ok := node.(*Value); ok {
-			return next(value.Reset())
+		switch node := node.(type) {
+		case *Value:
+			return next(node.Reset())
+		case *Node:
+			if node.Rest != nil {
+				node.Rest.Target.Set(reflect.Zero(node.Rest.Target.Type()))
+			}
 		}
 		return next(nil)
 	})
@@ -416,6 +624,20 @@ func (c *Context) trace(node *Node) (err error) { //nolint: gocyclo
 						c.scan.Pop()
 					}
 
+				// Windows-style flag, eg. /flag or /flag:value.
+				case c.slashFlags && slashFlagName(v, flags) != "":
+					c.scan.Pop()
+					_, value, ok := strings.Cut(v, ":")
+					if ok {
+						c.scan.PushTyped(value, FlagValueToken)
+					}
+					c.scan.PushTyped(slashFlagName(v, flags), FlagToken)
+
+				// Negative number, eg. -1 or -1.5.
+				case c.scan.allowNegativeNumbers && isNegativeNumber(v):
+					c.scan.Pop()
+					c.scan.PushTyped(token.Value, PositionalArgumentToken)
+
 				// Long flag.
 				case strings.HasPrefix(v, "--"):
 					c.scan.Pop()
@@ -450,26 +672,36 @@ func (c *Context) trace(node *Node) (err error) { //nolint: gocyclo
 
 		case FlagToken:
 			if err := c.parseFlag(flags, token.String()); err != nil {
-				if isUnknownFlagError(err) && positional < len(node.Positional) && node.Positional[positional].PassthroughMode == PassThroughModeAll {
+				switch {
+				case isUnknownFlagError(err) && positional < len(node.Positional) && node.Positional[positional].PassthroughMode == PassThroughModeAll:
 					c.scan.Pop()
 					c.scan.PushTyped(token.String(), PositionalArgumentToken)
-				} else {
+				case isUnknownFlagError(err) && defaultCmdTakesUnknownFlags(node):
+					return c.traceDefault(node)
+				case isUnknownFlagError(err) && node.Tag != nil && node.Tag.UnknownArgs == unknownArgsCapture:
+					c.captureUnknown()
+				default:
 					return err
 				}
 			}
 
 		case ShortFlagToken:
 			if err := c.parseFlag(flags, token.String()); err != nil {
-				if isUnknownFlagError(err) && positional < len(node.Positional) && node.Positional[positional].PassthroughMode == PassThroughModeAll {
+				switch {
+				case isUnknownFlagError(err) && positional < len(node.Positional) && node.Positional[positional].PassthroughMode == PassThroughModeAll:
 					c.scan.Pop()
 					c.scan.PushTyped(token.String(), PositionalArgumentToken)
-				} else {
+				case isUnknownFlagError(err) && defaultCmdTakesUnknownFlags(node):
+					return c.traceDefault(node)
+				case isUnknownFlagError(err) && node.Tag != nil && node.Tag.UnknownArgs == unknownArgsCapture:
+					c.captureUnknown()
+				default:
 					return err
 				}
 			}
 
 		case FlagValueToken:
-			return fmt.Errorf("unexpected flag argument %q", token.Value)
+			return c.messages.errorf(MessageUnexpectedFlagArgument, token.Value)
 
 		case PositionalArgumentToken:
 			candidates := []string{}
@@ -478,7 +710,8 @@ func (c *Context) trace(node *Node) (err error) { //nolint: gocyclo
 			if positional < len(node.Positional) {
 				arg := node.Positional[positional]
 
-				if arg.Passthrough {
+				// Flags of non-interspersed commands are only matched before positional arguments.
+				if arg.Passthrough || (node.Tag != nil && node.Tag.NonInterspersed) {
 					c.endParsing()
 				}
 
@@ -507,7 +740,7 @@ func (c *Context) trace(node *Node) (err error) { //nolint: gocyclo
 			for _, branch := range node.Children {
 				for _, a := range branch.Aliases {
 					_, ok := cmds[a]
-					if token.Value == a && !ok {
+					if c.commandMatches(a, token.Value) && !ok {
 						token.Value = branch.Name
 						break
 					}
@@ -518,8 +751,9 @@ func (c *Context) trace(node *Node) (err error) { //nolint: gocyclo
 			for _, branch := range node.Children {
 				if branch.Type == CommandNode && !branch.Hidden {
 					candidates = append(candidates, branch.Name)
+					candidates = append(candidates, branch.Aliases...)
 				}
-				if branch.Type == CommandNode && branch.Name == token.Value {
+				if branch.Type == CommandNode && c.commandMatches(branch.Name, token.Value) {
 					c.scan.Pop()
 					c.Path = append(c.Path, &Path{
 						Parent:    node,
@@ -531,6 +765,19 @@ func (c *Context) trace(node *Node) (err error) { //nolint: gocyclo
 				}
 			}
 
+			// Then let the command choose a subcommand from the shape of the argument.
+			if branch, err := dispatch(node, token.String()); err != nil {
+				return err
+			} else if branch != nil {
+				c.Path = append(c.Path, &Path{
+					Parent:    node,
+					Command:   branch,
+					Flags:     branch.Flags,
+					remainder: c.scan.PeekAll(),
+				})
+				return c.trace(branch)
+			}
+
 			// Finally, check arguments.
 			for _, branch := range node.Children {
 				if branch.Type == ArgumentNode {
@@ -550,23 +797,93 @@ func (c *Context) trace(node *Node) (err error) { //nolint: gocyclo
 			// If there is a default command that allows args and nothing else
 			// matches, take the branch of the default command
 			if node.DefaultCmd != nil && node.DefaultCmd.Tag.Default == "withargs" {
-				c.Path = append(c.Path, &Path{
-					Parent:    node,
-					Command:   node.DefaultCmd,
-					Flags:     node.DefaultCmd.Flags,
-					remainder: c.scan.PeekAll(),
-				})
-				return c.trace(node.DefaultCmd)
+				return c.traceDefault(node)
+			}
+
+			if node.Tag != nil && node.Tag.UnknownArgs == unknownArgsCapture {
+				c.captureUnknown()
+				break
 			}
 
-			return findPotentialCandidates(token.String(), candidates, "unexpected argument %s", token)
+			return findPotentialCandidates(c.messages, token.String(), candidates, MessageUnexpectedArgument, token)
 		default:
-			return fmt.Errorf("unexpected token %s", token)
+			return c.messages.errorf(MessageUnexpectedToken, token)
 		}
 	}
 	return c.maybeSelectDefault(flags, node)
 }
 
+// traceDefault continues tracing in the default command of "node".
+func (c *Context) traceDefault(node *Node) error {
+	c.Path = append(c.Path, &Path{
+		Parent:    node,
+		Command:   node.DefaultCmd,
+		Flags:     node.DefaultCmd.Flags,
+		remainder: c.scan.PeekAll(),
+	})
+	return c.trace(node.DefaultCmd)
+}
+
+// captureUnknown captures the next token, and all those after it, verbatim into the rest field of the command,
+// as configured by `unknownargs:"capture"`. Arguments after a bare "--" follow it.
+func (c *Context) captureUnknown() {
+	token := c.scan.Pop()
+	captured := []string{token.String()}
+	switch next := c.scan.Peek(); {
+	case token.Type == FlagToken && next.Type == FlagValueToken:
+		captured[0] += "=" + c.scan.Pop().String()
+	case token.Type == ShortFlagToken && next.Type == ShortFlagTailToken:
+		captured[0] += c.scan.Pop().String()
+	}
+	for !c.scan.Peek().IsEOL() {
+		captured = append(captured, c.scan.Pop().String())
+	}
+	if c.rest != nil {
+		captured = append(append(captured, "--"), c.rest...)
+	}
+	c.rest = captured
+}
+
+// defaultCmdTakesUnknownFlags returns true if "node" has a `default:"withargs"` command whose first positional
+// argument is `passthrough:"all"`, and so receives flags that are unknown to "node".
+func defaultCmdTakesUnknownFlags(node *Node) bool {
+	if node.DefaultCmd == nil || node.DefaultCmd.Tag.Default != "withargs" || len(node.DefaultCmd.Positional) == 0 {
+		return false
+	}
+	return node.DefaultCmd.Positional[0].PassthroughMode == PassThroughModeAll
+}
+
+// Dispatcher can be implemented by commands to select a subcommand from the shape of their first argument, eg.
+// a URL, a path or an ID, rather than by name.
+//
+// Dispatch is called with an argument that doesn't name a subcommand, and returns the name of the subcommand to
+// select, which receives the argument as its first positional argument. Returning "" leaves the argument unmatched.
+// The subcommands can still be selected by name, so they are typically hidden.
+type Dispatcher interface {
+	Dispatch(arg string) (string, error)
+}
+
+// dispatch returns the subcommand of "node" selected by its Dispatcher for "arg", if any.
+func dispatch(node *Node, arg string) (*Node, error) {
+	if !node.Target.IsValid() || !node.Target.CanAddr() {
+		return nil, nil
+	}
+	dispatcher, ok := node.Target.Addr().Interface().(Dispatcher)
+	if !ok {
+		return nil, nil
+	}
+	name, err := dispatcher.Dispatch(arg)
+	if err != nil || name == "" {
+		return nil, err
+	}
+	for _, branch := range node.Children {
+		if branch.Type == CommandNode && branch.Name == name {
+			return branch, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: Dispatch(%q) selected unknown subcommand %q", node.Name, arg, name)
+}
+
 // IgnoreDefault can be implemented by flags that want to be applied before any default commands.
 type IgnoreDefault interface {
 	IgnoreDefault()
@@ -580,9 +897,10 @@ func (c *Context) maybeSelectDefault(flags []*Flag, node *Node) error {
 			return nil
 		}
 	}
-	if node.DefaultCmd != nil {
+	// Follow chains of default commands, eg. "app" selecting "app server start".
+	for ; node.DefaultCmd != nil; node = node.DefaultCmd {
 		c.Path = append(c.Path, &Path{
-			Parent:    node.DefaultCmd,
+			Parent:    node,
 			Command:   node.DefaultCmd,
 			Flags:     node.DefaultCmd.Flags,
 			remainder: c.scan.PeekAll(),
@@ -594,13 +912,19 @@ func (c *Context) maybeSelectDefault(flags []*Flag, node *Node) error {
 // Resolve walks through the traced path, applying resolvers to any unset flags.
 func (c *Context) Resolve() error {
 	resolvers := c.combineResolvers()
-	if len(resolvers) == 0 {
-		return nil
-	}
-
 	inserted := []*Path{}
 	for _, path := range c.Path {
 		for _, flag := range path.Flags {
+			if len(flag.Tag.Sources) > 0 {
+				resolved, err := c.resolveSourced(path, flag, resolvers)
+				if err != nil {
+					return err
+				}
+				if resolved != nil {
+					inserted = append(inserted, resolved)
+				}
+				continue
+			}
 			// Flag has already been set on the command-line.
 			if _, ok := c.values[flag.Value]; ok {
 				continue
@@ -712,12 +1036,25 @@ func (c *Context) Apply() (string, error) {
 		if value != nil {
 			value.Apply(c.getValue(value))
 		}
+		if node := trace.Node(); node != nil && node.Rest != nil && c.rest != nil {
+			node.Rest.Apply(reflect.ValueOf(append([]string{}, c.rest...)))
+		}
 	}
+	c.applyIndexedGroups()
 
 	return strings.Join(path, " "), nil
 }
 
 func flipBoolValue(value reflect.Value) error {
+	if value.Type() == tristateType {
+		switch Tristate(value.Int()) {
+		case TristateTrue:
+			value.SetInt(int64(TristateFalse))
+		case TristateFalse:
+			value.SetInt(int64(TristateTrue))
+		}
+		return nil
+	}
 	if value.Kind() == reflect.Bool {
 		value.SetBool(!value.Bool())
 		return nil
@@ -733,6 +1070,57 @@ func flipBoolValue(value reflect.Value) error {
 	return fmt.Errorf("cannot negate a value of %s", value.Type().String())
 }
 
+// checkMaxCount checks that "value" does not exceed the "maxcount" tag of "flag".
+func checkMaxCount(msgs catalog, flag *Flag, value reflect.Value) error {
+	if flag.Tag.MaxCount == 0 {
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		if value.Len() > flag.Tag.MaxCount {
+			return flag.withErrHelp(msgs.errorf(MessageMaxValues, flag.ShortSummary(), flag.Tag.MaxCount))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Int() > int64(flag.Tag.MaxCount) {
+			return flag.withErrHelp(msgs.errorf(MessageMaxTimes, flag.ShortSummary(), flag.Tag.MaxCount))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > uint64(flag.Tag.MaxCount) {
+			return flag.withErrHelp(msgs.errorf(MessageMaxTimes, flag.ShortSummary(), flag.Tag.MaxCount))
+		}
+	}
+	return nil
+}
+
+// slashFlagName returns the name of the flag matched by a Windows-style "/flag[:value]" argument, or "".
+//
+// Names are matched case-insensitively against flag names, aliases and the negated names of negatable flags, eg.
+// "/no-color", while short flags are matched case-sensitively as "/v" and "/V" are commonly different flags. "/?"
+// matches the help flag.
+func slashFlagName(arg string, flags []*Flag) string {
+	if !strings.HasPrefix(arg, "/") {
+		return ""
+	}
+	name, _, _ := strings.Cut(arg[1:], ":")
+	if name == "?" {
+		name = "help"
+	}
+	for _, flag := range flags {
+		if strings.EqualFold(flag.Name, name) || (flag.Short != 0 && string(flag.Short) == name) {
+			return flag.Name
+		}
+		for _, alias := range flag.Aliases {
+			if strings.EqualFold(alias, name) {
+				return flag.Name
+			}
+		}
+		if neg := negatableFlagName(flag.Name, flag.Tag.Negatable); neg != "" && strings.EqualFold(neg[2:], name) {
+			return neg[2:]
+		}
+	}
+	return ""
+}
+
 func (c *Context) parseFlag(flags []*Flag, match string) (err error) {
 	candidates := []string{}
 
@@ -757,17 +1145,23 @@ func (c *Context) parseFlag(flags []*Flag, match string) (err error) {
 		}
 		// Found a matching flag.
 		c.scan.Pop()
-		if match == neg && flag.Tag.Negatable != "" {
-			flag.Negated = true
+		flag.Negated = match == neg && flag.Tag.Negatable != ""
+		if c.requireEquals && strings.HasPrefix(match, "--") && !flag.IsBool() && !flag.IsCounter() && c.scan.Peek().Type != FlagValueToken {
+			return c.messages.errorf(MessageRequiresEquals, match, flag.Summary())
 		}
+		remaining := c.scan.Len()
+		raw := c.scan.PeekAll()
 		err := flag.Parse(c.scan, c.getValue(flag.Value))
 		if err != nil {
 			var expected *expectedError
 			if errors.As(err, &expected) && expected.token.InferredType().IsAny(FlagToken, ShortFlagToken) {
-				return fmt.Errorf("%s; perhaps try %s=%q?", err.Error(), flag.ShortSummary(), expected.token)
+				return c.messages.errorf(MessagePerhapsTry, err.Error(), flag.ShortSummary(), expected.token)
 			}
 			return err
 		}
+		if err := checkMaxCount(c.messages, flag, c.getValue(flag.Value)); err != nil {
+			return err
+		}
 		if flag.Negated {
 			value := c.getValue(flag.Value)
 			err := flipBoolValue(value)
@@ -776,13 +1170,25 @@ func (c *Context) parseFlag(flags []*Flag, match string) (err error) {
 			}
 			flag.Value.Apply(value)
 		}
+		if callbacks := c.Kong.flagCallbacks[flag]; len(callbacks) > 0 {
+			// The raw value is made up of the tokens consumed by the flag.
+			values := []string{}
+			for i := 0; i < remaining-c.scan.Len() && i < len(raw); i++ {
+				values = append(values, raw[i].String())
+			}
+			for _, callback := range callbacks {
+				if err := callback(c, strings.Join(values, " ")); err != nil {
+					return err
+				}
+			}
+		}
 		c.Path = append(c.Path, &Path{
 			Flag:      flag,
 			remainder: c.scan.PeekAll(),
 		})
 		return nil
 	}
-	return &unknownFlagError{Cause: findPotentialCandidates(match, candidates, "unknown flag %s", match)}
+	return &unknownFlagError{Cause: findPotentialCandidates(c.messages, match, candidates, MessageUnknownFlag, match)}
 }
 
 func isUnknownFlagError(err error) bool {
@@ -802,23 +1208,50 @@ func (c *Context) Call(fn any, binds ...any) (out []any, err error) {
 	return callAnyFunction(fv, bindings)
 }
 
+// Args maps the names of positional arguments to their values, and can be bound to Run() methods.
+//
+// Go does not expose the names of function parameters, so positional arguments are looked up by name in Args
+// instead, eg.
+//
+//	func (c *CopyCmd) Run(args kong.Args) error {
+//		return copyFile(args["src"].(string), args["dst"].(string))
+//	}
+type Args map[string]any
+
+// positionalArgs returns the positional and branching arguments of "node" and its ancestors.
+func positionalArgs(node *Node) Args {
+	args := Args{}
+	for ; node != nil; node = node.Parent {
+		positional := node.Positional
+		if node.Argument != nil {
+			positional = append([]*Value{node.Argument}, positional...)
+		}
+		for _, arg := range positional {
+			if _, ok := args[arg.Name]; !ok && arg.Target.IsValid() {
+				args[arg.Name] = arg.Target.Interface()
+			}
+		}
+	}
+	return args
+}
+
 // RunNode calls the Run() method on an arbitrary node.
 //
 // This is useful in conjunction with Visit(), for dynamically running commands.
 //
 // Any passed values will be bindable to arguments of the target Run() method. Additionally,
-// all parent nodes in the command structure will be bound.
+// all parent nodes in the command structure and the positional arguments, as Args, will be bound.
 func (c *Context) RunNode(node *Node, binds ...any) (err error) {
 	type targetMethod struct {
 		node   *Node
 		method reflect.Value
 		binds  bindings
 	}
-	methodBinds := c.Kong.bindings.clone().add(binds...).add(c).merge(c.bindings)
+	methodBinds := c.Kong.bindings.clone().add(binds...).add(c, c.dryRun(), c.formatter(), c.noInput()).merge(c.bindings)
 	methods := []targetMethod{}
 	for i := 0; node != nil; i, node = i+1, node.Parent {
-		method := getMethod(node.Target, "Run")
-		methodBinds = methodBinds.clone()
+		method := c.Kong.getRunMethod(node.Target)
+		methodBinds = methodBinds.clone().add(positionalArgs(node))
 		for p := node; p != nil; p = p.Parent {
 			methodBinds = methodBinds.add(p.Target.Addr().Interface())
 			// Try value and pointer to value.
@@ -843,7 +1276,7 @@ func (c *Context) RunNode(node *Node, binds ...any) (err error) {
 		return fmt.Errorf("no Run() method found in hierarchy of %s", c.Selected().Summary())
 	}
 	for _, method := range methods {
-		if err = callFunction(method.method, method.binds); err != nil {
+		if err = c.callWithRetry(method.node, method.method, method.binds); err != nil {
 			return err
 		}
 	}
@@ -852,9 +1285,25 @@ func (c *Context) RunNode(node *Node, binds ...any) (err error) {
 
 // Run executes the Run() method on the selected command, which must exist.
 //
+// Values tagged as secret are zeroed once Run completes.
+//
+// AfterRun hooks are called even if Run() fails or panics, and can bind the "error" Run() returned. Cleanups
+// registered with OnExit are called after them.
+//
 // Any passed values will be bindable to arguments of the target Run() method. Additionally,
 // all parent nodes in the command structure will be bound.
 func (c *Context) Run(binds ...any) (err error) {
+	defer c.zeroSecrets()
+	defer func() {
+		if exitErr := c.exit(); exitErr != nil {
+			err = errors.Join(err, exitErr)
+		}
+	}()
+	if c.shared {
+		if err := c.reapply(); err != nil {
+			return err
+		}
+	}
 	node := c.Selected()
 	if node == nil {
 		if len(c.Path) == 0 {
@@ -862,7 +1311,7 @@ func (c *Context) Run(binds ...any) (err error) {
 		}
 		selected := c.Path[0].Node()
 		if selected.Type == ApplicationNode {
-			method := getMethod(selected.Target, "Run")
+			method := c.Kong.getRunMethod(selected.Target)
 			if method.IsValid() {
 				node = selected
 			}
@@ -872,9 +1321,75 @@ func (c *Context) Run(binds ...any) (err error) {
 			return fmt.Errorf("no command selected")
 		}
 	}
-	runErr := c.RunNode(node, binds...)
-	err = c.Kong.applyHook(c, "AfterRun")
-	return errors.Join(runErr, err)
+	restore, err := c.enterScope(node)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	if c.Kong.interrupts != nil {
+		defer c.watchInterrupts()()
+	}
+	var (
+		runErr error
+		audit  AuditRecord
+	)
+	if c.Kong.auditLog != nil {
+		audit = c.newAuditRecord()
+	}
+	defer func() {
+		// AfterRun hooks are called even if Run() panics, after which the panic resumes.
+		recovered := recover()
+		if recovered != nil {
+			runErr = fmt.Errorf("panic: %v", recovered)
+		}
+		extra := bindings{}
+		extra[reflect.TypeOf((*error)(nil)).Elem()] = newValueBinding(reflect.ValueOf(&runErr).Elem())
+		hookErr := c.Kong.applyHook(c, "AfterRun", extra)
+		if c.Kong.auditLog != nil {
+			audit.Duration = time.Since(audit.Start)
+			audit.Err = errors.Join(runErr, hookErr)
+			c.Kong.auditLog(audit)
+		}
+		if recovered != nil {
+			panic(recovered)
+		}
+		err = errors.Join(runErr, hookErr)
+	}()
+	runErr = c.RunNode(node, binds...)
+	return nil
+}
+
+// reapply resets the grammar and applies the values of this Context to it again, without calling hooks.
+func (c *Context) reapply() error {
+	if err := c.Reset(); err != nil {
+		return err
+	}
+	if err := c.Resolve(); err != nil {
+		return err
+	}
+	_, err := c.Apply()
+	return err
+}
+
+// Clear string and []byte values tagged as secrets, as a best-effort to limit how long they stay in memory.
+//
+// The backing array of []byte values is overwritten with zeros, but strings are immutable so only the reference to
+// their bytes is dropped, which may remain in memory until reused.
+func (c *Context) zeroSecrets() {
+	_ = Visit(c.Model, func(node Visitable, next Next) error {
+		if value, ok := node.(*Value); ok && value.Tag.Secret && value.Target.IsValid() {
+			target := reflect.Indirect(value.Target)
+			switch {
+			case target.Kind() == reflect.String:
+				target.SetString("")
+			case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8:
+				for i := 0; i < target.Len(); i++ {
+					target.Index(i).SetUint(0)
+				}
+			}
+		}
+		return next(nil)
+	})
 }
 
 // PrintUsage to Kong's stdout.
@@ -886,12 +1401,41 @@ func (c *Context) PrintUsage(summary bool) error {
 	return c.help(options, c)
 }
 
-func checkMissingFlags(flags []*Flag) error {
+// selectedCommands returns the full paths of the selected commands, eg. "cluster" and "cluster deploy".
+func (c *Context) selectedCommands() map[string]bool {
+	out := map[string]bool{}
+	names := []string{}
+	for _, path := range c.Path {
+		if path.Command != nil {
+			names = append(names, path.Command.Name)
+			out[strings.Join(names, " ")] = true
+		}
+	}
+	return out
+}
+
+// flagRequired returns true if "flag" is required, or if its requiredif tag names one of the "selected" commands.
+func flagRequired(flag *Flag, selected map[string]bool) bool {
+	if flag.Required {
+		return true
+	}
+	for _, cmd := range flag.Tag.RequiredIf {
+		if selected[cmd] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMissingFlags returns a MissingFlagsError if any required flags are missing, listed on separate lines if
+// "grouped" is true and there are several. "selected" holds the paths of the selected commands, for requiredif.
+func checkMissingFlags(msgs catalog, flags []*Flag, grouped bool, selected map[string]bool) error {
 	xorGroupSet := map[string]bool{}
 	xorGroup := map[string][]string{}
 	andGroupSet := map[string]bool{}
 	andGroup := map[string][]string{}
 	missing := []string{}
+	missingFlags := []*Flag{}
 	andGroupRequired := getRequiredAndGroupMap(flags)
 	for _, flag := range flags {
 		for _, and := range flag.And {
@@ -905,7 +1449,7 @@ func checkMissingFlags(flags []*Flag) error {
 				andGroupSet[and] = true
 			}
 		}
-		if !flag.Required || flag.Set {
+		if !flagRequired(flag, selected) || flag.Set {
 			continue
 		}
 		if len(flag.Xor) > 0 || len(flag.And) > 0 {
@@ -920,26 +1464,35 @@ func checkMissingFlags(flags []*Flag) error {
 			}
 		} else {
 			missing = append(missing, flag.Summary())
+			missingFlags = append(missingFlags, flag)
 		}
 	}
+	others := []string{}
 	for xor, flags := range xorGroup {
 		if !xorGroupSet[xor] && len(flags) > 1 {
-			missing = append(missing, strings.Join(flags, " or "))
+			others = append(others, strings.Join(flags, " or "))
 		}
 	}
 	for _, flags := range andGroup {
 		if len(flags) > 1 {
-			missing = append(missing, strings.Join(flags, " and "))
+			others = append(others, strings.Join(flags, " and "))
 		}
 	}
+	missing = append(missing, others...)
 
 	if len(missing) == 0 {
 		return nil
 	}
 
 	sort.Strings(missing)
+	sort.Strings(others)
+	sort.SliceStable(missingFlags, func(i, j int) bool { return missingFlags[i].Name < missingFlags[j].Name })
 
-	return fmt.Errorf("missing flags: %s", strings.Join(missing, ", "))
+	err := &MissingFlagsError{Flags: missingFlags, message: msgs.sprintf(MessageMissingFlags, strings.Join(missing, ", "))}
+	if grouped && len(missing) > 1 {
+		err.message = strings.TrimRight(msgs.sprintf(MessageMissingFlags, ""), " ") + formatMissingFlags(missingFlags, others)
+	}
+	return err
 }
 
 func getRequiredAndGroupMap(flags []*Flag) map[string]bool {
@@ -954,7 +1507,9 @@ func getRequiredAndGroupMap(flags []*Flag) map[string]bool {
 	return andGroupRequired
 }
 
-func checkMissingChildren(node *Node) error {
+// checkMissingChildren reports missing positional arguments of "node", and a missing subcommand or branching
+// argument if "children" is true.
+func checkMissingChildren(msgs catalog, node *Node, children bool) error {
 	missing := []string{}
 
 	missingArgs := []string{}
@@ -968,7 +1523,7 @@ func checkMissingChildren(node *Node) error {
 	}
 
 	for _, child := range node.Children {
-		if child.Hidden {
+		if child.Hidden || !children {
 			continue
 		}
 		if child.Argument != nil {
@@ -988,13 +1543,13 @@ func checkMissingChildren(node *Node) error {
 		missing = append(missing[:5], "...")
 	}
 	if len(missing) == 1 {
-		return fmt.Errorf("expected %s", missing[0])
+		return msgs.errorf(MessageExpected, missing[0])
 	}
-	return fmt.Errorf("expected one of %s", strings.Join(missing, ", "))
+	return msgs.errorf(MessageExpectedOneOf, strings.Join(missing, ", "))
 }
 
 // If we're missing any positionals and they're required, return an error.
-func checkMissingPositionals(positional int, values []*Value) error {
+func checkMissingPositionals(msgs catalog, positional int, values []*Value) error {
 	// All the positionals are in.
 	if positional >= len(values) {
 		return nil
@@ -1019,38 +1574,89 @@ func checkMissingPositionals(positional int, values []*Value) error {
 	if len(missing) == 0 {
 		return nil
 	}
-	return fmt.Errorf("missing positional arguments %s", strings.Join(missing, " "))
+	return msgs.errorf(MessageMissingPositionals, strings.Join(missing, " "))
 }
 
-func checkEnum(value *Value, target reflect.Value) error {
+func checkEnum(msgs catalog, value *Value, target reflect.Value) error {
+	return forEachElement(value.ShortSummary(), target, func(label string, element reflect.Value) error {
+		if element.Kind() == reflect.Struct {
+			return errors.New("enum can only be applied to a slice, map or value")
+		}
+		enumSlice := value.EnumSlice()
+		v := fmt.Sprintf("%v", element)
+		enums := []string{}
+		for _, enum := range enumSlice {
+			if enum == v {
+				return nil
+			}
+			enums = append(enums, fmt.Sprintf("%q", enum))
+		}
+		return value.withErrHelp(msgs.errorf(MessageEnum, label, strings.Join(enums, ","), value.Redact(fmt.Sprintf("%v", element.Interface()))))
+	})
+}
+
+// checkConstraints checks "target" against the "pattern", "min" and "max" tags of "value".
+func checkConstraints(msgs catalog, value *Value, target reflect.Value) error {
+	tag := value.Tag
+	if tag.Pattern == nil && tag.Min == nil && tag.Max == nil {
+		return nil
+	}
+	return forEachElement(value.ShortSummary(), target, func(label string, element reflect.Value) error {
+		formatted := value.Redact(fmt.Sprintf("%v", element.Interface()))
+		if tag.Pattern != nil && !tag.Pattern.MatchString(fmt.Sprintf("%v", element.Interface())) {
+			return value.withErrHelp(msgs.errorf(MessagePattern, label, tag.Get("pattern"), formatted))
+		}
+		var n float64
+		switch element.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(element.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(element.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = element.Float()
+		default:
+			return nil
+		}
+		if tag.Min != nil && n < *tag.Min {
+			return value.withErrHelp(msgs.errorf(MessageMin, label, *tag.Min, formatted))
+		}
+		if tag.Max != nil && n > *tag.Max {
+			return value.withErrHelp(msgs.errorf(MessageMax, label, *tag.Max, formatted))
+		}
+		return nil
+	})
+}
+
+// forEachElement calls "fn" with each element of slices and each value of maps in "target", or with "target"
+// itself for other values, labelling elements with their index or key, eg. "--flag[1]" or "--flag[key]".
+func forEachElement(label string, target reflect.Value, fn func(label string, element reflect.Value) error) error {
 	switch target.Kind() {
 	case reflect.Slice, reflect.Array:
 		for i := 0; i < target.Len(); i++ {
-			if err := checkEnum(value, target.Index(i)); err != nil {
+			if err := forEachElement(fmt.Sprintf("%s[%d]", label, i), target.Index(i), fn); err != nil {
 				return err
 			}
 		}
 		return nil
 
-	case reflect.Map, reflect.Struct:
-		return errors.New("enum can only be applied to a slice or value")
+	case reflect.Map:
+		keys := target.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			if err := forEachElement(fmt.Sprintf("%s[%v]", label, key), target.MapIndex(key), fn); err != nil {
+				return err
+			}
+		}
+		return nil
 
 	case reflect.Ptr:
 		if target.IsNil() {
 			return nil
 		}
-		return checkEnum(value, target.Elem())
+		return forEachElement(label, target.Elem(), fn)
+
 	default:
-		enumSlice := value.EnumSlice()
-		v := fmt.Sprintf("%v", target)
-		enums := []string{}
-		for _, enum := range enumSlice {
-			if enum == v {
-				return nil
-			}
-			enums = append(enums, fmt.Sprintf("%q", enum))
-		}
-		return fmt.Errorf("%s must be one of %s but got %q", value.ShortSummary(), strings.Join(enums, ","), fmt.Sprintf("%v", target.Interface()))
+		return fn(label, target)
 	}
 }
 
@@ -1064,12 +1670,12 @@ func checkPassthroughArg(target reflect.Value) bool {
 	}
 }
 
-func checkXorDuplicatedAndAndMissing(paths []*Path) error {
+func checkXorDuplicatedAndAndMissing(msgs catalog, paths []*Path) error {
 	errs := []string{}
-	if err := checkXorDuplicates(paths); err != nil {
+	if err := checkXorDuplicates(msgs, paths); err != nil {
 		errs = append(errs, err.Error())
 	}
-	if err := checkAndMissing(paths); err != nil {
+	if err := checkAndMissing(msgs, paths); err != nil {
 		errs = append(errs, err.Error())
 	}
 	if len(errs) > 0 {
@@ -1078,7 +1684,7 @@ func checkXorDuplicatedAndAndMissing(paths []*Path) error {
 	return nil
 }
 
-func checkXorDuplicates(paths []*Path) error {
+func checkXorDuplicates(msgs catalog, paths []*Path) error {
 	for _, path := range paths {
 		seen := map[string]*Flag{}
 		for _, flag := range path.Flags {
@@ -1087,7 +1693,7 @@ func checkXorDuplicates(paths []*Path) error {
 			}
 			for _, xor := range flag.Xor {
 				if seen[xor] != nil {
-					return fmt.Errorf("--%s and --%s can't be used together", seen[xor].Name, flag.Name)
+					return msgs.errorf(MessageXor, seen[xor].Name, flag.Name)
 				}
 				seen[xor] = flag
 			}
@@ -1096,7 +1702,7 @@ func checkXorDuplicates(paths []*Path) error {
 	return nil
 }
 
-func checkAndMissing(paths []*Path) error {
+func checkAndMissing(msgs catalog, paths []*Path) error {
 	for _, path := range paths {
 		missingMsgs := []string{}
 		andGroups := map[string][]*Flag{}
@@ -1118,7 +1724,7 @@ func checkAndMissing(paths []*Path) error {
 				}
 			}
 			if len(notSet) > 0 && oneSet {
-				missingMsgs = append(missingMsgs, fmt.Sprintf("--%s must be used together", strings.Join(flagNames, " and --")))
+				missingMsgs = append(missingMsgs, msgs.sprintf(MessageAnd, strings.Join(flagNames, " and --")))
 			}
 		}
 		if len(missingMsgs) > 0 {
@@ -1128,9 +1734,9 @@ func checkAndMissing(paths []*Path) error {
 	return nil
 }
 
-func findPotentialCandidates(needle string, haystack []string, format string, args ...any) error {
+func findPotentialCandidates(msgs catalog, needle string, haystack []string, message Message, args ...any) error {
 	if len(haystack) == 0 {
-		return fmt.Errorf(format, args...)
+		return msgs.errorf(message, args...)
 	}
 	closestCandidates := []string{}
 	for _, candidate := range haystack {
@@ -1138,11 +1744,11 @@ func findPotentialCandidates(needle string, haystack []string, format string, ar
 			closestCandidates = append(closestCandidates, fmt.Sprintf("%q", candidate))
 		}
 	}
-	prefix := fmt.Sprintf(format, args...)
+	prefix := msgs.sprintf(message, args...)
 	if len(closestCandidates) == 1 {
-		return fmt.Errorf("%s, did you mean %s?", prefix, closestCandidates[0])
+		return msgs.errorf(MessageDidYouMean, prefix, closestCandidates[0])
 	} else if len(closestCandidates) > 1 {
-		return fmt.Errorf("%s, did you mean one of %s?", prefix, strings.Join(closestCandidates, ", "))
+		return msgs.errorf(MessageDidYouMeanOneOf, prefix, strings.Join(closestCandidates, ", "))
 	}
 	return fmt.Errorf("%s", prefix)
 }
@@ -0,0 +1,139 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DebugModelFlag adds a hidden --kong-debug-model flag to the application, which prints the constructed model
+// tree and exits. It shows each command, argument and flag with its Go type, tags, group and the resolvers
+// consulted, to debug why a field was mapped to an unexpected kind of node.
+func DebugModelFlag() Option {
+	return OptionFunc(func(k *Kong) error {
+		k.debugModelFlag = true
+		return nil
+	})
+}
+
+type debugModelFlag bool
+
+func (d debugModelFlag) IgnoreDefault() {}
+
+func (d debugModelFlag) BeforeReset(ctx *Context) error {
+	DumpModel(ctx.Stdout, ctx.Model, ctx.combineResolvers()...)
+	ctx.Kong.Exit(0)
+	return nil
+}
+
+// addDebugModelFlag adds the --kong-debug-model flag to the root if enabled with DebugModelFlag.
+func (k *Kong) addDebugModelFlag() {
+	if !k.debugModelFlag {
+		return
+	}
+	var target debugModelFlag
+	value := reflect.ValueOf(&target).Elem()
+	flag := &Flag{
+		Value: &Value{
+			Name:         "kong-debug-model",
+			Help:         "Print the command-line model and exit.",
+			OrigHelp:     "Print the command-line model and exit.",
+			Target:       value,
+			Tag:          &Tag{},
+			Mapper:       k.registry.ForValue(value),
+			DefaultValue: reflect.ValueOf(false),
+		},
+		Hidden: true,
+	}
+	flag.Flag = flag
+	k.Model.Flags = append(k.Model.Flags, flag)
+}
+
+// DumpModel writes a readable description of the model tree to "w", as printed by DebugModelFlag.
+func DumpModel(w io.Writer, app *Application, resolvers ...Resolver) {
+	if len(resolvers) > 0 {
+		names := make([]string, len(resolvers))
+		for i, resolver := range resolvers {
+			names[i] = fmt.Sprintf("%T", resolver)
+		}
+		fmt.Fprintf(w, "resolvers: %s\n", strings.Join(names, ", "))
+	}
+	dumpNode(w, "", app.Node)
+}
+
+func dumpNode(w io.Writer, indent string, node *Node) {
+	kind := "command"
+	switch node.Type {
+	case ApplicationNode:
+		kind = "application"
+	case ArgumentNode:
+		kind = "argument"
+	}
+	line := fmt.Sprintf("%s%s (%s", indent, node.Name, kind)
+	if node.Target.IsValid() {
+		line += " " + dumpType(node.Target.Type())
+	}
+	line += ")"
+	line += dumpAttrs(node.Group, node.Hidden, node.Tag)
+	fmt.Fprintln(w, line)
+	indent += "  "
+	if node.Argument != nil {
+		fmt.Fprintf(w, "%s<%s> %s\n", indent, node.Argument.Name, dumpType(node.Argument.Target.Type()))
+	}
+	for _, flag := range node.Flags {
+		fmt.Fprintf(w, "%s--%s %s%s%s\n", indent, flag.Name, dumpType(flag.Target.Type()), dumpAttrs(flag.Group, flag.Hidden, flag.Tag), dumpEnvVar(flag.Value))
+	}
+	for _, positional := range node.Positional {
+		fmt.Fprintf(w, "%s<%s> %s%s%s\n", indent, positional.Name, dumpType(positional.Target.Type()), dumpAttrs(nil, false, positional.Tag), dumpEnvVar(positional))
+	}
+	for _, child := range node.Children {
+		dumpNode(w, indent, child)
+	}
+}
+
+// dumpType abbreviates anonymous struct types, whose fields are listed separately.
+func dumpType(t reflect.Type) string {
+	if t.Kind() == reflect.Struct && t.Name() == "" {
+		return "struct {...}"
+	}
+	return t.String()
+}
+
+// dumpEnvVar formats the envar that supplied "value", once parsed.
+func dumpEnvVar(value *Value) string {
+	if value.EnvVar == "" {
+		return ""
+	}
+	return fmt.Sprintf(" envvar=%q", value.EnvVar)
+}
+
+// dumpAttrs formats the group, visibility and tags of a node or value, with tags in a stable order.
+func dumpAttrs(group *Group, hidden bool, tag *Tag) string {
+	out := ""
+	if group != nil {
+		out += fmt.Sprintf(" group=%q", group.Key)
+	}
+	if hidden {
+		out += " hidden"
+	}
+	if tag == nil || len(tag.items) == 0 {
+		return out
+	}
+	keys := make([]string, 0, len(tag.items))
+	for key := range tag.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tags := []string{}
+	for _, key := range keys {
+		for _, value := range tag.items[key] {
+			tags = append(tags, fmt.Sprintf("%s:%q", key, value))
+		}
+	}
+	return out + " `" + strings.Join(tags, " ") + "`"
+}
@@ -0,0 +1,63 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestDebugModelFlag(t *testing.T) {
+	var cli struct {
+		Verbose bool `short:"v"`
+		Deploy  struct {
+			Region string `group:"Cloud" default:"eu"`
+			Target string `arg:""`
+		} `cmd:"" help:"Deploy."`
+	}
+	w := &strings.Builder{}
+	exited := false
+	p := mustNew(t, &cli, kong.Name("app"), kong.Writers(w, w), kong.DebugModelFlag(),
+		kong.Exit(func(int) { exited = true }),
+		kong.Resolvers(kong.ResolverFunc(func(*kong.Context, *kong.Path, *kong.Flag) (any, error) { return nil, nil })))
+	_, _ = p.Parse([]string{"--kong-debug-model"})
+	assert.True(t, exited)
+	assert.Equal(t, `resolvers: kong.ResolverFunc
+app (application struct {...})
+  --help kong.helpFlag
+  --verbose bool `+"`short:\"v\"`"+`
+  --kong-debug-model kong.debugModelFlag hidden
+  deploy (command struct {...}) `+"`cmd:\"\" help:\"Deploy.\"`"+`
+    --region string group="Cloud" `+"`default:\"eu\" group:\"Cloud\"`"+`
+    <target> string `+"`arg:\"\"`"+`
+`, w.String())
+}
+
+func TestDumpModelEnvVar(t *testing.T) {
+	var cli struct {
+		Dir string `env:"NEW_DIR,LEGACY_DIR"`
+	}
+	t.Setenv("LEGACY_DIR", "/data")
+	p := mustNew(t, &cli, kong.Name("app"))
+	ctx, err := p.Parse(nil)
+	assert.NoError(t, err)
+	w := &strings.Builder{}
+	kong.DumpModel(w, ctx.Model)
+	assert.Contains(t, w.String(), "  --dir string `env:\"NEW_DIR,LEGACY_DIR\"` envvar=\"LEGACY_DIR\"\n")
+}
+
+func TestDumpModelVersions(t *testing.T) {
+	var cli struct {
+		Wait  bool `since:"2.0"`
+		Force bool `removedin:"3.0"`
+	}
+	w := &strings.Builder{}
+	kong.DumpModel(w, mustNew(t, &cli, kong.Name("app")).Model)
+	assert.Contains(t, w.String(), "  --wait bool `since:\"2.0\"`\n")
+	assert.Contains(t, w.String(), "  --force bool `removedin:\"3.0\"`\n")
+}
@@ -0,0 +1,142 @@
+package kong
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ansiErrorStart = "\x1b[1;31m"
+	ansiReset      = "\x1b[0m"
+)
+
+// ErrorDiagnostics renders parse errors with a copy of the command-line and a caret under the
+// offending argument, like a compiler diagnostic.
+//
+// If "color" is true the offending argument and caret are highlighted with ANSI escapes.
+func ErrorDiagnostics(color bool) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.errorDiagnostics = true
+		k.colorDiagnostics = color
+		return nil
+	})
+}
+
+// Position returns the index into Context.Args of the argument that caused the error, or -1 if
+// the error is not attributable to a single argument.
+//
+// Errors parsing or decoding an argument, and enum, constraint and Validate() errors of a parsed flag, argument or
+// command, are attributed to the argument. Errors of values from defaults, environment variables or resolvers are
+// not.
+func (p *ParseError) Position() int {
+	if p.Context == nil {
+		return -1
+	}
+	return p.Context.errorArg
+}
+
+// Diagnostic renders the command-line with a caret under the argument that caused the error.
+//
+// An empty string is returned if the error has no position. Values of secret flags are masked.
+func (p *ParseError) Diagnostic(color bool) string {
+	pos := p.Position()
+	if pos < 0 || pos >= len(p.Context.Args) {
+		return ""
+	}
+	args := p.Context.redactArgs()
+	line := p.Context.Model.Name
+	offset, width := 0, 1
+	for i, arg := range args {
+		arg = quoteArg(arg)
+		if line != "" {
+			line += " "
+		}
+		if i == pos {
+			offset = utf8.RuneCountInString(line)
+			if arg != "" {
+				width = utf8.RuneCountInString(arg)
+			}
+			if color {
+				arg = ansiErrorStart + arg + ansiReset
+			}
+		}
+		line += arg
+	}
+	caret := strings.Repeat("^", width)
+	if color {
+		caret = ansiErrorStart + caret + ansiReset
+	}
+	return "  " + line + "\n  " + strings.Repeat(" ", offset) + caret
+}
+
+// quoteArg quotes "arg" if it would not survive shell word splitting.
+func quoteArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
+// redactArgs returns a copy of Context.Args with the values consumed by flags tagged as secret masked.
+//
+// Values are located from the parsed Path rather than by matching flag names, so that values of aliases, short flags
+// such as -tVALUE and slash flags such as /token:VALUE are masked too.
+func (c *Context) redactArgs() []string {
+	out := append([]string{}, c.Args...)
+	last := -1
+	for _, path := range c.Path {
+		if path.Resolved {
+			continue
+		}
+		end := c.argIndex(path.remainder)
+		if path.Flag != nil && path.Flag.Tag.Secret && end >= 0 && end < len(out) {
+			// The value is in the last argument consumed by the flag, either on its own or after the flag.
+			out[end] = redactFlagArg(out[end], path.Flag, end > last+1)
+		}
+		if end > last {
+			last = end
+		}
+	}
+	return out
+}
+
+// redactFlagArg masks the value in "arg", which is all of "arg" if "standalone", or follows the flag as in
+// --flag=VALUE, /flag:VALUE or -fVALUE.
+func redactFlagArg(arg string, flag *Flag, standalone bool) string {
+	switch {
+	case standalone:
+		return redacted
+	case strings.HasPrefix(arg, "--") && strings.Contains(arg, "="):
+		name, _, _ := strings.Cut(arg, "=")
+		return name + "=" + redacted
+	case strings.HasPrefix(arg, "/") && strings.Contains(arg, ":") && slashFlagName(arg, []*Flag{flag}) != "":
+		name, _, _ := strings.Cut(arg, ":")
+		return name + ":" + redacted
+	case strings.HasPrefix(arg, "-") && flag.Short != 0 && strings.ContainsRune(arg[1:], flag.Short):
+		i := 1 + strings.IndexRune(arg[1:], flag.Short) + utf8.RuneLen(flag.Short)
+		if i < len(arg) {
+			return arg[:i] + redacted
+		}
+	default:
+		// A short flag followed by its value in the next argument, eg. -vt VALUE.
+		return redacted
+	}
+	return arg
+}
+
+// argIndex returns the index into Context.Args of the last argument consumed, given the "remainder" of the scan.
+func (c *Context) argIndex(remainder []Token) int {
+	remaining := 0
+	for _, token := range remainder {
+		if token.Type == UntypedToken {
+			remaining++
+		}
+	}
+	scanned := len(c.Args)
+	if c.rest != nil {
+		// Arguments after "--" are not scanned.
+		scanned -= len(c.rest) + 1
+	}
+	return scanned - remaining - 1
+}
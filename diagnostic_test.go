@@ -0,0 +1,96 @@
+package kong_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestParseErrorDiagnostic(t *testing.T) {
+	var cli struct {
+		Count int
+		Token string `secret:"" short:"t"`
+		Cmd   struct {
+			Arg string `arg:""`
+		} `cmd:""`
+	}
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--token", "hunter2", "--bogus", "cmd"}, "" +
+			"  app --token ******** --bogus cmd\n" +
+			"                       ^^^^^^^"},
+		{[]string{"-thunter2", "--bogus"}, "" +
+			"  app -t******** --bogus\n" +
+			"                 ^^^^^^^"},
+		{[]string{"--count=x"}, "" +
+			"  app --count=x\n" +
+			"      ^^^^^^^^^"},
+		{[]string{"cmd", "a b", "c"}, "" +
+			"  app cmd \"a b\" c\n" +
+			"                ^"},
+		{[]string{"cmd", "héllo", "wörld"}, "" +
+			"  app cmd héllo wörld\n" +
+			"                ^^^^^"},
+		{[]string{"cmd"}, ""},
+	}
+	for _, test := range tests {
+		p := mustNew(t, &cli, kong.Name("app"))
+		_, err := p.Parse(test.args)
+		var parseErr *kong.ParseError
+		assert.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, test.expected, parseErr.Diagnostic(false))
+	}
+}
+
+type diagnosticValidatedCmd struct{}
+
+func (diagnosticValidatedCmd) Validate() error { return errors.New("not today") }
+
+func TestParseErrorPositionValidation(t *testing.T) {
+	var cli struct {
+		Level string                 `enum:"debug,info" default:"info"`
+		Port  int                    `min:"1"`
+		Check diagnosticValidatedCmd `cmd:""`
+		Run   struct{}               `cmd:""`
+	}
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"--level", "trace", "run"}, 1},
+		{[]string{"run", "--port=0"}, 1},
+		{[]string{"--level=debug", "check"}, 1},
+	}
+	for _, test := range tests {
+		p := mustNew(t, &cli)
+		_, err := p.Parse(test.args)
+		var parseErr *kong.ParseError
+		assert.True(t, errors.As(err, &parseErr), "%v", test.args)
+		assert.Equal(t, test.expected, parseErr.Position(), "%v", test.args)
+	}
+
+	// Values from environment variables are not attributable to an argument.
+	t.Setenv("PORT", "0")
+	p := mustNew(t, &cli, kong.DefaultEnvars(""))
+	_, err := p.Parse([]string{"run"})
+	var parseErr *kong.ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, -1, parseErr.Position())
+}
+
+func TestErrorDiagnosticsOption(t *testing.T) {
+	var cli struct{}
+	stderr := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Name("app"), kong.ErrorDiagnostics(true), kong.Writers(&bytes.Buffer{}, stderr), kong.Exit(func(int) {}))
+	_, err := p.Parse([]string{"--bogus"})
+	p.FatalIfErrorf(err)
+	assert.Equal(t, "app: error: unknown flag --bogus\n"+
+		"  app \x1b[1;31m--bogus\x1b[0m\n"+
+		"      \x1b[1;31m^^^^^^^\x1b[0m\n", stderr.String())
+}
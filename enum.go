@@ -0,0 +1,49 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnumProvider registers a function supplying the allowed values of flags and positional arguments tagged
+// `enumfrom:"name"`, so that values such as available profiles or attached devices are validated against reality.
+//
+// "fn" is called when a value tagged with "name" is first validated or completed, and its result is cached for the
+// lifetime of the Kong instance. Only values given on the command-line or by resolvers are validated.
+func EnumProvider(name string, fn func() ([]string, error)) Option {
+	return OptionFunc(func(k *Kong) error {
+		if k.enumProviders == nil {
+			k.enumProviders = map[string]*enumProvider{}
+		}
+		k.enumProviders[name] = &enumProvider{fn: fn}
+		return nil
+	})
+}
+
+type enumProvider struct {
+	fn     func() ([]string, error)
+	done   bool
+	values []string
+	err    error
+}
+
+func (e *enumProvider) get() ([]string, error) {
+	if !e.done {
+		e.values, e.err = e.fn()
+		e.done = true
+	}
+	return e.values, e.err
+}
+
+// resolveEnum sets the enum of "value" from its provider, if it is tagged with "enumfrom".
+func (c *Context) resolveEnum(value *Value) error {
+	if value.Tag.EnumFrom == "" {
+		return nil
+	}
+	values, err := c.Kong.enumProviders[value.Tag.EnumFrom].get()
+	if err != nil {
+		return fmt.Errorf("%s: %w", value.ShortSummary(), err)
+	}
+	value.Enum = strings.Join(values, ",")
+	return nil
+}
@@ -1,12 +1,18 @@
 package kong
 
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
 // ParseError is the error type returned by Kong.Parse().
 //
 // It contains the parse Context that triggered the error.
 type ParseError struct {
 	error
 	Context  *Context
-	exitCode int
+	category ExitCategory
 }
 
 // Unwrap returns the original cause of the error.
@@ -14,8 +20,39 @@ func (p *ParseError) Unwrap() error { return p.error }
 
 // ExitCode returns the status that Kong should exit with if it fails with a ParseError.
 func (p *ParseError) ExitCode() int {
-	if p.exitCode == 0 {
-		return exitNotOk
+	category := p.Category()
+	if p.Context != nil && p.Context.Kong != nil {
+		return p.Context.Kong.exitCodeFor(category)
+	}
+	return DefaultExitCodes[category]
+}
+
+// Category returns the category of the error, which determines its exit status. See ExitCodes.
+func (p *ParseError) Category() ExitCategory {
+	switch {
+	case errors.Is(p.error, context.Canceled):
+		return ExitInterrupted
+	case p.category == 0:
+		return ExitRunError
+	default:
+		return p.category
+	}
+}
+
+// redacted is displayed in place of secret values.
+const redacted = "********"
+
+// redactedError masks a secret value in the message of the error it wraps.
+type redactedError struct {
+	error
+	secret string
+}
+
+func (r *redactedError) Unwrap() error { return r.error }
+
+func (r *redactedError) Error() string {
+	if r.secret == "" {
+		return r.error.Error()
 	}
-	return p.exitCode
+	return strings.ReplaceAll(r.error.Error(), r.secret, redacted)
 }
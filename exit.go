@@ -1,6 +1,10 @@
 package kong
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 const (
 	exitOk    = 0
@@ -8,6 +12,9 @@ const (
 
 	// Semantic exit codes from https://github.com/square/exit?tab=readme-ov-file#about
 	exitUsageError = 80
+
+	// Conventional status of a process terminated by SIGINT.
+	exitInterrupted = 130
 )
 
 // ExitCoder is an interface that may be implemented by an error value to
@@ -17,16 +24,93 @@ type ExitCoder interface {
 	ExitCode() int
 }
 
-// exitCodeFromError returns the exit code for the given error.
-// If err implements the exitCoder interface, the ExitCode method is called.
-// Otherwise, exitCodeFromError returns 0 if err is nil, and 1 if it is not.
-func exitCodeFromError(err error) int {
-	var e ExitCoder
-	if errors.As(err, &e) {
-		return e.ExitCode()
-	} else if err == nil {
-		return exitOk
+// ExitCategory is a category of error that FatalIfErrorf exits with a distinct status for. See ExitCodes.
+type ExitCategory int
+
+const (
+	// ExitUsageError is a command-line that could not be parsed, eg. an unknown flag.
+	ExitUsageError ExitCategory = iota + 1
+	// ExitValidationError is a command-line that failed validation, eg. a missing required flag.
+	ExitValidationError
+	// ExitRunError is any other error, eg. one returned by a Run() method or a hook.
+	ExitRunError
+	// ExitInterrupted is an error wrapping context.Canceled, eg. after an interrupt cancelled the context.
+	ExitInterrupted
+)
+
+func (e ExitCategory) String() string {
+	switch e {
+	case ExitUsageError:
+		return "usage error"
+	case ExitValidationError:
+		return "validation error"
+	case ExitRunError:
+		return "run error"
+	case ExitInterrupted:
+		return "interrupted"
 	}
+	return fmt.Sprintf("ExitCategory(%d)", int(e))
+}
 
+// DefaultExitCodes are the statuses FatalIfErrorf exits with for each category of error.
+var DefaultExitCodes = map[ExitCategory]int{
+	ExitUsageError:      exitUsageError,
+	ExitValidationError: exitUsageError,
+	ExitRunError:        exitNotOk,
+	ExitInterrupted:     exitInterrupted,
+}
+
+// ExitCodes overrides the statuses FatalIfErrorf exits with for categories of error.
+//
+// Errors implementing ExitCoder, other than a ParseError, still exit with their own status.
+func ExitCodes(codes map[ExitCategory]int) Option {
+	return OptionFunc(func(k *Kong) error {
+		if k.exitCodes == nil {
+			k.exitCodes = map[ExitCategory]int{}
+		}
+		for category, code := range codes {
+			if _, ok := DefaultExitCodes[category]; !ok {
+				return fmt.Errorf("unknown exit category %d", int(category))
+			}
+			k.exitCodes[category] = code
+		}
+		return nil
+	})
+}
+
+// ExitCodes returns the status FatalIfErrorf exits with for each category of error, eg. for documentation.
+func (k *Kong) ExitCodes() map[ExitCategory]int {
+	out := map[ExitCategory]int{}
+	for category := range DefaultExitCodes {
+		out[category] = k.exitCodeFor(category)
+	}
+	return out
+}
+
+func (k *Kong) exitCodeFor(category ExitCategory) int {
+	if code, ok := k.exitCodes[category]; ok {
+		return code
+	}
+	if code, ok := DefaultExitCodes[category]; ok {
+		return code
+	}
 	return exitNotOk
 }
+
+// exitCode returns the status FatalIfErrorf exits with for "err".
+func (k *Kong) exitCode(err error) int {
+	var parseErr *ParseError
+	var e ExitCoder
+	switch {
+	case err == nil:
+		return exitOk
+	case errors.As(err, &parseErr):
+		return parseErr.ExitCode()
+	case errors.As(err, &e):
+		return e.ExitCode()
+	case errors.Is(err, context.Canceled):
+		return k.exitCodeFor(ExitInterrupted)
+	default:
+		return k.exitCodeFor(ExitRunError)
+	}
+}
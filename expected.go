@@ -0,0 +1,125 @@
+package kong
+
+import "strings"
+
+// Expected describes what may come next on a command-line, given the Context of a partial trace.
+//
+// It is the basis for completion engines and editor integrations. See Context.Expected.
+type Expected struct {
+	// Node reached by the trace, either the application, a command or a branching argument.
+	Node *Node
+	// Flags accepted at this point, including those inherited from ancestors. Hidden flags are excluded.
+	Flags []*Flag
+	// Positional argument expected next, if any. A cumulative positional argument remains expected once given.
+	Positional *Positional
+	// Commands that may be selected next. Hidden commands are excluded.
+	Commands []*Command
+	// Branching arguments that may be selected next.
+	Arguments []*Argument
+}
+
+// Expected returns what may come next on the command-line traced by the Context.
+//
+// The Context is typically obtained from Trace() on a partial command-line. Commands and branching arguments are
+// only expected once all positional arguments of the Node have been given, as the parser requires.
+func (c *Context) Expected() *Expected {
+	node := c.Selected()
+	if node == nil {
+		node = c.Model.Node
+	}
+	expected := &Expected{Node: node}
+	for _, group := range node.AllFlags(true) {
+		expected.Flags = append(expected.Flags, group...)
+	}
+	given := 0
+	var last *Positional
+	for _, path := range c.Path {
+		if path.Positional != nil && path.Parent == node {
+			given++
+			last = path.Positional
+		}
+	}
+	switch {
+	case last != nil && last.IsCumulative():
+		expected.Positional = last
+	case given < len(node.Positional):
+		expected.Positional = node.Positional[given]
+	}
+	// Errors from enum providers are ignored, as they are reported when the command-line is validated.
+	for _, flag := range expected.Flags {
+		_ = c.resolveEnum(flag.Value)
+	}
+	if expected.Positional != nil {
+		_ = c.resolveEnum(expected.Positional)
+		return expected
+	}
+	for _, child := range node.Children {
+		switch {
+		case child.Type == CommandNode && !child.Hidden:
+			expected.Commands = append(expected.Commands, child)
+		case child.Type == ArgumentNode:
+			expected.Arguments = append(expected.Arguments, child)
+		}
+	}
+	return expected
+}
+
+// Candidate is a suggestion for the next token on a command-line, returned by Context.Suggest.
+type Candidate struct {
+	// Value to insert, eg. "deploy", "--force" or "--format=json".
+	Value string
+	// Help for the command, flag or positional argument the candidate is for.
+	Help string
+}
+
+// Suggest returns the valid next tokens starting with "prefix", given the partial command-line traced by the
+// Context: subcommands and their aliases, flags, and enum values of positional arguments and of flags given as
+// "--flag=<prefix>".
+//
+// Flags are only suggested if "prefix" starts with "-", and candidates are returned in grammar order.
+func (c *Context) Suggest(prefix string) []Candidate {
+	expected := c.Expected()
+	candidates := []Candidate{}
+	add := func(value, help string) {
+		if strings.HasPrefix(value, prefix) {
+			candidates = append(candidates, Candidate{Value: value, Help: help})
+		}
+	}
+	if name, _, ok := strings.Cut(prefix, "="); ok && strings.HasPrefix(name, "--") {
+		for _, flag := range expected.Flags {
+			if "--"+flag.Name == name && flag.Enum != "" {
+				for _, value := range flag.EnumSlice() {
+					add(name+"="+value, flag.Help)
+				}
+			}
+		}
+		return candidates
+	}
+	if strings.HasPrefix(prefix, "-") {
+		for _, flag := range expected.Flags {
+			add("--"+flag.Name, flag.Help)
+			if neg := negatableFlagName(flag.Name, flag.Tag.Negatable); neg != "" {
+				add(neg, flag.Help)
+			}
+			for _, alias := range flag.Aliases {
+				add("--"+alias, flag.Help)
+			}
+			if flag.Short != 0 {
+				add("-"+string(flag.Short), flag.Help)
+			}
+		}
+		return candidates
+	}
+	if positional := expected.Positional; positional != nil && positional.Enum != "" {
+		for _, value := range positional.EnumSlice() {
+			add(value, positional.Help)
+		}
+	}
+	for _, command := range expected.Commands {
+		add(command.Name, command.Help)
+		for _, alias := range command.Aliases {
+			add(alias, command.Help)
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,93 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExplainCommand parses a command-line without running it, and describes what would be executed: the selected
+// command, the final value of each flag along with where it came from, and whether validation passed.
+//
+// Add it to a CLI as eg. an "explain" command. Hooks are not called, so values set by hooks are not reflected.
+type ExplainCommand struct {
+	Args []string `arg:"" optional:"" passthrough:"" help:"Command-line to explain."`
+}
+
+// Run writes the explanation to Kong.Stdout.
+func (e ExplainCommand) Run(app *Kong) error {
+	args, err := app.expandAliases(e.Args)
+	if err != nil {
+		return err
+	}
+	ctx, err := Trace(app, args)
+	if err != nil {
+		return err
+	}
+	if ctx.Error != nil {
+		return ctx.Error
+	}
+	if err := ctx.Reset(); err != nil {
+		return err
+	}
+	if err := ctx.Resolve(); err != nil {
+		return err
+	}
+	command, err := ctx.Apply()
+	if err != nil {
+		return err
+	}
+	if command == "" {
+		command = "(none)"
+	}
+	w := app.Stdout
+	fmt.Fprintf(w, "command: %s\n", command)
+	rows := [][2]string{}
+	for _, flag := range ctx.Flags() {
+		if flag.Hidden {
+			continue
+		}
+		rows = append(rows, [2]string{"--" + flag.Name + "=" + flag.FormatValue(flag.Target), "(" + explainSource(ctx, flag) + ")"})
+	}
+	if len(rows) > 0 {
+		fmt.Fprintln(w, "flags:")
+		width := 0
+		for _, row := range rows {
+			if len(row[0]) > width {
+				width = len(row[0])
+			}
+		}
+		for _, row := range rows {
+			fmt.Fprintf(w, "  %s%s  %s\n", row[0], strings.Repeat(" ", width-len(row[0])), row[1])
+		}
+	}
+	if err := ctx.Validate(); err != nil {
+		fmt.Fprintf(w, "validation: failed: %s\n", err)
+	} else {
+		fmt.Fprintln(w, "validation: passed")
+	}
+	return nil
+}
+
+// explainSource describes where the final value of "flag" came from.
+func explainSource(ctx *Context, flag *Flag) string {
+	source := ""
+	for _, path := range ctx.Path {
+		if path.Flag == flag {
+			if path.Resolved {
+				source = "resolver"
+			} else {
+				source = "command-line"
+			}
+		}
+	}
+	switch {
+	case source != "":
+		return source
+	case flag.EnvVar != "":
+		return "envar " + flag.EnvVar
+	case flag.HasDefault:
+		return "default"
+	default:
+		return "unset"
+	}
+}
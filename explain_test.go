@@ -0,0 +1,44 @@
+package kong_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestExplainCommand(t *testing.T) {
+	var cli struct {
+		Region string `env:"KONG_TEST_REGION"`
+		Format string `default:"text"`
+		Token  string `secret:""`
+		Deploy struct {
+			Force bool
+			Env   string `arg:""`
+		} `cmd:""`
+		Explain kong.ExplainCommand `cmd:""`
+	}
+	t.Setenv("KONG_TEST_REGION", "eu")
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(w, w))
+	ctx, err := p.Parse([]string{"explain", "deploy", "--force", "--token=hunter2", "prod"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.Equal(t, `command: deploy <env>
+flags:
+  --help=false      (unset)
+  --region=eu       (envar KONG_TEST_REGION)
+  --format=text     (default)
+  --token=********  (command-line)
+  --force=true      (command-line)
+validation: passed
+`, w.String())
+
+	w.Reset()
+	ctx, err = p.Parse([]string{"explain", "deploy"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.Contains(t, w.String(), "validation: failed: missing positional arguments <env>\n")
+}
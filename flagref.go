@@ -0,0 +1,105 @@
+package kong
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var flagRefRegex = regexp.MustCompile(`\${flag:([^}]+)}`)
+
+// recordFlagRefs records the help and placeholder of "value" as templates if they reference other flags in the
+// form ${flag:name}, which are only interpolated when help is rendered.
+func recordFlagRefs(value *Value) {
+	if strings.Contains(value.Help, "${flag:") {
+		value.helpTemplate = value.Help
+	}
+	if value.Flag != nil && strings.Contains(value.Flag.PlaceHolder, "${flag:") {
+		value.Flag.placeHolderTemplate = value.Flag.PlaceHolder
+	}
+}
+
+// interpolateFlagRefs interpolates ${flag:name} references in help and placeholders with the current values of
+// the referenced flags, taking into account the command-line, resolvers, environment variables and defaults.
+func (c *Context) interpolateFlagRefs() {
+	_ = Visit(c.Model, func(node Visitable, next Next) error {
+		var value *Value
+		switch node := node.(type) {
+		case *Value:
+			value = node
+		case *Flag:
+			value = node.Value
+		}
+		if value != nil {
+			if value.helpTemplate != "" {
+				value.Help = c.expandFlagRefs(value.helpTemplate)
+			}
+			if value.Flag != nil && value.Flag.placeHolderTemplate != "" {
+				value.Flag.PlaceHolder = c.expandFlagRefs(value.Flag.placeHolderTemplate)
+			}
+		}
+		return next(nil)
+	})
+}
+
+// expandFlagRefs replaces ${flag:name} references in "s". References to unknown flags are left as-is.
+func (c *Context) expandFlagRefs(s string) string {
+	return flagRefRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		name := flagRefRegex.FindStringSubmatch(ref)[1]
+		if value, ok := c.flagRefValue(name); ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// flagRefValue returns the current value of the flag "name", preferring flags on the selected path.
+func (c *Context) flagRefValue(name string) (string, bool) {
+	var flag *Flag
+	for _, candidate := range c.Flags() {
+		if candidate.Name == name {
+			flag = candidate
+			break
+		}
+	}
+	if flag == nil {
+		_ = Visit(c.Model, func(node Visitable, next Next) error {
+			if candidate, ok := node.(*Flag); ok && flag == nil && candidate.Name == name {
+				flag = candidate
+			}
+			return next(nil)
+		})
+	}
+	if flag == nil {
+		return "", false
+	}
+	if value, ok := c.values[flag.Value]; ok {
+		return flag.FormatValue(value), true
+	}
+	for _, path := range c.Path {
+		for _, candidate := range path.Flags {
+			if candidate != flag {
+				continue
+			}
+			var selected any
+			for _, resolver := range c.combineResolvers() {
+				if s, err := resolver.Resolve(c, path, flag); err == nil && s != nil {
+					selected = s
+				}
+			}
+			if selected != nil {
+				return flag.Redact(fmt.Sprintf("%v", selected)), true
+			}
+		}
+	}
+	for _, env := range flag.Tag.Envs {
+		if value, ok := os.LookupEnv(env); ok {
+			return flag.Redact(value), true
+		}
+	}
+	if flag.HasDefault {
+		return flag.Redact(flag.Default), true
+	}
+	return "", true
+}
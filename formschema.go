@@ -0,0 +1,197 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// FormSchema describes each command of an application as a form, for web consoles that render a form equivalent
+// to the command-line.
+//
+// It encodes to JSON. A submitted form is converted back to a command-line with Argv, which should then be parsed
+// as usual, so that the web console and the command-line are validated identically.
+type FormSchema struct {
+	Name     string        `json:"name"`
+	Help     string        `json:"help,omitempty"`
+	Commands []FormCommand `json:"commands"`
+}
+
+// FormCommand is the form for one runnable command.
+type FormCommand struct {
+	Command string      `json:"command"` // eg. "db migrate" or "user <id> show", or "" without commands.
+	Help    string      `json:"help,omitempty"`
+	Group   string      `json:"group,omitempty"`
+	Fields  []FormField `json:"fields"`
+}
+
+// FormField is a flag or positional argument of a FormCommand.
+type FormField struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind"`             // "flag" or "arg".
+	Type        string   `json:"type"`             // "string", "boolean", "integer", "number", "array" or "map".
+	Format      string   `json:"format,omitempty"` // Named type, eg. "path" or "password".
+	Help        string   `json:"help,omitempty"`
+	Group       string   `json:"group,omitempty"`
+	Placeholder string   `json:"placeholder,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Secret      bool     `json:"secret,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+	Xor         []string `json:"xor,omitempty"`
+	And         []string `json:"and,omitempty"`
+	Since       string   `json:"since,omitempty"`     // Version the field was introduced in.
+	RemovedIn   string   `json:"removedin,omitempty"` // Version the field will be removed in.
+}
+
+// NewFormSchema describes the visible commands, flags and positional arguments of "app" as forms.
+//
+// The help flag is omitted.
+func NewFormSchema(app *Application) *FormSchema {
+	schema := &FormSchema{Name: app.Name, Help: app.Help, Commands: []FormCommand{}}
+	leaves := app.Leaves(true)
+	if len(leaves) == 0 {
+		leaves = []*Node{app.Node}
+	}
+	for _, node := range leaves {
+		command := FormCommand{Help: node.Help, Fields: []FormField{}}
+		if group := node.ClosestGroup(); group != nil {
+			command.Group = group.Title
+		}
+		for n := node; n != nil; n = n.Parent {
+			if n.Type == ArgumentNode {
+				command.Fields = append([]FormField{newFormField(n.Argument, "arg")}, command.Fields...)
+			}
+		}
+		command.Command = strings.TrimSpace(modelPath(node)[len(app.Name):])
+		for _, group := range node.AllFlags(true) {
+			for _, flag := range group {
+				if flag == app.HelpFlag {
+					continue
+				}
+				field := newFormField(flag.Value, "flag")
+				if flag.Group != nil {
+					field.Group = flag.Group.Title
+				}
+				if !flag.IsBool() {
+					field.Placeholder = flag.FormatPlaceHolder()
+				}
+				field.Xor, field.And = flag.Xor, flag.And
+				command.Fields = append(command.Fields, field)
+			}
+		}
+		for _, positional := range node.Positional {
+			command.Fields = append(command.Fields, newFormField(positional, "arg"))
+		}
+		schema.Commands = append(schema.Commands, command)
+	}
+	return schema
+}
+
+func newFormField(value *Value, kind string) FormField {
+	field := FormField{
+		Name:      value.Name,
+		Kind:      kind,
+		Type:      formFieldType(value),
+		Format:    value.Tag.Type,
+		Help:      value.Help,
+		Default:   value.Default,
+		Required:  value.Required,
+		Secret:    value.Tag.Secret || value.Tag.Type == "password",
+		Min:       value.Tag.Min,
+		Max:       value.Tag.Max,
+		Since:     value.Tag.Since,
+		RemovedIn: value.Tag.RemovedIn,
+	}
+	if value.Enum != "" {
+		field.Enum = value.EnumSlice()
+	}
+	if value.Tag.Pattern != nil {
+		field.Pattern = value.Tag.Pattern.String()
+	}
+	return field
+}
+
+func formFieldType(value *Value) string {
+	switch {
+	case value.IsBool():
+		return "boolean"
+	case value.IsCounter():
+		return "integer"
+	case value.IsSlice():
+		return "array"
+	case value.IsMap():
+		return "map"
+	}
+	switch reflect.Indirect(value.Target).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// Argv converts a submitted form for "command" to a command-line, to be parsed by Kong.
+//
+// Values are keyed by field name. Fields with several values, such as arrays, are repeated. Empty values are
+// omitted. The schema may have been decoded from JSON.
+func (s *FormSchema) Argv(command string, values url.Values) ([]string, error) {
+	var form *FormCommand
+	for i := range s.Commands {
+		if s.Commands[i].Command == command {
+			form = &s.Commands[i]
+		}
+	}
+	if form == nil {
+		return nil, fmt.Errorf("unknown command %q", command)
+	}
+	fields := map[string]FormField{}
+	for _, field := range form.Fields {
+		fields[field.Name] = field
+	}
+	for name := range values {
+		if _, ok := fields[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q for command %q", name, command)
+		}
+	}
+	// Branching arguments are named in the command, eg. "user <id> show", and are not positional arguments.
+	argv := []string{}
+	branching := map[string]bool{}
+	for _, word := range strings.Fields(form.Command) {
+		if strings.HasPrefix(word, "<") && strings.HasSuffix(word, ">") {
+			word = strings.TrimSuffix(strings.TrimPrefix(word, "<"), ">")
+			branching[word] = true
+			word = values.Get(word)
+		}
+		argv = append(argv, word)
+	}
+	positionals := []string{}
+	for _, field := range form.Fields {
+		for _, value := range values[field.Name] {
+			switch {
+			case value == "" || branching[field.Name]:
+			case field.Kind == "flag":
+				argv = append(argv, "--"+field.Name+"="+value)
+			default:
+				positionals = append(positionals, value)
+			}
+		}
+	}
+	for _, positional := range positionals {
+		if strings.HasPrefix(positional, "-") {
+			argv = append(argv, "--")
+			break
+		}
+	}
+	return append(argv, positionals...), nil
+}
@@ -0,0 +1,94 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type formCLI struct {
+	Debug bool `help:"Debug mode." removedin:"2.0"`
+
+	Deploy struct {
+		Env      string   `enum:"dev,prod" default:"dev" help:"Environment."`
+		Replicas int      `min:"1" group:"Scaling" help:"Number of replicas."`
+		Tags     []string `help:"Tags." since:"1.2"`
+		Token    string   `type:"password" required:""`
+		Service  string   `arg:"" help:"Service to deploy."`
+	} `cmd:"" help:"Deploy a service."`
+
+	User struct {
+		ID struct {
+			ID   string `arg:""`
+			Show struct {
+				Verbose bool
+			} `cmd:""`
+		} `arg:""`
+	} `cmd:""`
+}
+
+func TestFormSchema(t *testing.T) {
+	var cli formCLI
+	p := mustNew(t, &cli, kong.Groups{"Scaling": "Scaling flags"})
+	schema := kong.NewFormSchema(p.Model)
+
+	assert.Equal(t, "test", schema.Name)
+	assert.Equal(t, 2, len(schema.Commands))
+	deploy := schema.Commands[0]
+	assert.Equal(t, "deploy", deploy.Command)
+	assert.Equal(t, "Deploy a service.", deploy.Help)
+	one := 1.0
+	assert.Equal(t, []kong.FormField{
+		{Name: "debug", Kind: "flag", Type: "boolean", Help: "Debug mode.", RemovedIn: "2.0"},
+		{Name: "env", Kind: "flag", Type: "string", Help: "Environment.", Placeholder: `"dev"`, Default: "dev", Enum: []string{"dev", "prod"}},
+		{Name: "replicas", Kind: "flag", Type: "integer", Help: "Number of replicas.", Group: "Scaling flags", Placeholder: "INT", Min: &one},
+		{Name: "tags", Kind: "flag", Type: "array", Help: "Tags.", Placeholder: "TAGS,...", Since: "1.2"},
+		{Name: "token", Kind: "flag", Type: "string", Format: "password", Placeholder: "STRING", Required: true, Secret: true},
+		{Name: "service", Kind: "arg", Type: "string", Help: "Service to deploy.", Required: true},
+	}, deploy.Fields)
+	assert.Equal(t, "user <id> show", schema.Commands[1].Command)
+	assert.Equal(t, "id", schema.Commands[1].Fields[0].Name)
+
+	// Round-trip through JSON, as a web console would.
+	data, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	decoded := &kong.FormSchema{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+
+	argv, err := decoded.Argv("deploy", url.Values{
+		"env":      {"prod"},
+		"replicas": {"3"},
+		"tags":     {"a", "b"},
+		"token":    {"s3cret"},
+		"debug":    {"false"},
+		"service":  {"-api"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deploy", "--debug=false", "--env=prod", "--replicas=3", "--tags=a", "--tags=b",
+		"--token=s3cret", "--", "-api"}, argv)
+	_, err = p.Parse(argv)
+	assert.NoError(t, err)
+	assert.Equal(t, "-api", cli.Deploy.Service)
+	assert.Equal(t, []string{"a", "b"}, cli.Deploy.Tags)
+	assert.False(t, cli.Debug)
+
+	argv, err = decoded.Argv("user <id> show", url.Values{"id": {"alice"}, "verbose": {"true"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user", "alice", "show", "--verbose=true"}, argv)
+	_, err = p.Parse(argv)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", cli.User.ID.ID)
+	assert.True(t, cli.User.ID.Show.Verbose)
+
+	_, err = decoded.Argv("deploy", url.Values{"bogus": {"1"}})
+	assert.EqualError(t, err, `unknown field "bogus" for command "deploy"`)
+	_, err = decoded.Argv("destroy", nil)
+	assert.EqualError(t, err, `unknown command "destroy"`)
+}
@@ -14,3 +14,19 @@ func Parse(cli any, options ...Option) *Context {
 	parser.FatalIfErrorf(err)
 	return ctx
 }
+
+// ParseArgs allocates a CLI struct of type T, constructs a new parser on it and parses "args".
+//
+// Unlike Parse, errors are returned rather than being fatal, which makes it convenient for small tools and tests.
+func ParseArgs[T any](args []string, options ...Option) (*T, *Context, error) {
+	cli := new(T)
+	parser, err := New(cli, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, err := parser.Parse(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cli, ctx, nil
+}
@@ -29,3 +29,22 @@ func TestParseHandlingBadBuild(t *testing.T) {
 
 	t.Fatal("we were expecting a panic")
 }
+
+func TestParseArgs(t *testing.T) {
+	type CLI struct {
+		Name  string `arg:""`
+		Force bool
+	}
+	cli, ctx, err := ParseArgs[CLI]([]string{"--force", "bob"})
+	assert.NoError(t, err)
+	assert.Equal(t, &CLI{Name: "bob", Force: true}, cli)
+	assert.Equal(t, "<name>", ctx.Command())
+
+	_, _, err = ParseArgs[CLI]([]string{"--unknown"})
+	assert.EqualError(t, err, "unknown flag --unknown")
+
+	_, _, err = ParseArgs[struct {
+		Enabled bool `kong:"fail='"`
+	}](nil)
+	assert.EqualError(t, err, "fail=' is not quoted properly")
+}
@@ -8,3 +8,7 @@ import "io"
 func guessWidth(w io.Writer) int {
 	return 80
 }
+
+func isTerminal(w io.Writer) bool {
+	return false
+}
@@ -40,3 +40,20 @@ func guessWidth(w io.Writer) int {
 	}
 	return 80
 }
+
+// isTerminal returns true if "w" is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	var dimensions [4]uint16
+	_, _, err := syscall.Syscall6(
+		syscall.SYS_IOCTL,
+		f.Fd(),
+		uintptr(syscall.TIOCGWINSZ),
+		uintptr(unsafe.Pointer(&dimensions)), //nolint: gas
+		0, 0, 0,
+	)
+	return err == 0
+}
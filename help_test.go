@@ -1,10 +1,15 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
 package kong_test
 
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/alecthomas/kong"
@@ -863,3 +868,326 @@ test: error: missing flags: --flag=STRING
 	assert.Equal(t, expected, w.String())
 	assert.Equal(t, 80, exitCode)
 }
+
+func TestTypePlaceHolders(t *testing.T) {
+	var cli struct {
+		Count   int
+		Timeout time.Duration
+		Level   string `enum:"debug,info,warn" default:"info"`
+		Format  string `enum:"${formats}" required:""`
+		Region  string `enum:"a,b,c,d,e,f" required:""`
+		Labels  map[string]string
+		Ports   []int
+		Name    string `placeholder:"NAME"`
+	}
+	w := bytes.NewBuffer(nil)
+	p := mustNew(t, &cli, kong.Name("test-app"), kong.Writers(w, w), kong.Exit(func(int) {}),
+		kong.PlaceHolders(kong.TypePlaceHolders), kong.Vars{"formats": "json,yaml"})
+	_, _ = p.Parse([]string{"--help"})
+	expected := `Usage: test-app --format=<json|yaml> --region=<string> [flags]
+
+Flags:
+  -h, --help                      Show context-sensitive help.
+      --count=<int>
+      --timeout=<duration>
+      --level="info"
+      --format=<json|yaml>
+      --region=<string>
+      --labels=<key=value>;...
+      --ports=<int>,...
+      --name=NAME
+`
+	assert.Equal(t, expected, w.String())
+}
+
+func TestHelpUsageBudget(t *testing.T) {
+	var cli struct {
+		Region string `enum:"us-east-1,us-west-1,eu-west-1,ap-south-1" required:"" placeholder:"{${enum}}"`
+		Zone   string `required:""`
+		Deploy struct {
+			Service string `arg:""`
+		} `cmd:""`
+	}
+	for _, test := range []struct {
+		budget   int
+		expected string
+	}{
+		{0, "Usage: test-app deploy --region={us-east-1,us-west-1,eu-west-1,ap-south-1} --zone=STRING <service>"},
+		{70, "Usage: test-app deploy --region={us-east-1,us-west-1,…} --zone=STRING <service>"},
+		{50, "Usage: test-app deploy <2 required flags> <service>"},
+		{20, "Usage: test-app deploy <2 required …"},
+	} {
+		w := bytes.NewBuffer(nil)
+		p := mustNew(t, &cli, kong.Name("test-app"), kong.Writers(w, w), kong.Exit(func(int) {}),
+			kong.ConfigureHelp(kong.HelpOptions{UsageBudget: test.budget}))
+		_, _ = p.Parse([]string{"deploy", "--help"})
+		assert.Equal(t, test.expected, strings.SplitN(w.String(), "\n", 2)[0])
+	}
+}
+
+type byteSize int64
+
+func TestTypeFormatter(t *testing.T) {
+	var cli struct {
+		Cache   byteSize      `default:"10485760" help:"Cache size (default ${default})."`
+		Timeout time.Duration `default:"3600s"`
+	}
+	w := bytes.NewBuffer(nil)
+	p := mustNew(t, &cli, kong.Name("test-app"), kong.Writers(w, w), kong.Exit(func(int) {}),
+		kong.TypeFormatter(reflect.TypeOf(byteSize(0)), func(value reflect.Value) string {
+			return fmt.Sprintf("%dMiB", value.Int()/(1<<20))
+		}),
+		kong.TypeFormatter(reflect.TypeOf(time.Duration(0)), func(value reflect.Value) string {
+			return strings.TrimSuffix(value.Interface().(time.Duration).String(), "0m0s")
+		}))
+	_, _ = p.Parse([]string{"--help"})
+	assert.Equal(t, `Usage: test-app [flags]
+
+Flags:
+  -h, --help           Show context-sensitive help.
+      --cache=10MiB    Cache size (default 10MiB).
+      --timeout=1h
+`, w.String())
+}
+
+type region string
+
+func TestTypeTags(t *testing.T) {
+	var cli struct {
+		Home    region   `required:""`
+		Backup  *region  `help:"Backup region."`
+		Mirrors []region `placeholder:"R"`
+	}
+	w := bytes.NewBuffer(nil)
+	p := mustNew(t, &cli, kong.Name("test-app"), kong.Writers(w, w), kong.Exit(func(int) {}),
+		kong.TypeTags(reflect.TypeOf(region("")), `enum:"us,eu" help:"Cloud region." placeholder:"REGION"`))
+	_, err := p.Parse([]string{"--home=us", "--backup=eu", "--mirrors=us,eu"})
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"--home=us", "--mirrors=us,ap"})
+	assert.EqualError(t, err, `--mirrors[1] must be one of "us","eu" but got "ap"`)
+	_, _ = p.Parse([]string{"--help"})
+	assert.Equal(t, `Usage: test-app --home=REGION [flags]
+
+Flags:
+  -h, --help             Show context-sensitive help.
+      --home=REGION      Cloud region.
+      --backup=REGION    Backup region.
+      --mirrors=R,...    Cloud region.
+`, w.String())
+}
+
+func TestHelpMetadata(t *testing.T) {
+	var cli struct {
+		Debug bool
+	}
+	w := bytes.NewBuffer(nil)
+	p := mustNew(t, &cli, kong.Name("test-app"), kong.Description("A test app."), kong.Writers(w, w), kong.Exit(func(int) {}),
+		kong.About(kong.Metadata{
+			Author:   "Jane Doe",
+			License:  "MIT",
+			Homepage: "https://example.com",
+			Long:     "Does things for testing.",
+		}))
+	_, _ = p.Parse([]string{"--help"})
+	assert.Equal(t, `Usage: test-app [flags]
+
+A test app.
+
+Does things for testing.
+
+Flags:
+  -h, --help     Show context-sensitive help.
+      --debug
+
+Homepage: https://example.com
+Author: Jane Doe
+License: MIT
+`, w.String())
+	assert.Equal(t, "MIT", p.Model.Metadata.License)
+}
+
+func TestHelpFlagReferences(t *testing.T) {
+	var cli struct {
+		DataDir string `default:"/var/lib/app" env:"KONG_TEST_DATA_DIR"`
+		Cache   string `help:"Cache directory, defaults to ${flag:data-dir}/cache." placeholder:"${flag:data-dir}/cache"`
+	}
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Name("test"), kong.Writers(w, w), kong.Exit(func(int) {}))
+
+	render := func(args ...string) string {
+		w.Reset()
+		ctx, err := kong.Trace(p, args)
+		assert.NoError(t, err)
+		assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+		return strings.Join(strings.Fields(w.String()), " ")
+	}
+	assert.Contains(t, render(), "--cache=/var/lib/app/cache Cache directory, defaults to /var/lib/app/cache.")
+	assert.Contains(t, render("--data-dir=/data"), "--cache=/data/cache Cache directory, defaults to /data/cache.")
+	t.Setenv("KONG_TEST_DATA_DIR", "/env")
+	assert.Contains(t, render(), "--cache=/env/cache Cache directory, defaults to /env/cache.")
+}
+
+func TestHelpHumanReadableDefaults(t *testing.T) {
+	var cli struct {
+		Timeout time.Duration `default:"5400s" help:"Timeout (${default})."`
+		Since   time.Time     `default:"2024-03-01" format:"2006-01-02"`
+		Cache   int64         `default:"1048576" unit:"B"`
+		Poll    int           `default:"5400000" unit:"ms"`
+	}
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Name("test"), kong.Writers(w, w))
+	ctx, err := kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.Contains(t, w.String(), "--timeout=1h30m       Timeout (1h30m).")
+	assert.Contains(t, w.String(), "--since=2024-03-01")
+	assert.Contains(t, w.String(), "--cache=1MiB")
+	assert.Contains(t, w.String(), "--poll=90m")
+
+	w.Reset()
+	p = mustNew(t, &cli, kong.Name("test"), kong.Writers(w, w),
+		kong.TypeFormatter(reflect.TypeOf(time.Duration(0)), func(v reflect.Value) string { return fmt.Sprint(v.Int()) }))
+	ctx, err = kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.Contains(t, w.String(), "--timeout=5400000000000")
+}
+
+func TestHelpVersions(t *testing.T) {
+	var cli struct {
+		Region string `help:"Region to deploy to." since:"1.4"`
+		Legacy bool   `help:"Use the legacy API" since:"0.9" removedin:"2.0"`
+		Target string `arg:"" help:"Target." since:"1.2"`
+	}
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Name("test"), kong.Writers(w, w))
+	ctx, err := kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.NotContains(t, w.String(), "since")
+
+	w.Reset()
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{Versions: true}, ctx))
+	assert.Contains(t, w.String(), "<target>    Target (since 1.2).")
+	assert.Contains(t, w.String(), "--region=STRING    Region to deploy to (since 1.4).")
+	assert.Contains(t, w.String(), "--legacy           Use the legacy API (since 0.9, removed in 2.0)")
+}
+
+func TestHelpPositionalDetails(t *testing.T) {
+	var cli struct {
+		Source string   `arg:"" placeholder:"SRC" help:"Where to copy from.\n\nMay be a URL."`
+		Count  int      `arg:"" default:"3" help:"Number of copies."`
+		Paths  []string `arg:"" type:"path" optional:"" help:"Destinations."`
+	}
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Name("test"), kong.Writers(w, w))
+	ctx, err := kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.Equal(t, `Usage: test <SRC> [<count> [<paths> ...]]
+
+Arguments:
+  <SRC>            Where to copy from.
+
+                   May be a URL.
+  [<count>]        Number of copies (int, default: 3).
+  [<paths> ...]    Destinations (path).
+
+Flags:
+  -h, --help    Show context-sensitive help.
+`, w.String())
+}
+
+func TestIgnoreHelpInUsage(t *testing.T) {
+	var cli struct {
+		One string `required:""`
+	}
+
+	k := mustNew(t, &cli)
+	w := &bytes.Buffer{}
+	k.Stdout = w
+	k.Exit = func(code int) {}
+	_, err := k.Parse([]string{"--help"})
+	assert.Error(t, err)
+	assert.Equal(t, `Usage: test --one=STRING
+
+Flags:
+  -h, --help          Show context-sensitive help.
+      --one=STRING
+`, w.String())
+}
+
+func TestHelpRelations(t *testing.T) {
+	var cli struct {
+		JSON bool `help:"Print JSON." xor:"format"`
+		YAML bool `help:"Print YAML." xor:"format"`
+	}
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(w, w), kong.Exit(func(int) {}), kong.ConfigureHelp(kong.HelpOptions{Relations: true}))
+	_, _ = p.Parse([]string{"--help"})
+	assert.Contains(t, w.String(), "--json    Print JSON (mutually exclusive with --yaml).")
+	assert.Contains(t, w.String(), "--yaml    Print YAML (mutually exclusive with --json).")
+}
+
+func TestTermHelpWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "40")
+	var cli struct {
+		Flag string `help:"A flag with a long help that must wrap at the terminal width."`
+	}
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(w, w), kong.Exit(func(int) {}))
+	_, err := p.Parse([]string{"--help"})
+	assert.NoError(t, err)
+	for _, line := range bytes.Split(w.Bytes(), []byte("\n")) {
+		assert.True(t, len(line) <= 40, string(line))
+	}
+}
+
+func TestAutoNegatableHelp(t *testing.T) {
+	var cli struct {
+		Color bool `default:"true" help:"Colorize output."`
+	}
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.AutoNegatable(), kong.NegationPrefix("without-"), kong.Writers(w, w), kong.Exit(func(int) {}))
+	_, _ = p.Parse([]string{"--help"})
+	assert.Contains(t, w.String(), "--[without-]color    Colorize output.")
+}
+
+func TestDryRunFlagHelp(t *testing.T) {
+	var cli struct {
+		DryRun kong.DryRunFlag
+		Deploy dryRunCmd `cmd:""`
+	}
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(w, w))
+	ctx, err := kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.Contains(t, w.String(), "--dry-run    Show what would be done, without making any changes.")
+}
+
+func TestHelpEnvarProvenance(t *testing.T) {
+	var cli struct {
+		Dir string `env:"NEW_DIR,LEGACY_DIR" help:"Data directory."`
+	}
+	w := &bytes.Buffer{}
+	p := newEnvParser(t, &cli, envMap{"LEGACY_DIR": "/data"}, kong.Writers(w, w))
+	ctx, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.PrintUsage(false))
+	assert.Contains(t, w.String(), "--dir=STRING    Data directory ($NEW_DIR, $LEGACY_DIR; using $LEGACY_DIR).")
+}
+
+func TestIndexedEmbeddedGroupsHelp(t *testing.T) {
+	var cli struct {
+		Listeners []listenerConfig `embed:"" prefix:"listener.{i}." instances:"3"`
+	}
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(w, w))
+	ctx, err := kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.Contains(t, w.String(), "--listener.{0..2}.port=INT\n")
+	assert.Contains(t, w.String(), "--listener.{0..2}.host=\"localhost\"\n")
+	assert.NotContains(t, w.String(), "--listener.1.")
+}
@@ -0,0 +1,39 @@
+package kong
+
+// HelpFlagOptions configures the built-in help flag. See ConfigureHelpFlag.
+type HelpFlagOptions struct {
+	// Name of the flag. Defaults to "help".
+	Name string
+	// Short name of the flag. Defaults to 'h'.
+	Short rune
+	// NoShort removes the short flag.
+	NoShort bool
+	// Help of the flag. Defaults to "Show context-sensitive help.".
+	Help string
+	// RootOnly accepts the flag before any command only, rather than on every command.
+	RootOnly bool
+	// Action replaces printing help, eg. to print a machine-readable description of the application. Kong exits
+	// with a zero status after it returns without error.
+	Action func(ctx *Context) error
+}
+
+// ConfigureHelpFlag renames, removes the short flag of, restricts to the root or changes the behaviour of the
+// built-in help flag. Use NoDefaultHelp() to remove it entirely.
+func ConfigureHelpFlag(options HelpFlagOptions) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.helpFlagOptions = options
+		return nil
+	})
+}
+
+// helpHint returns the command-line that shows help for the command at "path", eg. "app cmd --help".
+func helpHint(app *Application, path string) string {
+	name := "help"
+	if app.HelpFlag != nil {
+		name = app.HelpFlag.Name
+		if app.HelpFlag.Tag.Local {
+			path = app.Name
+		}
+	}
+	return path + " --" + name
+}
@@ -0,0 +1,63 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestConfigureHelpFlag(t *testing.T) {
+	var cli struct {
+		Sub struct {
+			Hidden bool `short:"h"`
+		} `cmd:""`
+	}
+	w := &bytes.Buffer{}
+	exited := false
+	p := mustNew(t, &cli, kong.Writers(w, w), kong.Exit(func(int) { exited = true }), kong.ShortUsageOnError(),
+		kong.ConfigureHelpFlag(kong.HelpFlagOptions{Name: "usage", NoShort: true, Help: "Show usage.", RootOnly: true}))
+
+	_, _ = p.Parse([]string{"--usage"})
+	assert.True(t, exited)
+	assert.Contains(t, w.String(), "--usage    Show usage.")
+	assert.NotContains(t, w.String(), "--help")
+
+	ctx, err := p.Parse([]string{"sub", "-h"})
+	assert.NoError(t, err)
+	assert.Equal(t, "sub", ctx.Command())
+	assert.True(t, cli.Sub.Hidden)
+
+	_, err = p.Parse([]string{"sub", "--usage"})
+	assert.Error(t, err)
+
+	w.Reset()
+	_, err = p.Parse([]string{})
+	assert.Error(t, err)
+	p.FatalIfErrorf(err)
+	assert.Contains(t, w.String(), `Run "test --usage" for more information.`)
+}
+
+func TestConfigureHelpFlagAction(t *testing.T) {
+	var cli struct {
+		Flag string `help:"A flag."`
+	}
+	w := &bytes.Buffer{}
+	exited := false
+	p := mustNew(t, &cli, kong.Writers(w, w), kong.Exit(func(int) { exited = true }),
+		kong.ConfigureHelpFlag(kong.HelpFlagOptions{Action: func(ctx *kong.Context) error {
+			return json.NewEncoder(ctx.Stdout).Encode(map[string]string{"name": ctx.Model.Name})
+		}}))
+
+	_, _ = p.Parse([]string{"-h"})
+	assert.True(t, exited)
+	description := map[string]string{}
+	assert.NoError(t, json.Unmarshal(w.Bytes(), &description))
+	assert.Equal(t, map[string]string{"name": "test"}, description)
+}
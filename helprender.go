@@ -0,0 +1,576 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// annotatedHelp formats the help of "value", annotated with its versions if HelpOptions.Versions is set, and with its
+// relationships to other flags if HelpOptions.Relations is set.
+func (h *helpWriter) annotatedHelp(value *Value) string {
+	help := h.helpFormatter(value)
+	if value.Tag == nil {
+		return help
+	}
+	annotations := []string{}
+	if h.Versions && value.Tag.Since != "" {
+		annotations = append(annotations, "since "+value.Tag.Since)
+	}
+	if h.Versions && value.Tag.RemovedIn != "" {
+		annotations = append(annotations, "removed in "+value.Tag.RemovedIn)
+	}
+	if h.Relations && value.Flag != nil {
+		for _, relation := range value.Flag.Relations {
+			annotations = append(annotations, relation.String())
+		}
+	}
+	if len(annotations) == 0 {
+		return help
+	}
+	return appendHelpSuffix(help, "("+strings.Join(annotations, ", ")+")")
+}
+
+// DefaultShortHelpPrinter is the default HelpPrinter for short help on error.
+func DefaultShortHelpPrinter(options HelpOptions, ctx *Context) error {
+	ctx.interpolateFlagRefs()
+	w := newHelpWriter(ctx, options)
+	cmd := ctx.Selected()
+	app := ctx.Model
+	w.Section("usage")
+	if cmd == nil {
+		w.Printf("Usage: %s%s", app.Name, w.summary(app.Node))
+		w.Printf(`Run "%s" for more information.`, helpHint(app, app.Name))
+	} else {
+		w.Printf("Usage: %s %s", app.Name, w.summary(cmd))
+		w.Printf(`Run "%s" for more information.`, helpHint(app, cmd.FullPath()))
+	}
+	return w.Flush()
+}
+
+// DefaultHelpPrinter is the default HelpPrinter.
+func DefaultHelpPrinter(options HelpOptions, ctx *Context) error {
+	ctx.interpolateFlagRefs()
+	if ctx.Empty() {
+		options.Summary = false
+	}
+	w := newHelpWriter(ctx, options)
+	selected := ctx.Selected()
+	if selected == nil {
+		printApp(w, ctx.Model)
+	} else {
+		printCommand(w, ctx.Model, selected)
+	}
+	return w.Flush()
+}
+
+func printApp(w *helpWriter, app *Application) {
+	if !w.NoAppSummary {
+		w.Section("usage")
+		w.Printf("Usage: %s%s", app.Name, w.summary(app.Node))
+	}
+	printNodeDetail(w, app.Node, true)
+	cmds := app.Leaves(true)
+	if len(cmds) > 0 && app.HelpFlag != nil {
+		w.Section("footer")
+		w.Print("")
+		if w.Summary {
+			w.Printf(`Run "%s" for more information.`, helpHint(app, app.Name))
+		} else if !app.HelpFlag.Tag.Local {
+			w.Printf(`Run "%s" for more information on a command.`, helpHint(app, app.Name+" <command>"))
+		}
+	}
+	if !w.Summary {
+		printMetadata(w, app.Metadata)
+	}
+}
+
+func printMetadata(w *helpWriter, meta Metadata) {
+	rows := [][2]string{}
+	for _, row := range [][2]string{
+		{"Homepage", meta.Homepage},
+		{"Support", meta.SupportURL},
+		{"Author", meta.Author},
+		{"License", meta.License},
+	} {
+		if row[1] != "" {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	w.Section("metadata")
+	w.Print("")
+	for _, row := range rows {
+		w.Printf("%s: %s", row[0], row[1])
+	}
+}
+
+func printCommand(w *helpWriter, app *Application, cmd *Command) {
+	if !w.NoAppSummary {
+		w.Section("usage")
+		w.Printf("Usage: %s %s", app.Name, w.summary(cmd))
+	}
+	printNodeDetail(w, cmd, true)
+	if w.Summary && app.HelpFlag != nil {
+		w.Section("footer")
+		w.Print("")
+		w.Printf(`Run "%s" for more information.`, helpHint(app, cmd.FullPath()))
+	}
+}
+
+func printNodeDetail(w *helpWriter, node *Node, hide bool) {
+	if node.Help != "" {
+		w.Section("help")
+		w.Print("")
+		w.Wrap(node.Help)
+	}
+	if w.Summary {
+		return
+	}
+	if node.Detail != "" {
+		w.Section("detail")
+		w.Print("")
+		w.Wrap(node.Detail)
+	}
+	if len(node.Positional) > 0 {
+		w.Section("arguments")
+		w.Print("")
+		w.Print("Arguments:")
+		writePositionals(w.Indent(), node.Positional)
+	}
+	printFlags := func() {
+		if flags := node.AllFlags(true); len(flags) > 0 {
+			groupedFlags := collectFlagGroups(flags)
+			for _, group := range groupedFlags {
+				w.Section("flags")
+				w.Print("")
+				if group.Metadata.Title != "" {
+					w.Wrap(group.Metadata.Title)
+				}
+				if group.Metadata.Description != "" {
+					w.Indent().Wrap(group.Metadata.Description)
+					w.Print("")
+				}
+				writeFlags(w.Indent(), group.Flags)
+			}
+		}
+	}
+	if !w.FlagsLast {
+		printFlags()
+	}
+	var cmds []*Node
+	if w.NoExpandSubcommands {
+		cmds = node.Children
+	} else {
+		cmds = node.Leaves(hide)
+	}
+	if len(cmds) > 0 {
+		iw := w.Indent()
+		if w.Tree {
+			w.Section("commands")
+			w.Print("")
+			w.Print("Commands:")
+			writeCommandTree(iw, node)
+		} else {
+			groupedCmds := collectCommandGroups(cmds)
+			for _, group := range groupedCmds {
+				w.Section("commands")
+				w.Print("")
+				if group.Metadata.Title != "" {
+					w.Wrap(group.Metadata.Title)
+				}
+				if group.Metadata.Description != "" {
+					w.Indent().Wrap(group.Metadata.Description)
+					w.Print("")
+				}
+
+				if w.Compact {
+					writeCompactCommandList(group.Commands, iw)
+				} else {
+					writeCommandList(group.Commands, iw)
+				}
+			}
+		}
+	}
+	if w.FlagsLast {
+		printFlags()
+	}
+}
+
+func writeCommandList(cmds []*Node, iw *helpWriter) {
+	for i, cmd := range cmds {
+		if cmd.Hidden {
+			continue
+		}
+		printCommandSummary(iw, cmd)
+		if i != len(cmds)-1 {
+			iw.Print("")
+		}
+	}
+}
+
+func writeCompactCommandList(cmds []*Node, iw *helpWriter) {
+	rows := [][2]string{}
+	for _, cmd := range cmds {
+		if cmd.Hidden {
+			continue
+		}
+		rows = append(rows, [2]string{cmd.Path(), cmd.Help})
+	}
+	writeTwoColumns(iw, rows)
+}
+
+func writeCommandTree(w *helpWriter, node *Node) {
+	rows := make([][2]string, 0, len(node.Children)*2)
+	for i, cmd := range node.Children {
+		if cmd.Hidden {
+			continue
+		}
+		rows = append(rows, w.CommandTree(cmd, "")...)
+		if i != len(node.Children)-1 {
+			rows = append(rows, [2]string{"", ""})
+		}
+	}
+	writeTwoColumns(w, rows)
+}
+
+type helpFlagGroup struct {
+	Metadata *Group
+	Flags    [][]*Flag
+}
+
+func collectFlagGroups(flags [][]*Flag) []helpFlagGroup {
+	// Group keys in order of appearance.
+	groups := []*Group{}
+	// Flags grouped by their group key.
+	flagsByGroup := map[string][][]*Flag{}
+
+	for _, levelFlags := range flags {
+		levelFlagsByGroup := map[string][]*Flag{}
+
+		for _, flag := range levelFlags {
+			key := ""
+			if flag.Group != nil {
+				key = flag.Group.Key
+				groupAlreadySeen := false
+				for _, group := range groups {
+					if key == group.Key {
+						groupAlreadySeen = true
+						break
+					}
+				}
+				if !groupAlreadySeen {
+					groups = append(groups, flag.Group)
+				}
+			}
+
+			levelFlagsByGroup[key] = append(levelFlagsByGroup[key], flag)
+		}
+
+		for key, flags := range levelFlagsByGroup {
+			flagsByGroup[key] = append(flagsByGroup[key], flags)
+		}
+	}
+
+	out := []helpFlagGroup{}
+	// Ungrouped flags are always displayed first.
+	if ungroupedFlags, ok := flagsByGroup[""]; ok {
+		out = append(out, helpFlagGroup{
+			Metadata: &Group{Title: "Flags:"},
+			Flags:    ungroupedFlags,
+		})
+	}
+	for _, group := range groups {
+		out = append(out, helpFlagGroup{Metadata: group, Flags: flagsByGroup[group.Key]})
+	}
+	return out
+}
+
+type helpCommandGroup struct {
+	Metadata *Group
+	Commands []*Node
+}
+
+func collectCommandGroups(nodes []*Node) []helpCommandGroup {
+	// Groups in order of appearance.
+	groups := []*Group{}
+	// Nodes grouped by their group key.
+	nodesByGroup := map[string][]*Node{}
+
+	for _, node := range nodes {
+		key := ""
+		if group := node.ClosestGroup(); group != nil {
+			key = group.Key
+			if _, ok := nodesByGroup[key]; !ok {
+				groups = append(groups, group)
+			}
+		}
+		nodesByGroup[key] = append(nodesByGroup[key], node)
+	}
+
+	out := []helpCommandGroup{}
+	// Ungrouped nodes are always displayed first.
+	if ungroupedNodes, ok := nodesByGroup[""]; ok {
+		out = append(out, helpCommandGroup{
+			Metadata: &Group{Title: "Commands:"},
+			Commands: ungroupedNodes,
+		})
+	}
+	for _, group := range groups {
+		out = append(out, helpCommandGroup{Metadata: group, Commands: nodesByGroup[group.Key]})
+	}
+	return out
+}
+
+func printCommandSummary(w *helpWriter, cmd *Command) {
+	w.Print(w.summary(cmd))
+	if cmd.Help != "" {
+		w.Indent().Wrap(cmd.Help)
+	}
+}
+
+// Placeholder lists longer than this are elided in summarised usage.
+const maxUsageListItems = 3
+
+var usageListRe = regexp.MustCompile(`([<{(\[])([^<>{}()\[\]]+)([>})\]])`)
+
+// summary returns the usage summary for "node", shortened to fit UsageBudget.
+func (h *helpWriter) summary(node *Node) string {
+	summary := node.Summary()
+	if h.UsageBudget <= 0 || utf8.RuneCountInString(summary) <= h.UsageBudget {
+		return summary
+	}
+	required := []string{}
+	for _, group := range node.AllFlags(true) {
+		for _, flag := range group {
+			if flag.Required {
+				required = append(required, elideUsageLists(flag.Summary()))
+			}
+		}
+	}
+	summary = node.summary(strings.Join(required, " "))
+	if utf8.RuneCountInString(summary) > h.UsageBudget && len(required) > 0 {
+		summary = node.summary(fmt.Sprintf("<%d required flags>", len(required)))
+	}
+	if runes := []rune(summary); len(runes) > h.UsageBudget {
+		summary = string(runes[:h.UsageBudget-1]) + "…"
+	}
+	return summary
+}
+
+// elideUsageLists shortens bracketed lists of alternatives, eg. "<a|b|c|d>" to "<a|b|…>".
+func elideUsageLists(s string) string {
+	return usageListRe.ReplaceAllStringFunc(s, func(match string) string {
+		groups := usageListRe.FindStringSubmatch(match)
+		sep := "|"
+		if !strings.Contains(groups[2], sep) {
+			sep = ","
+		}
+		items := strings.Split(groups[2], sep)
+		if len(items) <= maxUsageListItems {
+			return match
+		}
+		return groups[1] + strings.Join(items[:maxUsageListItems-1], sep) + sep + "…" + groups[3]
+	})
+}
+
+type helpWriter struct {
+	indent        string
+	width         int
+	out           *helpOutput
+	helpFormatter HelpValueFormatter
+	HelpOptions
+}
+
+func newHelpWriter(ctx *Context, options HelpOptions) *helpWriter {
+	newSink := ctx.Kong.helpSink
+	if newSink == nil {
+		newSink = NewHelpSink
+	}
+	wrapWidth := ctx.Term().Width
+	if options.WrapUpperBound > 0 && wrapWidth > options.WrapUpperBound {
+		wrapWidth = options.WrapUpperBound
+	}
+	w := &helpWriter{
+		indent:        "",
+		width:         wrapWidth,
+		out:           &helpOutput{sink: newSink(ctx.Stdout)},
+		helpFormatter: ctx.Kong.helpFormatter,
+		HelpOptions:   options,
+	}
+	return w
+}
+
+func (h *helpWriter) Printf(format string, args ...any) {
+	h.Print(fmt.Sprintf(format, args...))
+}
+
+func (h *helpWriter) Print(text string) {
+	if h.out.err == nil {
+		h.out.err = h.out.sink.WriteLine(strings.TrimRight(h.indent+text, " "))
+	}
+}
+
+// Section starts a new section of help, see HelpSink.
+func (h *helpWriter) Section(name string) {
+	if h.out.err == nil {
+		h.out.err = h.out.sink.Section(name)
+	}
+}
+
+// Indent returns a new helpWriter indented by two characters.
+func (h *helpWriter) Indent() *helpWriter {
+	return &helpWriter{indent: h.indent + "  ", out: h.out, width: h.width - 2, HelpOptions: h.HelpOptions, helpFormatter: h.helpFormatter}
+}
+
+// Flush flushes the sink and returns the first error encountered while writing help, if any.
+func (h *helpWriter) Flush() error {
+	if h.out.err != nil {
+		return h.out.err
+	}
+	return h.out.sink.Flush()
+}
+
+func (h *helpWriter) Wrap(text string) {
+	w := bytes.NewBuffer(nil)
+	doc.ToText(w, strings.TrimSpace(text), "", "    ", h.width) //nolint:staticcheck // cross-package links not possible
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		h.Print(line)
+	}
+}
+
+func writePositionals(w *helpWriter, args []*Positional) {
+	rows := [][2]string{}
+	for _, arg := range args {
+		help := w.annotatedHelp(arg)
+		if details := positionalDetails(arg); details != "" {
+			help = appendHelpSuffix(help, "("+details+")")
+		}
+		rows = append(rows, [2]string{arg.Summary(), help})
+	}
+	writeTwoColumns(w, rows)
+}
+
+// positionalDetails describes the type and default of a positional argument, eg. "int, default: 3". Strings
+// without a default, and defaults already interpolated into the help, are omitted.
+func positionalDetails(arg *Positional) string {
+	details := []string{}
+	typ := arg.Tag.Type
+	if typ == "" {
+		t := arg.Target.Type()
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if typ = t.Name(); typ == "" {
+			typ = t.Kind().String()
+		}
+		typ = strings.ToLower(dashedString(typ))
+	}
+	if typ != "string" {
+		details = append(details, typ)
+	}
+	if arg.HasDefault && !HasInterpolatedVar(arg.OrigHelp, "default") {
+		details = append(details, "default: "+arg.FormattedDefault())
+	}
+	return strings.Join(details, ", ")
+}
+
+func writeFlags(w *helpWriter, groups [][]*Flag) {
+	rows := [][2]string{}
+	haveShort := false
+	for _, group := range groups {
+		for _, flag := range group {
+			if flag.Short != 0 {
+				haveShort = true
+				break
+			}
+		}
+	}
+	for i, group := range groups {
+		if i > 0 {
+			rows = append(rows, [2]string{"", ""})
+		}
+		for _, flag := range group {
+			// Flags of embedded slices of structs are listed once, for the first instance.
+			if !flag.Hidden && (flag.Tag.indexed == nil || flag.Tag.instance == 0) {
+				rows = append(rows, [2]string{formatFlag(haveShort, flag), w.annotatedHelp(flag.Value)})
+			}
+		}
+	}
+	writeTwoColumns(w, rows)
+}
+
+func writeTwoColumns(w *helpWriter, rows [][2]string) {
+	maxLeft := 375 * w.width / 1000
+	if maxLeft < 30 {
+		maxLeft = 30
+	}
+	// Find size of first column.
+	leftSize := 0
+	for _, row := range rows {
+		if c := len(row[0]); c > leftSize && c < maxLeft {
+			leftSize = c
+		}
+	}
+
+	offsetStr := strings.Repeat(" ", leftSize+defaultColumnPadding)
+
+	for _, row := range rows {
+		buf := bytes.NewBuffer(nil)
+		doc.ToText(buf, row[1], "", strings.Repeat(" ", defaultIndent), w.width-leftSize-defaultColumnPadding) //nolint:staticcheck // cross-package links not possible
+		lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+
+		line := fmt.Sprintf("%-*s", leftSize, row[0])
+		if len(row[0]) < maxLeft {
+			line += fmt.Sprintf("%*s%s", defaultColumnPadding, "", lines[0])
+			lines = lines[1:]
+		}
+		w.Print(line)
+		for _, line := range lines {
+			w.Printf("%s%s", offsetStr, line)
+		}
+	}
+}
+
+// haveShort will be true if there are short flags present at all in the help. Useful for column alignment.
+func formatFlag(haveShort bool, flag *Flag) string {
+	flagString := ""
+	name := flag.Name
+	isBool := flag.IsBool()
+	isCounter := flag.IsCounter()
+
+	short := ""
+	if flag.Short != 0 {
+		short = "-" + string(flag.Short) + ", "
+	} else if haveShort {
+		short = "    "
+	}
+
+	if isBool && flag.Tag.Negatable == negatableDefault {
+		name = "[no-]" + name
+	} else if prefix := strings.TrimSuffix(flag.Tag.Negatable, name); isBool && prefix != flag.Tag.Negatable && prefix != "" {
+		// Negation is of the form <prefix><flag>, eg. from NegationPrefix().
+		name = "[" + prefix + "]" + name
+	} else if isBool && flag.Tag.Negatable != "" {
+		name += "/" + flag.Tag.Negatable
+	}
+	if flag.Tag.indexed != nil {
+		name = strings.Replace(name, flag.Name, flag.Tag.indexed.helpName(flag.Name), 1)
+	}
+
+	flagString += fmt.Sprintf("%s--%s", short, name)
+
+	if !isBool && !isCounter {
+		flagString += fmt.Sprintf("=%s", flag.FormatPlaceHolder())
+	}
+	return flagString
+}
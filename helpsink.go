@@ -0,0 +1,55 @@
+package kong
+
+import (
+	"bufio"
+	"io"
+)
+
+// HelpSink receives help as it is rendered, one line at a time, so that large help can be streamed rather than built
+// up in memory, and so that custom sinks can act on each section. See HelpOutput.
+type HelpSink interface {
+	// Section is called before the first line of each section of help, eg. "usage", "help", "detail", "arguments",
+	// "flags", "commands", "footer" or "metadata". Sections such as "flags" may occur more than once.
+	Section(name string) error
+	// WriteLine writes a single line of help, without a trailing newline.
+	WriteLine(line string) error
+	// Flush is called once all help has been written.
+	Flush() error
+}
+
+// HelpOutput configures the HelpSink that help printed to "w", normally Kong's stdout, is written through.
+//
+// Defaults to NewHelpSink.
+func HelpOutput(sink func(w io.Writer) HelpSink) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.helpSink = sink
+		return nil
+	})
+}
+
+// NewHelpSink returns a HelpSink that writes buffered, newline-terminated lines to "w" and ignores sections.
+func NewHelpSink(w io.Writer) HelpSink {
+	return &writerHelpSink{w: bufio.NewWriter(w)}
+}
+
+type writerHelpSink struct {
+	w *bufio.Writer
+}
+
+func (s *writerHelpSink) Section(name string) error { return nil }
+
+func (s *writerHelpSink) WriteLine(line string) error {
+	if _, err := s.w.WriteString(line); err != nil {
+		return err
+	}
+	return s.w.WriteByte('\n')
+}
+
+func (s *writerHelpSink) Flush() error { return s.w.Flush() }
+
+// helpOutput holds the sink help is written to and the first error it returned. It is shared by a helpWriter and
+// its indented copies.
+type helpOutput struct {
+	sink HelpSink
+	err  error
+}
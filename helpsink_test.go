@@ -0,0 +1,93 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type recordingHelpSink struct {
+	w       io.Writer
+	flushed bool
+}
+
+func (r *recordingHelpSink) Section(name string) error {
+	_, err := fmt.Fprintf(r.w, "[%s]\n", name)
+	return err
+}
+
+func (r *recordingHelpSink) WriteLine(line string) error {
+	if strings.Contains(line, "--broken") {
+		return errors.New("broken pipe")
+	}
+	_, err := fmt.Fprintln(r.w, line)
+	return err
+}
+
+func (r *recordingHelpSink) Flush() error {
+	r.flushed = true
+	return nil
+}
+
+func TestHelpOutput(t *testing.T) {
+	var cli struct {
+		Flag string `help:"A flag."`
+		Cmd  struct {
+			Arg string `arg:"" help:"An argument."`
+		} `cmd:"" help:"A command."`
+	}
+	w := &bytes.Buffer{}
+	sink := &recordingHelpSink{}
+	p := mustNew(t, &cli, kong.Writers(w, w), kong.Exit(func(int) {}),
+		kong.HelpOutput(func(w io.Writer) kong.HelpSink {
+			sink.w = w
+			return sink
+		}))
+
+	_, _ = p.Parse([]string{"cmd", "--help"})
+	assert.True(t, sink.flushed)
+	assert.Equal(t, `[usage]
+Usage: test cmd <arg> [flags]
+[help]
+
+A command.
+[arguments]
+
+Arguments:
+  <arg>    An argument.
+[flags]
+
+Flags:
+  -h, --help           Show context-sensitive help.
+      --flag=STRING    A flag.
+`, w.String())
+}
+
+func TestHelpOutputError(t *testing.T) {
+	var cli struct {
+		Broken bool
+		After  bool
+	}
+	w := &bytes.Buffer{}
+	sink := &recordingHelpSink{}
+	p := mustNew(t, &cli, kong.Writers(w, w), kong.Exit(func(int) {}),
+		kong.HelpOutput(func(w io.Writer) kong.HelpSink {
+			sink.w = w
+			return sink
+		}))
+
+	_, err := p.Parse([]string{"--help"})
+	assert.EqualError(t, err, "broken pipe")
+	assert.False(t, sink.flushed)
+	assert.NotContains(t, w.String(), "--after")
+}
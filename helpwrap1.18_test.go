@@ -1,5 +1,5 @@
-//go:build !go1.19
-// +build !go1.19
+//go:build !go1.19 && !kong_minimal
+// +build !go1.19,!kong_minimal
 
 package kong_test
 
@@ -1,8 +1,8 @@
 // Wrapping of text changed in Go1.19 per https://github.com/alecthomas/kong/issues/325
 // The test has been split pre-go1.19 and go1.19 and onwards.
 
-//go:build go1.19
-// +build go1.19
+//go:build go1.19 && !kong_minimal
+// +build go1.19,!kong_minimal
 
 package kong_test
 
@@ -24,9 +24,17 @@ type AfterApply interface {
 	AfterApply(args ...any) error
 }
 
+// HookPrioritizer can be implemented by nodes in the grammar to control when their hooks run relative to others.
+//
+// See WithHookPriority for the order in which hooks run.
+type HookPrioritizer interface {
+	// HookPriority of the node's hooks. Hooks with lower priorities run first. The default is 0.
+	HookPriority() int
+}
+
 // AfterRun is a documentation-only interface describing hooks that run after Run() returns.
 type AfterRun interface {
 	// This is not the correct signature - see README for details.
-	// AfterRun is called after Run() returns.
+	// AfterRun is called after Run() returns or panics. The error returned by Run(), if any, is bound as "error".
 	AfterRun(args ...any) error
 }
@@ -0,0 +1,60 @@
+package kong
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// indexedGroup is an embedded slice of structs, eg.
+//
+//	Listeners []Listener `embed:"" prefix:"listener.{i}." instances:"4"`
+//
+// Flags are built for each of a fixed number of instances in "backing", and after a command-line is applied the
+// field is truncated to the instances up to the last one given a value. The tags of the fields of each instance
+// refer back to the group and hold the index of the instance.
+type indexedGroup struct {
+	field   reflect.Value
+	backing reflect.Value
+	prefix  string // Flag prefix, with "{i}" in place of the index.
+}
+
+// helpName returns the name of the flag "name" of the first instance as shown in help, which lists it once for all
+// instances, eg. "listener.{0..3}.port" for "listener.0.port".
+func (g *indexedGroup) helpName(name string) string {
+	first := strings.ReplaceAll(g.prefix, "{i}", "0")
+	if !strings.HasPrefix(name, first) {
+		return name
+	}
+	indexes := fmt.Sprintf("{0..%d}", g.backing.Len()-1)
+	return strings.ReplaceAll(g.prefix, "{i}", indexes) + strings.TrimPrefix(name, first)
+}
+
+// applyIndexedGroups truncates each embedded slice of structs to the instances given values on the command-line, by
+// resolvers or by envars.
+func (c *Context) applyIndexedGroups() {
+	if len(c.Kong.indexedGroups) == 0 {
+		return
+	}
+	set := []*Flag{}
+	for _, path := range c.Path {
+		if path.Flag != nil {
+			set = append(set, path.Flag)
+		}
+	}
+	_ = Visit(c.Model, func(node Visitable, next Next) error {
+		if flag, ok := node.(*Flag); ok && flag.EnvVar != "" {
+			set = append(set, flag)
+		}
+		return next(nil)
+	})
+	used := map[*indexedGroup]int{}
+	for _, flag := range set {
+		if group := flag.Tag.indexed; group != nil && flag.Tag.instance >= used[group] {
+			used[group] = flag.Tag.instance + 1
+		}
+	}
+	for _, group := range c.Kong.indexedGroups {
+		group.field.Set(group.backing.Slice(0, used[group]))
+	}
+}
@@ -0,0 +1,102 @@
+package kong
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+)
+
+// InterruptPolicy configures how Context.Run handles interrupt signals. See Interrupts.
+type InterruptPolicy struct {
+	// Signals to handle. Defaults to os.Interrupt and syscall.SIGTERM.
+	Signals []os.Signal
+	// OnInterrupt is called when the first signal is received, after the bound context.Context has been cancelled.
+	// Defaults to printing "interrupt received, finishing up..." to Kong.Stderr.
+	OnInterrupt func(sig os.Signal)
+	// OnForceExit is called when a second signal is received, before exiting.
+	OnForceExit func(sig os.Signal)
+	// ForceExitCode is the status to exit with on a second signal. Defaults to the status for ExitInterrupted.
+	ForceExitCode int
+}
+
+// Interrupts binds a context.Context to Run() methods and hooks for the duration of Context.Run, which is cancelled
+// by the first interrupt signal received. A second signal exits immediately.
+//
+// If a context.Context is already bound, the bound context is derived from it.
+func Interrupts(policy InterruptPolicy) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.interrupts = &policy
+		return nil
+	})
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// watchInterrupts binds a context.Context that is cancelled by the first interrupt signal, until "stop" is called.
+func (c *Context) watchInterrupts() (stop func()) {
+	policy := c.Kong.interrupts
+	parent := context.Background()
+	previous, bound := c.bindings[contextType]
+	for _, b := range []*binding{previous, c.Kong.bindings[contextType]} {
+		if b == nil {
+			continue
+		}
+		if v, ok := b.Get(); ok {
+			if ctx, ok := v.Interface().(context.Context); ok && ctx != nil {
+				parent = ctx
+				break
+			}
+		}
+	}
+	ctx, cancel := context.WithCancel(parent)
+	c.BindTo(ctx, (*context.Context)(nil))
+
+	signals := policy.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	received := make(chan os.Signal, 2)
+	signal.Notify(received, signals...)
+	done := make(chan struct{})
+	go func() {
+		interrupted := false
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-received:
+				if !interrupted {
+					interrupted = true
+					cancel()
+					if policy.OnInterrupt != nil {
+						policy.OnInterrupt(sig)
+					} else {
+						formatMultilineMessage(c.Kong.Stderr, []string{c.Model.Name}, "interrupt received, finishing up...")
+					}
+					continue
+				}
+				if policy.OnForceExit != nil {
+					policy.OnForceExit(sig)
+				}
+				code := policy.ForceExitCode
+				if code == 0 {
+					code = c.Kong.exitCodeFor(ExitInterrupted)
+				}
+				c.Kong.Exit(code)
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(received)
+		close(done)
+		cancel()
+		if bound {
+			c.bindings[contextType] = previous
+		} else {
+			delete(c.bindings, contextType)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+//go:build !windows
+// +build !windows
+
+package kong_test
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type interruptCmd struct {
+	exited chan int
+}
+
+func (c *interruptCmd) Run(ctx context.Context) error {
+	_ = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		return nil
+	}
+	_ = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	select {
+	case <-c.exited:
+	case <-time.After(5 * time.Second):
+		return nil
+	}
+	return ctx.Err()
+}
+
+func TestInterrupts(t *testing.T) {
+	var cli struct {
+		Serve interruptCmd `cmd:""`
+	}
+	cli.Serve.exited = make(chan int, 1)
+	var code int
+	var interrupted os.Signal
+	p := mustNew(t, &cli,
+		kong.Exit(func(status int) {
+			code = status
+			cli.Serve.exited <- status
+		}),
+		kong.Interrupts(kong.InterruptPolicy{
+			Signals:       []os.Signal{syscall.SIGUSR1},
+			OnInterrupt:   func(sig os.Signal) { interrupted = sig },
+			ForceExitCode: 3,
+		}))
+	ctx, err := p.Parse([]string{"serve"})
+	assert.NoError(t, err)
+	err = ctx.Run()
+	assert.IsError(t, err, context.Canceled)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, os.Signal(syscall.SIGUSR1), interrupted)
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -56,24 +57,76 @@ type Kong struct {
 	registry     *Registry
 	ignoreFields []*regexp.Regexp
 
-	noDefaultHelp   bool
-	allowHyphenated bool
-	usageOnError    usageOnError
-	help            HelpPrinter
-	shortHelp       HelpPrinter
-	helpFormatter   HelpValueFormatter
-	helpOptions     HelpOptions
-	helpFlag        *Flag
-	groups          []Group
-	vars            Vars
-	flagNamer       func(string) string
+	noDefaultHelp    bool
+	allowHyphenated  bool
+	runMethods       []string // Names of entry point methods, in order of preference.
+	negativeNumbers  NegativeNumberPolicy
+	usageOnError     usageOnError
+	help             HelpPrinter
+	shortHelp        HelpPrinter
+	helpFormatter    HelpValueFormatter
+	helpSink         func(w io.Writer) HelpSink
+	helpOptions      HelpOptions
+	helpFlag         *Flag
+	groups           []Group
+	vars             Vars
+	lazyVars         map[string]func() string
+	lazyValues       Vars // Memoised results of lazyVars.
+	lazyHelp         bool // Help references lazyVars that have not been computed yet. See expandLazyHelp.
+	flagNamer        func(string) string
+	recordPath       string
+	aliases          map[string][]string // Command-line aliases, see CommandLineAliases.
+	passwordPrompt   func(prompt string) (string, error)
+	enumProviders    map[string]*enumProvider
+	negationPrefix   string
+	autoNegatable    bool
+	expandPaths      bool
+	errorDiagnostics bool
+	colorDiagnostics bool
+	usageTelemetry   func(UsageSummary)
+	shellCompletion  bool
+	placeHolderStyle PlaceHolderStyle
+	typeFormatters   map[reflect.Type]func(reflect.Value) string
+	typeTags         map[reflect.Type]map[string][]string // Registered with TypeTags.
+	requireEquals    bool
+	slashFlags       bool
+	hasRest          bool            // Set if any node has a "rest" field.
+	indexedGroups    []*indexedGroup // Embedded slices of structs.
+	messages         catalog         // Overridden with Messages.
+	exitCodes        map[ExitCategory]int
+	unusedConfig     UnusedConfigPolicy
+	unusedEnvars     UnusedConfigPolicy
+	unusedEnvPrefix  string
+	decimalComma     bool // Set by Locale.
+	auditLog         func(AuditRecord)
+	interrupts       *InterruptPolicy
+	onRetry          func(RetryEvent)
+	debugModelFlag   bool
+	noInputFlag      bool
+	preprocessArgs   []func([]string) ([]string, error)
+	commandNamer     func(string) string
+	commandMatcher   func(string) string
+	helpFlagOptions  HelpFlagOptions
+	applets          map[string]any // Grammars selected by binary name, see Applets.
+	collisionPolicy  func(Collision) CollisionPolicy
+	groupMissing     bool            // Set by GroupMissingFlags.
+	builtinKeys      map[string]bool // Keys of the flags added by Kong, eg. "--help".
+	shadowedBuiltins map[string]bool // Keys of built-in flags shadowed by application flags.
 
 	// Set temporarily by Options. These are applied after build().
 	postBuildOptions []Option
 	embedded         []embedded
 	dynamicCommands  []*dynamicCommand
 
-	hooks map[string][]reflect.Value
+	hooks         map[string][]hook
+	flagCallbacks map[*Flag][]func(ctx *Context, value string) error
+	hookPriority  int // Priority of hooks registered by options, set by WithHookPriority.
+}
+
+// A hook registered with an option such as WithAfterApply.
+type hook struct {
+	fn       reflect.Value
+	priority int
 }
 
 // New creates a new Kong parser on grammar.
@@ -86,10 +139,12 @@ func New(grammar any, options ...Option) (*Kong, error) {
 		Stderr:        os.Stderr,
 		registry:      NewRegistry().RegisterDefaults(),
 		vars:          Vars{},
+		messages:      catalog{},
 		bindings:      bindings{},
-		hooks:         make(map[string][]reflect.Value),
+		hooks:         make(map[string][]hook),
 		helpFormatter: DefaultHelpValueFormatter,
 		ignoreFields:  make([]*regexp.Regexp, 0),
+		runMethods:    []string{"Run"},
 		flagNamer: func(s string) string {
 			return strings.ToLower(dashedString(s))
 		},
@@ -111,7 +166,7 @@ func New(grammar any, options ...Option) (*Kong, error) {
 		k.shortHelp = DefaultShortHelpPrinter
 	}
 
-	model, err := build(k, grammar)
+	model, err := build(k, k.appletGrammar(grammar))
 	if err != nil {
 		return k, err
 	}
@@ -158,6 +213,17 @@ func New(grammar any, options ...Option) (*Kong, error) {
 		}
 	}
 
+	if err = k.applyVisibility(k.Model.Node, k.vars); err != nil {
+		return nil, err
+	}
+	if err = k.checkCommandMatches(k.Model.Node); err != nil {
+		return nil, err
+	}
+	annotateRelations(k.Model.Node)
+	k.addHelpAllFlag()
+	k.addDebugModelFlag()
+	k.addNoInputFlag()
+
 	for _, option := range k.postBuildOptions {
 		if err = option.Apply(k); err != nil {
 			return nil, err
@@ -168,6 +234,17 @@ func New(grammar any, options ...Option) (*Kong, error) {
 	if err = k.interpolate(k.Model.Node); err != nil {
 		return nil, err
 	}
+	if k.lazyHelp {
+		help, shortHelp := k.help, k.shortHelp
+		k.help = func(options HelpOptions, ctx *Context) error {
+			k.expandLazyHelp()
+			return help(options, ctx)
+		}
+		k.shortHelp = func(options HelpOptions, ctx *Context) error {
+			k.expandLazyHelp()
+			return shortHelp(options, ctx)
+		}
+	}
 
 	k.bindings.add(k.vars)
 
@@ -226,7 +303,7 @@ func (k *Kong) interpolate(node *Node) (err error) {
 		switch node := node.(type) {
 		case *Node:
 			vars := stack.push(node.Vars())
-			node.Help, err = interpolate(node.Help, vars, nil)
+			node.Help, err = interpolate(node.Help, k.deferLazyVars(vars, node.Help), nil)
 			if err != nil {
 				return fmt.Errorf("help for %s: %s", node.Path(), err)
 			}
@@ -242,12 +319,19 @@ func (k *Kong) interpolate(node *Node) (err error) {
 }
 
 func (k *Kong) interpolateValue(value *Value, vars Vars) (err error) {
+	value.messages = k.messages
 	if len(value.Tag.Vars) > 0 {
 		vars = vars.CloneWith(value.Tag.Vars)
 	}
 	if varsContributor, ok := value.Mapper.(VarsContributor); ok {
 		vars = vars.CloneWith(varsContributor.Vars(value))
 	}
+	referenced := []string{value.Enum, value.Default}
+	if value.Flag != nil {
+		referenced = append(referenced, value.Flag.PlaceHolder)
+		referenced = append(referenced, value.Flag.Envs...)
+	}
+	vars = k.withLazyVars(vars, referenced...)
 
 	if value.Enum, err = interpolate(value.Enum, vars, nil); err != nil {
 		return fmt.Errorf("enum for %s: %s", value.Summary(), err)
@@ -260,9 +344,12 @@ func (k *Kong) interpolateValue(value *Value, vars Vars) (err error) {
 		return fmt.Errorf("enum value for %s: %s", value.Summary(), err)
 	}
 	updatedVars := map[string]string{
-		"default": value.Default,
+		"default": value.FormattedDefault(),
 		"enum":    value.Enum,
 	}
+	if strings.Contains(value.Enum, "|") {
+		updatedVars["enum"] = strings.Join(value.EnumSlice(), ",")
+	}
 	if value.Flag != nil {
 		for i, env := range value.Flag.Envs {
 			if value.Flag.Envs[i], err = interpolate(env, vars, updatedVars); err != nil {
@@ -280,28 +367,138 @@ func (k *Kong) interpolateValue(value *Value, vars Vars) (err error) {
 			return fmt.Errorf("placeholder value for %s: %s", value.Summary(), err)
 		}
 	}
-	value.Help, err = interpolate(value.Help, vars, updatedVars)
+	value.Help, err = interpolate(value.Help, k.deferLazyVars(vars, value.Help), updatedVars)
 	if err != nil {
 		return fmt.Errorf("help for %s: %s", value.Summary(), err)
 	}
+	recordFlagRefs(value)
 	return nil
 }
 
+// withLazyVars adds VarsFunc variables referenced in "strs" that are not already in "vars".
+func (k *Kong) withLazyVars(vars Vars, strs ...string) Vars {
+	if len(k.lazyVars) == 0 {
+		return vars
+	}
+	var lazy Vars
+	for _, s := range strs {
+		for _, match := range interpolationRegex.FindAllStringSubmatch(s, -1) {
+			name := match[3]
+			fn, ok := k.lazyVars[name]
+			if _, set := vars[name]; !ok || set {
+				continue
+			}
+			value, ok := k.lazyValues[name]
+			if !ok {
+				value = fn()
+				k.lazyValues[name] = value
+			}
+			if lazy == nil {
+				lazy = Vars{}
+			}
+			lazy[name] = value
+		}
+	}
+	if lazy == nil {
+		return vars
+	}
+	return vars.CloneWith(lazy)
+}
+
+// deferLazyVars adds VarsFunc variables referenced in "help" that are not already in "vars" or computed, with
+// themselves as their value, so that they are only computed if help is printed. See expandLazyHelp.
+func (k *Kong) deferLazyVars(vars Vars, help string) Vars {
+	if len(k.lazyVars) == 0 {
+		return vars
+	}
+	var deferred Vars
+	for _, match := range interpolationRegex.FindAllStringSubmatch(help, -1) {
+		name := match[3]
+		if _, ok := k.lazyVars[name]; !ok {
+			continue
+		}
+		if _, set := vars[name]; set {
+			continue
+		}
+		if deferred == nil {
+			deferred = Vars{}
+		}
+		if value, ok := k.lazyValues[name]; ok {
+			deferred[name] = value
+		} else {
+			deferred[name] = "${" + name + "}"
+			k.lazyHelp = true
+		}
+	}
+	if deferred == nil {
+		return vars
+	}
+	return vars.CloneWith(deferred)
+}
+
+// expandLazyHelp computes the VarsFunc variables deferred by deferLazyVars and interpolates them into the help of
+// the model, the first time help is printed.
+func (k *Kong) expandLazyHelp() {
+	if !k.lazyHelp {
+		return
+	}
+	k.lazyHelp = false
+	expand := func(help string) string {
+		out := ""
+		for _, match := range interpolationRegex.FindAllStringSubmatch(help, -1) {
+			fn, ok := k.lazyVars[match[3]]
+			if !ok {
+				out += match[0]
+				continue
+			}
+			value, ok := k.lazyValues[match[3]]
+			if !ok {
+				value = fn()
+				k.lazyValues[match[3]] = value
+			}
+			out += value
+		}
+		return out
+	}
+	_ = Visit(k.Model, func(node Visitable, next Next) error {
+		switch node := node.(type) {
+		case *Node:
+			node.Help = expand(node.Help)
+		case *Value:
+			node.Help = expand(node.Help)
+		}
+		return next(nil)
+	})
+}
+
 // Provide additional builtin flags, if any.
 func (k *Kong) extraFlags() []*Flag {
 	if k.noDefaultHelp {
 		return nil
 	}
+	options := k.helpFlagOptions
+	if options.Name == "" {
+		options.Name = "help"
+	}
+	if options.Short == 0 {
+		options.Short = 'h'
+	}
+	if options.NoShort {
+		options.Short = 0
+	}
+	if options.Help == "" {
+		options.Help = "Show context-sensitive help."
+	}
 	var helpTarget helpFlag
 	value := reflect.ValueOf(&helpTarget).Elem()
 	helpFlag := &Flag{
-		Short: 'h',
+		Short: options.Short,
 		Value: &Value{
-			Name:         "help",
-			Help:         "Show context-sensitive help.",
-			OrigHelp:     "Show context-sensitive help.",
+			Name:         options.Name,
+			Help:         options.Help,
+			OrigHelp:     options.Help,
 			Target:       value,
-			Tag:          &Tag{},
+			Tag:          &Tag{Local: options.RootOnly},
 			Mapper:       k.registry.ForValue(value),
 			DefaultValue: reflect.ValueOf(false),
 		},
@@ -319,41 +516,111 @@ func (k *Kong) extraFlags() []*Flag {
 // Will return a ParseError if a *semantically* invalid command-line is encountered (as opposed to a syntactically
 // invalid one, which will report a normal error).
 func (k *Kong) Parse(args []string) (ctx *Context, err error) {
+	if k.shellCompletion {
+		if line, ok := os.LookupEnv("COMP_LINE"); ok {
+			k.printCompletions(line)
+			k.Exit(0)
+		}
+	}
+	if args, err = k.preprocess(args); err != nil {
+		return nil, err
+	}
+	if args, err = k.expandAliases(args); err != nil {
+		// Nothing has been parsed yet, but usage is printed from the root.
+		ctx, _ = Trace(k, nil)
+		return nil, &ParseError{error: err, Context: ctx, category: ExitUsageError}
+	}
 	ctx, err = Trace(k, args)
 	if err != nil { // Trace is not expected to return an err
-		return nil, &ParseError{error: err, Context: ctx, exitCode: exitUsageError}
+		return nil, &ParseError{error: err, Context: ctx, category: ExitUsageError}
 	}
+	traced := ctx
+	defer func() {
+		if err != nil {
+			_ = traced.exit()
+		}
+	}()
 	if ctx.Error != nil {
-		return nil, &ParseError{error: ctx.Error, Context: ctx, exitCode: exitUsageError}
+		return nil, &ParseError{error: ctx.Error, Context: ctx, category: ExitUsageError}
 	}
-	if err = k.applyHook(ctx, "BeforeReset"); err != nil {
+	if err = k.applyHook(ctx, "BeforeReset", nil); err != nil {
 		return nil, &ParseError{error: err, Context: ctx}
 	}
 	if err = ctx.Reset(); err != nil {
 		return nil, &ParseError{error: err, Context: ctx}
 	}
-	if err = k.applyHook(ctx, "BeforeResolve"); err != nil {
+	if err = k.applyHook(ctx, "BeforeResolve", nil); err != nil {
 		return nil, &ParseError{error: err, Context: ctx}
 	}
 	if err = ctx.Resolve(); err != nil {
 		return nil, &ParseError{error: err, Context: ctx}
 	}
-	if err = k.applyHook(ctx, "BeforeApply"); err != nil {
+	if err = k.checkUnusedConfig(ctx); err != nil {
+		return nil, &ParseError{error: err, Context: ctx, category: ExitValidationError}
+	}
+	if err = k.checkUnusedEnvars(); err != nil {
+		return nil, &ParseError{error: err, Context: ctx, category: ExitValidationError}
+	}
+	if err = ctx.promptPasswords(); err != nil {
+		return nil, &ParseError{error: err, Context: ctx}
+	}
+	if err = k.applyHook(ctx, "BeforeApply", nil); err != nil {
 		return nil, &ParseError{error: err, Context: ctx}
 	}
 	if _, err = ctx.Apply(); err != nil { // Apply is not expected to return an err
 		return nil, &ParseError{error: err, Context: ctx}
 	}
 	if err = ctx.Validate(); err != nil {
-		return nil, &ParseError{error: err, Context: ctx, exitCode: exitUsageError}
+		return nil, &ParseError{error: err, Context: ctx, category: ExitValidationError}
 	}
-	if err = k.applyHook(ctx, "AfterApply"); err != nil {
+	if err = k.applyHook(ctx, "AfterApply", nil); err != nil {
 		return nil, &ParseError{error: err, Context: ctx}
 	}
+	k.warnUnstable(ctx)
+	if k.recordPath != "" {
+		if err = k.recordInvocation(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if k.usageTelemetry != nil {
+		k.usageTelemetry(NewUsageSummary(ctx))
+	}
 	return ctx, nil
 }
 
-func (k *Kong) applyHook(ctx *Context, name string) error {
+// ParseInvocations parses a command-line holding several command invocations separated by "separator", eg.
+// "app build --fast + test --race", returning a Context for each invocation in order.
+//
+// Every invocation is parsed, and so validated, before any Context is returned. As the invocations share the
+// grammar, Context.Run() re-applies the values of its own invocation before running it. See RunInvocations for
+// running them together.
+func (k *Kong) ParseInvocations(args []string, separator string) ([]*Context, error) {
+	contexts := []*Context{}
+	for _, invocation := range SplitInvocations(args, separator) {
+		ctx, err := k.Parse(invocation)
+		if err != nil {
+			return nil, err
+		}
+		ctx.shared = len(contexts) > 0
+		contexts = append(contexts, ctx)
+	}
+	if len(contexts) > 1 {
+		contexts[0].shared = true
+	}
+	return contexts, nil
+}
+
+// applyHook calls the hook "name" on each node in the path, with "extra" bindings in addition to the usual ones.
+//
+// Hooks are called in order of increasing priority. Hooks of equal priority are called in path order, with the
+// node's own hooks before those registered by options.
+func (k *Kong) applyHook(ctx *Context, name string, extra bindings) error {
+	type hookCall struct {
+		trace    *Path
+		method   reflect.Value
+		priority int
+	}
+	calls := []hookCall{}
 	for _, trace := range ctx.Path {
 		var value reflect.Value
 		switch {
@@ -370,32 +637,48 @@ func (k *Kong) applyHook(ctx *Context, name string) error {
 		default:
 			panic("unsupported Path")
 		}
-		for _, method := range k.getMethods(value, name) {
-			binds := k.bindings.clone()
-			binds.add(ctx, trace)
-			binds.add(trace.Node().Vars().CloneWith(k.vars))
-			binds.merge(ctx.bindings)
-			if err := callFunction(method, binds); err != nil {
-				return err
-			}
+		// Identify callbacks by reflecting on value.
+		priority := hookPriority(value)
+		for _, method := range getMethods(value, name) {
+			calls = append(calls, hookCall{trace, method, priority})
+		}
+		// Identify callbacks that were registered with a kong.Option.
+		for _, hook := range k.hooks[name] {
+			calls = append(calls, hookCall{trace, hook.fn, hook.priority})
+		}
+	}
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].priority < calls[j].priority })
+	for _, call := range calls {
+		binds := k.bindings.clone()
+		binds.add(ctx, call.trace)
+		binds.add(call.trace.Node().Vars().CloneWith(k.vars))
+		binds.merge(ctx.bindings).merge(extra)
+		if err := callFunction(call.method, binds); err != nil {
+			return err
 		}
 	}
 	// Path[0] will always be the app root.
-	return k.applyHookToDefaultFlags(ctx, ctx.Path[0].Node(), name)
+	return k.applyHookToDefaultFlags(ctx, ctx.Path[0].Node(), name, extra)
 }
 
-func (k *Kong) getMethods(value reflect.Value, name string) []reflect.Value {
-	return append(
-		// Identify callbacks by reflecting on value
-		getMethods(value, name),
-
-		// Identify callbacks that were registered with a kong.Option
-		k.hooks[name]...,
-	)
+// hookPriority returns the priority of hooks on "value", from its HookPrioritizer implementation if any.
+func hookPriority(value reflect.Value) int {
+	if !value.IsValid() || !value.CanInterface() {
+		return 0
+	}
+	if prioritizer, ok := value.Interface().(HookPrioritizer); ok {
+		return prioritizer.HookPriority()
+	}
+	if value.CanAddr() {
+		if prioritizer, ok := value.Addr().Interface().(HookPrioritizer); ok {
+			return prioritizer.HookPriority()
+		}
+	}
+	return 0
 }
 
 // Call hook on any unset flags with default values.
-func (k *Kong) applyHookToDefaultFlags(ctx *Context, node *Node, name string) error {
+func (k *Kong) applyHookToDefaultFlags(ctx *Context, node *Node, name string, extra bindings) error {
 	if node == nil {
 		return nil
 	}
@@ -404,7 +687,7 @@ func (k *Kong) applyHookToDefaultFlags(ctx *Context, node *Node, name string) er
 		if !ok {
 			return next(nil)
 		}
-		binds := k.bindings.clone().add(ctx).add(node.Vars().CloneWith(k.vars))
+		binds := k.bindings.clone().add(ctx).add(node.Vars().CloneWith(k.vars)).merge(extra)
 		for _, flag := range node.Flags {
 			if !flag.HasDefault || ctx.values[flag.Value].IsValid() || !flag.Target.IsValid() {
 				continue
@@ -455,7 +738,8 @@ func (k *Kong) Fatalf(format string, args ...any) {
 
 // FatalIfErrorf terminates with an error message if err != nil.
 // If the error implements the ExitCoder interface, the ExitCode() method is called and
-// the application exits with that status. Otherwise, the application exits with status 1.
+// the application exits with that status. Otherwise, the application exits with the status of its
+// category, see ExitCodes.
 func (k *Kong) FatalIfErrorf(err error, args ...any) {
 	if err == nil {
 		return
@@ -477,7 +761,12 @@ func (k *Kong) FatalIfErrorf(err error, args ...any) {
 		}
 	}
 	k.Errorf("%s", msg)
-	k.Exit(exitCodeFromError(err))
+	if parseErr != nil && k.errorDiagnostics {
+		if diagnostic := parseErr.Diagnostic(k.colorDiagnostics); diagnostic != "" {
+			fmt.Fprintln(k.Stderr, diagnostic)
+		}
+	}
+	k.Exit(k.exitCode(err))
 }
 
 // LoadConfig from path using the loader configured via Configuration(loader).
@@ -2,11 +2,13 @@ package kong_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/alecthomas/repr"
@@ -217,6 +219,47 @@ func TestRequiredFlag(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRequiredIfFlag(t *testing.T) {
+	var cli struct {
+		Env     string   `requiredif:"cmd=deploy,promote"`
+		Build   struct{} `cmd:""`
+		Deploy  struct{} `cmd:""`
+		Promote struct {
+			Canary struct{} `cmd:""`
+		} `cmd:""`
+	}
+	parser := mustNew(t, &cli)
+	_, err := parser.Parse([]string{"build"})
+	assert.NoError(t, err)
+	_, err = parser.Parse([]string{"deploy"})
+	assert.EqualError(t, err, "missing flags: --env=STRING")
+	_, err = parser.Parse([]string{"promote", "canary"})
+	assert.EqualError(t, err, "missing flags: --env=STRING")
+	_, err = parser.Parse([]string{"--env=prod", "deploy"})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", cli.Env)
+	assert.False(t, parser.Model.Flags[1].Required)
+
+	var nested struct {
+		Region  string   `requiredif:"cmd=cluster deploy"`
+		Deploy  struct{} `cmd:""`
+		Cluster struct {
+			Deploy struct{} `cmd:""`
+		} `cmd:""`
+	}
+	parser = mustNew(t, &nested)
+	_, err = parser.Parse([]string{"deploy"})
+	assert.NoError(t, err)
+	_, err = parser.Parse([]string{"cluster", "deploy"})
+	assert.EqualError(t, err, "missing flags: --region=STRING")
+
+	var bad struct {
+		Env string `requiredif:"deploy"`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, `<anonymous struct>.Env: requiredif should be in the form cmd=<command>[,<command>...] but got "deploy"`)
+}
+
 func TestOptionalArg(t *testing.T) {
 	var cli struct {
 		Arg string `kong:"arg,optional"`
@@ -647,6 +690,157 @@ func TestGlobalHooks(t *testing.T) {
 	}, called)
 }
 
+type prioritisedHookCmd struct{}
+
+func (prioritisedHookCmd) HookPriority() int { return -1 }
+
+func (prioritisedHookCmd) AfterApply(called *[]string) error {
+	*called = append(*called, "cmd")
+	return nil
+}
+
+func TestHookPriority(t *testing.T) {
+	var cli struct {
+		One prioritisedHookCmd `cmd:""`
+	}
+	called := []string{}
+	log := func(name string) any {
+		return func(path *kong.Path) error {
+			if path.App != nil {
+				called = append(called, name)
+			}
+			return nil
+		}
+	}
+	p := mustNew(t, &cli, kong.Bind(&called),
+		kong.WithAfterApply(log("default")),
+		kong.WithHookPriority(10, kong.WithAfterApply(log("late"))),
+		kong.WithHookPriority(-10, kong.WithAfterApply(log("early"))),
+		kong.WithAfterApply(log("default2")),
+	)
+	_, err := p.Parse([]string{"one"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"early", "cmd", "default", "default2", "late"}, called)
+}
+
+func TestOverrideFlag(t *testing.T) {
+	var cli struct {
+		Format string `short:"f" default:"text" help:"Output format."`
+		Export struct {
+			Format string `short:"f" override:"" enum:"csv,json" default:"csv" help:"Export format."`
+		} `cmd:""`
+		List struct{} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"export", "-f", "json"})
+	assert.NoError(t, err)
+	assert.Equal(t, "text", cli.Format)
+	assert.Equal(t, "json", cli.Export.Format)
+	_, err = p.Parse([]string{"export", "--format=text"})
+	assert.EqualError(t, err, `--format must be one of "csv","json" but got "text"`)
+	_, err = p.Parse([]string{"list", "--format=yaml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "yaml", cli.Format)
+
+	var dup struct {
+		Format string
+		Export struct {
+			Format string
+		} `cmd:""`
+	}
+	_, err = kong.New(&dup)
+	assert.EqualError(t, err, "<anonymous struct>.Format: duplicate flag --format")
+}
+
+func TestLocalFlag(t *testing.T) {
+	var cli struct {
+		Init  bool `local:""`
+		Debug bool
+		Sub   struct{} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"--init", "--debug", "sub"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Init)
+	_, err = p.Parse([]string{"sub", "--debug"})
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"sub", "--init"})
+	assert.EqualError(t, err, "unknown flag --init")
+}
+
+func TestTraceExpected(t *testing.T) {
+	var cli struct {
+		Debug bool
+		Copy  struct {
+			Force bool
+			Src   string   `arg:""`
+			Dst   []string `arg:""`
+		} `cmd:""`
+		Remote struct {
+			Add    struct{} `cmd:""`
+			Secret struct{} `cmd:"" hidden:""`
+		} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	names := func(nodes []*kong.Node) (out []string) {
+		for _, node := range nodes {
+			out = append(out, node.Name)
+		}
+		return out
+	}
+
+	ctx, err := kong.Trace(p, []string{"--debug"})
+	assert.NoError(t, err)
+	expected := ctx.Expected()
+	assert.Equal(t, []string{"copy", "remote"}, names(expected.Commands))
+	assert.Zero(t, expected.Positional)
+	assert.Equal(t, 2, len(expected.Flags))
+
+	ctx, err = kong.Trace(p, []string{"copy"})
+	assert.NoError(t, err)
+	expected = ctx.Expected()
+	assert.Equal(t, "copy", expected.Node.Name)
+	assert.Equal(t, "src", expected.Positional.Name)
+	assert.Equal(t, 0, len(expected.Commands))
+	assert.Equal(t, 3, len(expected.Flags))
+
+	ctx, err = kong.Trace(p, []string{"copy", "a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "dst", ctx.Expected().Positional.Name)
+
+	ctx, err = kong.Trace(p, []string{"remote"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"add"}, names(ctx.Expected().Commands))
+}
+
+func TestContextSuggest(t *testing.T) {
+	var cli struct {
+		Format  string `enum:"json,yaml,text" default:"text" help:"Output format."`
+		Verbose bool   `short:"v" help:"Verbose output."`
+		Deploy  struct {
+			Env string `arg:"" enum:"dev,prod" help:"Environment."`
+		} `cmd:"" aliases:"dep" help:"Deploy."`
+		Destroy struct{} `cmd:"" help:"Destroy."`
+	}
+	p := mustNew(t, &cli)
+	values := func(candidates []kong.Candidate) (out []string) {
+		for _, candidate := range candidates {
+			out = append(out, candidate.Value)
+		}
+		return out
+	}
+	ctx, err := kong.Trace(p, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deploy", "dep", "destroy"}, values(ctx.Suggest("de")))
+	assert.Equal(t, []kong.Candidate{{Value: "deploy", Help: "Deploy."}}, ctx.Suggest("depl"))
+	assert.Equal(t, []string{"--help", "-h", "--format", "--verbose", "-v"}, values(ctx.Suggest("-")))
+	assert.Equal(t, []string{"--format=json"}, values(ctx.Suggest("--format=j")))
+
+	ctx, err = kong.Trace(p, []string{"deploy"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev", "prod"}, values(ctx.Suggest("")))
+}
+
 func TestShort(t *testing.T) {
 	var cli struct {
 		Bool   bool   `short:"b"`
@@ -724,6 +918,54 @@ func TestEmbeddedStruct(t *testing.T) {
 	assert.Equal(t, "foo", cli.NotEmbedded)
 }
 
+type dbConfig struct {
+	Host string `env:"HOST"`
+}
+
+func TestInterpolatedEmbedPrefix(t *testing.T) {
+	var cli struct {
+		Primary dbConfig `embed:"" set:"instance=db1" prefix:"${instance}-" envprefix:"${instance}_"`
+		Replica dbConfig `embed:"" prefix:"${replica}-"`
+	}
+	p := mustNew(t, &cli, kong.Vars{"replica": "db2"})
+	_, err := p.Parse([]string{"--db1-host=a", "--db2-host=b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "a", cli.Primary.Host)
+	assert.Equal(t, "b", cli.Replica.Host)
+	assert.Equal(t, []string{"db1_HOST"}, p.Model.Flags[1].Envs)
+
+	_, err = kong.New(&cli)
+	assert.EqualError(t, err, "<anonymous struct>.Replica: prefix: undefined variable ${replica}")
+}
+
+type listenerConfig struct {
+	Port int
+	Host string `default:"localhost"`
+}
+
+func TestIndexedEmbeddedGroups(t *testing.T) {
+	var cli struct {
+		Listeners []listenerConfig `embed:"" prefix:"listener.{i}." instances:"3"`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"--listener.0.port=80", "--listener.1.port=81", "--listener.1.host=example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, []listenerConfig{{80, "localhost"}, {81, "example.com"}}, cli.Listeners)
+
+	_, err = p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []listenerConfig{}, cli.Listeners)
+
+	_, err = p.Parse([]string{"--listener.3.port=82"})
+	assert.EqualError(t, err, `unknown flag --listener.3.port, did you mean one of "--listener.0.port", "--listener.1.port", "--listener.2.port"?`)
+
+	var bad struct {
+		Listeners []listenerConfig `embed:"" prefix:"listener.{i}."`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, "<anonymous struct>.Listeners: embedded slices require an instances:\"N\" tag")
+}
+
 func TestSliceWithDisabledSeparator(t *testing.T) {
 	var cli struct {
 		Flag []string `sep:"none"`
@@ -806,6 +1048,129 @@ func TestRun(t *testing.T) {
 	assert.Equal(t, "argping", cli.Three.SubCommand.Arg)
 }
 
+type copyCmdWithArgs struct {
+	Src     string `arg:""`
+	Dst     string `arg:"" optional:""`
+	Verbose bool
+}
+
+func (c *copyCmdWithArgs) Run(args kong.Args, out *[]string) error {
+	*out = append(*out, fmt.Sprintf("%s %v -> %v", args["repo"], args["src"], args["dst"]))
+	return nil
+}
+
+func TestRunBindsArgs(t *testing.T) {
+	var cli struct {
+		Repo struct {
+			Repo string          `arg:""`
+			Copy copyCmdWithArgs `cmd:""`
+		} `arg:""`
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"kong", "copy", "a.go"})
+	assert.NoError(t, err)
+	out := []string{}
+	err = ctx.Run(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kong a.go -> "}, out)
+}
+
+func TestContextParseFacts(t *testing.T) {
+	var cli struct {
+		Region string `env:"TEST_REGION" default:"eu"`
+		Zone   string `default:"a"`
+		User   struct {
+			Create struct {
+				Name  string `arg:""`
+				Admin bool   `short:"a"`
+			} `cmd:"" aliases:"add"`
+		} `cmd:""`
+	}
+	t.Setenv("TEST_REGION", "us")
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"user", "add", "-a", "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, "user create", ctx.SelectedCommand())
+	assert.Equal(t, "user create <name>", ctx.Command())
+	assert.True(t, ctx.FlagSet("--admin"))
+	assert.True(t, ctx.FlagSet("-a"))
+	assert.True(t, ctx.FlagSet("region"))
+	assert.False(t, ctx.FlagSet("--zone"))
+	assert.False(t, ctx.FlagSet("--unknown"))
+	assert.Equal(t, kong.Args{"name": "alice"}, ctx.PositionalValues())
+
+	ctx, err = p.Parse([]string{"--zone=b", "user", "create", "bob"})
+	assert.NoError(t, err)
+	assert.True(t, ctx.FlagSet("--zone"))
+	assert.False(t, ctx.FlagSet("--admin"))
+}
+
+type dryRunCmd struct{}
+
+func (dryRunCmd) Run(dryRun kong.DryRun, out *[]string) error {
+	*out = append(*out, fmt.Sprintf("dry-run=%v", dryRun))
+	return nil
+}
+
+func TestDryRunFlag(t *testing.T) {
+	var cli struct {
+		DryRun kong.DryRunFlag
+		Deploy dryRunCmd `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	out := []string{}
+	for _, args := range [][]string{{"deploy"}, {"--dry-run", "deploy"}} {
+		ctx, err := p.Parse(args)
+		assert.NoError(t, err)
+		assert.NoError(t, ctx.Run(&out))
+	}
+	assert.Equal(t, []string{"dry-run=false", "dry-run=true"}, out)
+}
+
+type plainRunCmd struct{}
+
+func (plainRunCmd) Run(out *[]string) error {
+	*out = append(*out, "Run")
+	return nil
+}
+
+type richRunCmd struct{ plainRunCmd }
+
+func (richRunCmd) RunE(ctx *kong.Context, out *[]string, extras ...fmt.Stringer) error {
+	*out = append(*out, fmt.Sprintf("RunE %s %d", ctx.Command(), len(extras)))
+	return nil
+}
+
+func TestRunMethods(t *testing.T) {
+	var cli struct {
+		Plain plainRunCmd `cmd:""`
+		Rich  richRunCmd  `cmd:""`
+	}
+	p := mustNew(t, &cli, kong.RunMethods("RunE", "Run"))
+	out := []string{}
+	for _, cmd := range []string{"plain", "rich"} {
+		ctx, err := p.Parse([]string{cmd})
+		assert.NoError(t, err)
+		err = ctx.Run(&out)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"Run", "RunE rich 0"}, out)
+
+	ctx, err := p.Parse([]string{"rich"})
+	assert.NoError(t, err)
+	ctx.BindTo(time.Second, (*fmt.Stringer)(nil))
+	err = ctx.Run(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, "RunE rich 1", out[len(out)-1])
+
+	p = mustNew(t, &cli)
+	ctx, err = p.Parse([]string{"rich"})
+	assert.NoError(t, err)
+	err = ctx.Run(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, "Run", out[len(out)-1])
+}
+
 type failCmd struct{}
 
 func (f failCmd) Run() error {
@@ -1009,6 +1374,63 @@ func TestEnum(t *testing.T) {
 	assert.EqualError(t, err, "--flag must be one of \"a\",\"b\",\"c\" but got \"d\"")
 }
 
+func TestEnumProvider(t *testing.T) {
+	var cli struct {
+		Profile string `enumfrom:"profiles"`
+		Debug   bool
+	}
+	calls := 0
+	p := mustNew(t, &cli, kong.EnumProvider("profiles", func() ([]string, error) {
+		calls++
+		return []string{"dev", "prod"}, nil
+	}))
+	_, err := p.Parse([]string{"--debug"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, calls)
+	_, err = p.Parse([]string{"--profile", "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", cli.Profile)
+	_, err = p.Parse([]string{"--profile", "staging"})
+	assert.EqualError(t, err, "--profile must be one of \"dev\",\"prod\" but got \"staging\"")
+	assert.Equal(t, 1, calls)
+
+	ctx, err := kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []kong.Candidate{{Value: "--profile=dev"}, {Value: "--profile=prod"}}, ctx.Suggest("--profile="))
+
+	_, err = kong.New(&cli)
+	assert.EqualError(t, err, "<anonymous struct>.Profile: unknown enum provider \"profiles\", perhaps missing an EnumProvider() option?")
+}
+
+func TestElementValidation(t *testing.T) {
+	var cli struct {
+		Formats []string          `enum:"csv,json" default:"csv"`
+		Names   []string          `pattern:"[a-z]+"`
+		Ports   map[string]int    `min:"1" max:"65535"`
+		Weights []float64         `max:"1"`
+		Labels  map[string]string `enum:"a,b" default:"x=a"`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"--formats=csv,xml"})
+	assert.EqualError(t, err, `--formats[1] must be one of "csv","json" but got "xml"`)
+	_, err = p.Parse([]string{"--names=alice,Bob"})
+	assert.EqualError(t, err, `--names[1] must match "[a-z]+" but got "Bob"`)
+	_, err = p.Parse([]string{"--ports=http=80;https=0"})
+	assert.EqualError(t, err, `--ports[https] must be at least 1 but got 0`)
+	_, err = p.Parse([]string{"--weights=0.5", "--weights=2"})
+	assert.EqualError(t, err, `--weights[1] must be at most 1 but got 2`)
+	_, err = p.Parse([]string{"--labels=x=a;y=c"})
+	assert.EqualError(t, err, `--labels[y] must be one of "a","b" but got "c"`)
+	_, err = p.Parse([]string{"--names=alice", "--ports=http=80"})
+	assert.NoError(t, err)
+
+	var bad struct {
+		Name string `min:"1"`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, "<anonymous struct>.Name: min only makes sense for numbers")
+}
+
 func TestEnumMeaningfulOrder(t *testing.T) {
 	var cli struct {
 		Flag string `enum:"first,second,third,fourth,fifth" required:""`
@@ -1073,6 +1495,32 @@ func TestEnvarEnumValidated(t *testing.T) {
 	assert.EqualError(t, err, "--flag must be one of \"valid\" but got \"invalid\"")
 }
 
+func TestGroupMissingFlags(t *testing.T) {
+	var cli struct {
+		Token  string `required:"" help:"API token."`
+		DBHost string `required:"" group:"Database" help:"Database host.\nEg. localhost."`
+		DBUser string `required:"" group:"Database" help:"Database user."`
+		Debug  bool   `xor:"log" required:""`
+		Trace  bool   `xor:"log" required:""`
+	}
+	_, err := mustNew(t, &cli).Parse(nil)
+	assert.EqualError(t, err, "missing flags: --db-host=STRING, --db-user=STRING, --debug or --trace, --token=STRING")
+	var missing *kong.MissingFlagsError
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, 3, len(missing.Flags))
+
+	_, err = mustNew(t, &cli, kong.GroupMissingFlags()).Parse(nil)
+	assert.EqualError(t, err, `missing flags:
+  --token=STRING    API token.
+  --debug or --trace
+  Database
+    --db-host=STRING    Database host.
+    --db-user=STRING    Database user.`)
+
+	_, err = mustNew(t, &cli, kong.GroupMissingFlags()).Parse([]string{"--db-host=x", "--db-user=y", "--debug"})
+	assert.EqualError(t, err, "missing flags: --token=STRING")
+}
+
 func TestXor(t *testing.T) {
 	var cli struct {
 		Hello bool   `xor:"another"`
@@ -1389,7 +1837,76 @@ func TestDefaultCommandWithSubCommand(t *testing.T) {
 		} `cmd:"" default:"1"`
 	}
 	_, err := kong.New(&cli)
-	assert.EqualError(t, err, "<anonymous struct>.One: default command one <command> must not have subcommands or arguments")
+	assert.EqualError(t, err, "<anonymous struct>.One: default command one <command> has subcommands, so one of them must also be a default command")
+}
+
+type openCmd struct {
+	URL struct {
+		URL string `arg:""`
+	} `cmd:"" hidden:""`
+	Path struct {
+		Path string `arg:""`
+	} `cmd:"" hidden:""`
+}
+
+func (o *openCmd) Dispatch(arg string) (string, error) {
+	switch {
+	case strings.Contains(arg, "://"):
+		return "url", nil
+	case strings.HasPrefix(arg, "!"):
+		return "", errors.New("invalid target")
+	case strings.Contains(arg, "/"):
+		return "path", nil
+	}
+	return "", nil
+}
+
+func TestDispatcher(t *testing.T) {
+	var cli struct {
+		Open openCmd `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"open", "https://example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "open url <url>", ctx.Command())
+	assert.Equal(t, "https://example.com", cli.Open.URL.URL)
+	ctx, err = p.Parse([]string{"open", "./README.md"})
+	assert.NoError(t, err)
+	assert.Equal(t, "open path <path>", ctx.Command())
+	assert.Equal(t, "./README.md", cli.Open.Path.Path)
+	ctx, err = p.Parse([]string{"open", "path", "/tmp"})
+	assert.NoError(t, err)
+	assert.Equal(t, "open path <path>", ctx.Command())
+	_, err = p.Parse([]string{"open", "!x"})
+	assert.EqualError(t, err, "invalid target")
+	_, err = p.Parse([]string{"open", "12"})
+	assert.EqualError(t, err, "unexpected argument 12")
+}
+
+func TestNestedDefaultCommands(t *testing.T) {
+	var cli struct {
+		Verbose bool
+		Server  struct {
+			Start struct {
+				Port int `default:"8080"`
+			} `cmd:"" default:"1"`
+			Stop struct{} `cmd:""`
+		} `cmd:"" default:"1"`
+		Client struct{} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, "server start", ctx.Command())
+	ctx, err = p.Parse([]string{"--verbose", "server"})
+	assert.NoError(t, err)
+	assert.Equal(t, "server start", ctx.Command())
+	ctx, err = p.Parse([]string{"server", "stop"})
+	assert.NoError(t, err)
+	assert.Equal(t, "server stop", ctx.Command())
+	ctx, err = p.Parse([]string{"client"})
+	assert.NoError(t, err)
+	assert.Equal(t, "client", ctx.Command())
 }
 
 func TestDefaultCommandWithAllowedSubCommand(t *testing.T) {
@@ -1456,6 +1973,36 @@ func TestDefaultCommandWithAllowedBranchingArgument(t *testing.T) {
 	assert.Equal(t, "value", cli.One.Two.Flag)
 }
 
+func TestDefaultCommandReceivesUnknownFlags(t *testing.T) {
+	var cli struct {
+		Debug bool
+		Run   struct {
+			Quiet bool
+			Args  []string `arg:"" passthrough:"all"`
+		} `cmd:"" default:"withargs"`
+		Version struct{} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"--debug", "--quiet", "--rm", "-i", "alpine", "sh"})
+	assert.NoError(t, err)
+	assert.Equal(t, "run <args>", ctx.Command())
+	assert.True(t, cli.Debug)
+	assert.True(t, cli.Run.Quiet)
+	assert.Equal(t, []string{"--rm", "-i", "alpine", "sh"}, cli.Run.Args)
+
+	ctx, err = p.Parse([]string{"version"})
+	assert.NoError(t, err)
+	assert.Equal(t, "version", ctx.Command())
+
+	var strict struct {
+		Run struct {
+			Args []string `arg:"" optional:""`
+		} `cmd:"" default:"withargs"`
+	}
+	_, err = mustNew(t, &strict).Parse([]string{"--rm"})
+	assert.EqualError(t, err, "unknown flag --rm")
+}
+
 func TestDefaultCommandPrecedence(t *testing.T) {
 	var cli struct {
 		Two struct {
@@ -2487,6 +3034,150 @@ func TestAfterRun(t *testing.T) {
 	assert.Equal(t, afterRunCLI{runCalled: true, afterRunCalled: true}, cli)
 }
 
+type failingRunCLI struct {
+	Panic  bool
+	result []string `kong:"-"`
+}
+
+func (c *failingRunCLI) Run() error {
+	if c.Panic {
+		panic("boom")
+	}
+	return errors.New("failed")
+}
+
+func (c *failingRunCLI) AfterRun(err error) error {
+	c.result = append(c.result, err.Error())
+	return nil
+}
+
+func TestAfterRunOnFailure(t *testing.T) {
+	var cli failingRunCLI
+	k := mustNew(t, &cli)
+	kctx, err := k.Parse([]string{})
+	assert.NoError(t, err)
+	err = kctx.Run()
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, []string{"failed"}, cli.result)
+
+	kctx, err = k.Parse([]string{"--panic"})
+	assert.NoError(t, err)
+	assert.Panics(t, func() { _ = kctx.Run() })
+	assert.Equal(t, []string{"failed", "panic: boom"}, cli.result)
+}
+
+type storeKey struct{}
+
+type storeCLI struct {
+	Name string
+}
+
+func (s *storeCLI) AfterApply(ctx *kong.Context) error {
+	ctx.Set(storeKey{}, "hello "+s.Name)
+	return nil
+}
+
+func (s *storeCLI) Run(ctx *kong.Context) error {
+	greeting, ok := kong.ContextValue[string](ctx, storeKey{})
+	if !ok {
+		return errors.New("missing greeting")
+	}
+	return errors.New(greeting)
+}
+
+func TestContextStore(t *testing.T) {
+	var cli storeCLI
+	k := mustNew(t, &cli)
+	kctx, err := k.Parse([]string{"--name=bob"})
+	assert.NoError(t, err)
+	err = kctx.Run()
+	assert.EqualError(t, err, "hello bob")
+	_, ok := kong.ContextValue[int](kctx, storeKey{})
+	assert.False(t, ok)
+	_, ok = kctx.Get("missing")
+	assert.False(t, ok)
+}
+
+type onExitCLI struct {
+	Fail   bool
+	events []string `kong:"-"`
+}
+
+func (c *onExitCLI) AfterApply(ctx *kong.Context) error {
+	for _, name := range []string{"file", "conn"} {
+		name := name
+		c.events = append(c.events, "open "+name)
+		ctx.OnExit(func() error {
+			c.events = append(c.events, "close "+name)
+			if name == "conn" {
+				return errors.New("conn already closed")
+			}
+			return nil
+		})
+	}
+	if c.Fail {
+		return errors.New("hook failed")
+	}
+	return nil
+}
+
+func (c *onExitCLI) Run() error {
+	c.events = append(c.events, "run")
+	return errors.New("run failed")
+}
+
+func (c *onExitCLI) AfterRun() error {
+	c.events = append(c.events, "after run")
+	return nil
+}
+
+func TestContextOnExit(t *testing.T) {
+	var cli onExitCLI
+	k := mustNew(t, &cli)
+	kctx, err := k.Parse([]string{})
+	assert.NoError(t, err)
+	err = kctx.Run()
+	assert.EqualError(t, err, "run failed\nconn already closed")
+	assert.Equal(t, []string{"open file", "open conn", "run", "after run", "close conn", "close file"}, cli.events)
+
+	cli.events = nil
+	_, err = k.Parse([]string{"--fail"})
+	assert.EqualError(t, err, "hook failed")
+	assert.Equal(t, []string{"open file", "open conn", "close conn", "close file"}, cli.events)
+}
+
+type invocationCmd struct {
+	Fast  bool
+	Files []string `arg:"" optional:""`
+}
+
+func (c *invocationCmd) Run(ctx *kong.Context, out *[]string) error {
+	*out = append(*out, fmt.Sprintf("%s fast=%v files=%v", ctx.Command(), c.Fast, c.Files))
+	return nil
+}
+
+func TestParseInvocations(t *testing.T) {
+	var cli struct {
+		Build invocationCmd `cmd:""`
+		Test  invocationCmd `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	ctxs, err := p.ParseInvocations([]string{"build", "--fast", "a", "+", "test", "b", "+", "build"}, "+")
+	assert.NoError(t, err)
+	out := []string{}
+	for _, ctx := range ctxs {
+		assert.NoError(t, ctx.Run(&out))
+	}
+	assert.Equal(t, []string{
+		"build <files> fast=true files=[a]",
+		"test <files> fast=false files=[b]",
+		"build fast=false files=[]",
+	}, out)
+
+	_, err = p.ParseInvocations([]string{"build", "+", "deploy"}, "+")
+	assert.EqualError(t, err, `unexpected argument deploy`)
+}
+
 type ProvidedString string
 
 type providerCLI struct {
@@ -2704,3 +3395,430 @@ func TestParseHyphenParameter(t *testing.T) {
 		assert.Equal(t, &shortFlag{Numeric: -10}, actual)
 	})
 }
+
+func TestSecretMasking(t *testing.T) {
+	var cli struct {
+		Token string `secret:"" default:"s3cr3t" help:"Token (default ${default})."`
+		Mode  string `secret:"" enum:"alpha,beta" default:"alpha"`
+		Port  int    `secret:""`
+	}
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(w, w))
+	help := p.Model.Flags[1]
+	assert.Equal(t, "Token (default ********).", help.Help)
+	assert.Equal(t, "--token=STRING", help.Summary())
+
+	_, err := p.Parse([]string{"--mode=gamma"})
+	assert.EqualError(t, err, `--mode must be one of "alpha","beta" but got "********"`)
+	assert.NotContains(t, err.Error(), "gamma")
+
+	_, err = p.Parse([]string{"--port=12ab"})
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "12ab")
+
+	ctx, err := p.Parse([]string{"--token=hunter2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", cli.Token)
+	_ = ctx.Run()
+	assert.Equal(t, "", cli.Token)
+}
+
+func TestNegationPrefix(t *testing.T) {
+	var cli struct {
+		Color bool `negatable:"" default:"true"`
+	}
+	p := mustNew(t, &cli, kong.NegationPrefix("disable-"))
+	_, err := p.Parse([]string{"--disable-color"})
+	assert.NoError(t, err)
+	assert.False(t, cli.Color)
+	_, err = p.Parse([]string{"--no-color"})
+	assert.EqualError(t, err, `unknown flag --no-color`)
+}
+
+func TestAutoNegatable(t *testing.T) {
+	var cli struct {
+		Color   bool `default:"true" help:"Colorize output."`
+		Verbose bool
+	}
+	p := mustNew(t, &cli, kong.AutoNegatable(), kong.NegationPrefix("without-"))
+	_, err := p.Parse([]string{"--without-color"})
+	assert.NoError(t, err)
+	assert.False(t, cli.Color)
+	_, err = p.Parse([]string{"--no-verbose"})
+	assert.Error(t, err)
+}
+
+func TestConditionalVisibility(t *testing.T) {
+	type CLI struct {
+		Exp struct {
+			Fast bool `enabled:"${experimental}"`
+		} `cmd:"" enabled:"${experimental}"`
+		Preview struct{} `cmd:"" hidden:"${!beta}"`
+		Trace   bool     `hidden:"${!beta}"`
+		Gated   bool     `enabled:"${KONG_TEST_GATE}"`
+		Legacy  bool     `hidden:"yes"`
+		Old     struct{} `cmd:"" hidden:"false"`
+	}
+
+	var cli CLI
+	p := mustNew(t, &cli, kong.Vars{"experimental": "false", "beta": "false"})
+	_, err := p.Parse([]string{"exp"})
+	assert.EqualError(t, err, `unexpected argument exp`)
+	_, err = p.Parse([]string{"--gated"})
+	assert.EqualError(t, err, `unknown flag --gated`)
+	assert.True(t, p.Model.Children[0].Hidden)
+	assert.True(t, p.Model.Flags[1].Hidden)
+	assert.True(t, p.Model.Flags[2].Hidden)
+	assert.True(t, p.Model.Children[1].Hidden)
+
+	t.Setenv("KONG_TEST_GATE", "1")
+	cli = CLI{}
+	p = mustNew(t, &cli, kong.Vars{"experimental": "true", "beta": "true"})
+	_, err = p.Parse([]string{"--gated", "exp", "--fast"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Gated)
+	assert.True(t, cli.Exp.Fast)
+	assert.False(t, p.Model.Children[1].Hidden)
+
+	_, err = kong.New(&cli, kong.Vars{"experimental": "maybe"})
+	assert.EqualError(t, err, `enabled for exp: invalid condition "maybe"`)
+}
+
+func TestConditionalVisibilityDefaultCommand(t *testing.T) {
+	var cli struct {
+		Serve struct{} `cmd:"" default:"1" enabled:"${serve}" hidden:"${!beta}"`
+		Check struct{} `cmd:""`
+	}
+	p := mustNew(t, &cli, kong.Vars{"serve": "true", "beta": "false"})
+	ctx, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "serve", ctx.Command())
+
+	p = mustNew(t, &cli, kong.Vars{"serve": "false", "beta": "false"})
+	assert.Zero(t, p.Model.DefaultCmd)
+	_, err = p.Parse(nil)
+	assert.EqualError(t, err, "expected \"check\"")
+}
+
+func TestVarsFunc(t *testing.T) {
+	var cli struct {
+		Procs   int    `default:"${procs}" help:"Worker count (default ${procs})."`
+		Context string `default:"${kubecontext}"`
+	}
+	calls := map[string]int{}
+	lazy := func(name, value string) func() string {
+		return func() string {
+			calls[name]++
+			return value
+		}
+	}
+	p := mustNew(t, &cli, kong.VarsFunc{
+		"procs":       lazy("procs", "8"),
+		"kubecontext": lazy("kubecontext", "prod"),
+		"unused":      lazy("unused", "x"),
+	}, kong.Vars{"kubecontext": "dev"})
+	_, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, cli.Procs)
+	assert.Equal(t, "dev", cli.Context)
+	assert.Equal(t, map[string]int{"procs": 1}, calls)
+}
+
+func TestVarsFuncHelpOnly(t *testing.T) {
+	var cli struct {
+		Region string `help:"Region, one of ${regions}."`
+	}
+	calls := 0
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.VarsFunc{"regions": func() string {
+		calls++
+		return "eu, us"
+	}}, kong.Writers(w, w), kong.Exit(func(int) {}))
+	_, err := p.Parse([]string{"--region=eu"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, calls)
+
+	_, _ = p.Parse([]string{"--help"})
+	_, _ = p.Parse([]string{"--help"})
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, w.String(), "Region, one of eu, us.")
+}
+
+func TestCommandSuggestionsIncludeAliases(t *testing.T) {
+	var cli struct {
+		Remove struct{} `cmd:"" aliases:"rm"`
+		List   struct{} `cmd:"" aliases:"ls"`
+		Hidden struct{} `cmd:"" aliases:"rn" hidden:""`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"rx"})
+	assert.EqualError(t, err, `unexpected argument rx, did you mean one of "rm", "ls"?`)
+	_, err = p.Parse([]string{"lst"})
+	assert.EqualError(t, err, `unexpected argument lst, did you mean one of "list", "ls"?`)
+}
+
+type errHelpRegion string
+
+func (r errHelpRegion) Validate() error {
+	if !strings.Contains(string(r), "-") {
+		return fmt.Errorf("invalid region %q", string(r))
+	}
+	return nil
+}
+
+func TestErrHelpTag(t *testing.T) {
+	var cli struct {
+		Count  int           `errhelp:"expects a whole number of workers"`
+		Level  string        `enum:"debug,info" default:"info" errhelp:"see --help for levels"`
+		Region errHelpRegion `errhelp:"expects a region like us-east-1"`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"--count=many"})
+	assert.EqualError(t, err, `--count: expected a valid 64 bit int but got "many"; expects a whole number of workers`)
+	_, err = p.Parse([]string{"--level=trace"})
+	assert.EqualError(t, err, `--level must be one of "debug","info" but got "trace"; see --help for levels`)
+	_, err = p.Parse([]string{"--region=useast"})
+	assert.EqualError(t, err, `--region: invalid region "useast"; expects a region like us-east-1`)
+}
+
+func TestNonInterspersedCommand(t *testing.T) {
+	var cli struct {
+		Debug bool
+		Run   struct {
+			Env  []string
+			Args []string `arg:""`
+		} `cmd:"" noninterspersed:""`
+		Ls struct {
+			Long  bool
+			Paths []string `arg:""`
+		} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"run", "--env=A=1", "--debug", "make", "--debug", "-j4", "--", "x"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Debug)
+	assert.Equal(t, []string{"A=1"}, cli.Run.Env)
+	assert.Equal(t, []string{"make", "--debug", "-j4", "--", "x"}, cli.Run.Args)
+
+	_, err = p.Parse([]string{"ls", "a", "--long"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Ls.Long)
+
+	var bad struct {
+		Flag bool `noninterspersed:""`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, "<anonymous struct>.Flag: noninterspersed only makes sense for commands")
+}
+
+func TestRestField(t *testing.T) {
+	var cli struct {
+		Extra []string `rest:""`
+		Exec  struct {
+			Verbose bool
+			Args    []string `arg:"" optional:""`
+		} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"exec", "a", "--", "--verbose", "b", "--"})
+	assert.NoError(t, err)
+	assert.False(t, cli.Exec.Verbose)
+	assert.Equal(t, []string{"a"}, cli.Exec.Args)
+	assert.Equal(t, []string{"--verbose", "b", "--"}, cli.Extra)
+
+	_, err = p.Parse([]string{"exec", "--verbose"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Exec.Verbose)
+	assert.Equal(t, []string(nil), cli.Extra)
+
+	// Commands without a rest field parse the arguments after "--" as usual.
+	var sibling struct {
+		Exec struct {
+			Command []string `rest:""`
+		} `cmd:""`
+		Run struct {
+			Args []string `arg:"" optional:""`
+		} `cmd:""`
+	}
+	p = mustNew(t, &sibling)
+	_, err = p.Parse([]string{"run", "--", "-x", "y"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-x", "y"}, sibling.Run.Args)
+	_, err = p.Parse([]string{"exec", "--", "-x", "y"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-x", "y"}, sibling.Exec.Command)
+
+	var bad struct {
+		Rest string `rest:""`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, "<anonymous struct>.Rest: rest must be a []string")
+}
+
+func TestUnknownArgsCapture(t *testing.T) {
+	var cli struct {
+		Exec struct {
+			Env     []string `short:"e"`
+			Verbose bool     `short:"v"`
+			Command []string `rest:""`
+			List    struct{} `cmd:""`
+		} `cmd:"" unknownargs:"capture"`
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"exec", "-e", "A=1", "docker", "run", "--rm", "-v", "x", "--", "y"})
+	assert.NoError(t, err)
+	assert.Equal(t, "exec", ctx.Command())
+	assert.Equal(t, []string{"A=1"}, cli.Exec.Env)
+	assert.False(t, cli.Exec.Verbose)
+	assert.Equal(t, []string{"docker", "run", "--rm", "-v", "x", "--", "y"}, cli.Exec.Command)
+
+	_, err = p.Parse([]string{"exec", "-v", "--pull=always", "image"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Exec.Verbose)
+	assert.Equal(t, []string{"--pull=always", "image"}, cli.Exec.Command)
+
+	_, err = p.Parse([]string{"exec", "-xz", "image"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-xz", "image"}, cli.Exec.Command)
+
+	ctx, err = p.Parse([]string{"exec", "list"})
+	assert.NoError(t, err)
+	assert.Equal(t, "exec list", ctx.Command())
+
+	var bad struct {
+		Exec struct{} `cmd:"" unknownargs:"capture"`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, `<anonymous struct>.Exec: unknownargs:"capture" requires a rest:"" field to capture into`)
+}
+
+func TestMaxCount(t *testing.T) {
+	var cli struct {
+		Verbose int               `type:"counter" short:"v" maxcount:"2"`
+		Tag     []string          `maxcount:"3"`
+		Label   map[string]string `maxcount:"1"`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"-vv", "--tag=a,b", "--tag=c", "--label=k=v"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cli.Verbose)
+	assert.Equal(t, []string{"a", "b", "c"}, cli.Tag)
+	_, err = p.Parse([]string{"-vvv"})
+	assert.EqualError(t, err, "--verbose can be given at most 2 times")
+	_, err = p.Parse([]string{"--tag=a,b", "--tag=c,d"})
+	assert.EqualError(t, err, "--tag accepts at most 3 values")
+	_, err = p.Parse([]string{"--label=a=1", "--label=b=2"})
+	assert.EqualError(t, err, "--label accepts at most 1 values")
+
+	var bad struct {
+		Name string `maxcount:"2"`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, "<anonymous struct>.Name: maxcount only makes sense for slices, maps and counters")
+}
+
+func TestMessages(t *testing.T) {
+	var cli struct {
+		Level string `enum:"low,high" default:"low"`
+		Cmd   struct {
+		} `cmd:""`
+	}
+	p := mustNew(t, &cli, kong.Messages(map[kong.Message]string{
+		kong.MessageUnknownFlag: "no such option %s",
+		kong.MessageEnum:        "%s: %[3]q is not allowed, pick %[2]s",
+	}))
+	_, err := p.Parse([]string{"--levle", "high", "cmd"})
+	assert.EqualError(t, err, `no such option --levle, did you mean "--level"?`)
+	_, err = p.Parse([]string{"--level", "mid", "cmd"})
+	assert.EqualError(t, err, `--level: "mid" is not allowed, pick "low","high"`)
+	_, err = p.Parse(nil)
+	assert.EqualError(t, err, `expected "cmd"`)
+
+	_, err = kong.New(&cli, kong.Messages(map[kong.Message]string{kong.MessageUnknownFlag: "no such option"}))
+	assert.EqualError(t, err, `message "unknown-flag": expected 1 arguments but "no such option" has 0`)
+}
+
+func TestMessagesMapperErrors(t *testing.T) {
+	var cli struct {
+		Count int    `short:"c"`
+		Size  uint64 `unit:"B"`
+	}
+	p := mustNew(t, &cli, kong.Messages(map[kong.Message]string{
+		kong.MessageInt:           "%[2]q is not a number",
+		kong.MessageExpectedValue: "%[1]s value missing",
+		kong.MessageUnknownUnit:   "%[2]q: no such unit %[1]q",
+	}))
+	_, err := p.Parse([]string{"--count", "many"})
+	assert.EqualError(t, err, `--count: "many" is not a number`)
+	_, err = p.Parse([]string{"--count"})
+	assert.EqualError(t, err, `--count: int value missing`)
+	_, err = p.Parse([]string{"--size", "3XB"})
+	assert.EqualError(t, err, `--size: "3XB": no such unit "XB"`)
+
+	defaults := kong.DefaultMessages()
+	defaults[kong.MessageInt] = "changed %d %q"
+	assert.Equal(t, "expected a valid %d bit int but got %q", kong.DefaultMessages()[kong.MessageInt])
+}
+
+func TestExitCodes(t *testing.T) {
+	var cli struct {
+		Name string `required:""`
+	}
+	exitCode := -1
+	p := mustNew(t, &cli,
+		kong.Writers(&bytes.Buffer{}, &bytes.Buffer{}),
+		kong.Exit(func(code int) { exitCode = code }),
+		kong.ExitCodes(map[kong.ExitCategory]int{kong.ExitUsageError: 64, kong.ExitValidationError: 65}),
+	)
+	assert.Equal(t, map[kong.ExitCategory]int{
+		kong.ExitUsageError:      64,
+		kong.ExitValidationError: 65,
+		kong.ExitRunError:        1,
+		kong.ExitInterrupted:     130,
+	}, p.ExitCodes())
+
+	for _, test := range []struct {
+		args     []string
+		err      error
+		expected int
+	}{
+		{args: []string{"--unknown"}, expected: 64},
+		{args: []string{}, expected: 65},
+		{err: errors.New("failed"), expected: 1},
+		{err: fmt.Errorf("stopped: %w", context.Canceled), expected: 130},
+	} {
+		err := test.err
+		if err == nil {
+			_, err = p.Parse(test.args)
+		}
+		p.FatalIfErrorf(err)
+		assert.Equal(t, test.expected, exitCode)
+	}
+}
+
+func TestTristate(t *testing.T) {
+	var cli struct {
+		Color kong.Tristate `negatable:""`
+	}
+	p := mustNew(t, &cli)
+	for _, test := range []struct {
+		args     []string
+		expected kong.Tristate
+	}{
+		{nil, kong.TristateAuto},
+		{[]string{"--color"}, kong.TristateTrue},
+		{[]string{"--no-color"}, kong.TristateFalse},
+		{[]string{"--color=false"}, kong.TristateFalse},
+		{[]string{"--color=auto"}, kong.TristateAuto},
+		{[]string{"--no-color=false"}, kong.TristateTrue},
+	} {
+		_, err := p.Parse(test.args)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, cli.Color, "%v", test.args)
+	}
+	assert.True(t, kong.TristateAuto.Bool(true))
+	assert.False(t, kong.TristateFalse.Bool(true))
+
+	_, err := p.Parse([]string{"--color=maybe"})
+	assert.EqualError(t, err, `--color: value must be auto, true or false but got "maybe"`)
+}
@@ -0,0 +1,12 @@
+// Command kongcheck validates Kong struct tags in Go packages.
+//
+//	go run github.com/alecthomas/kong/kongcheck/cmd/kongcheck ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/alecthomas/kong/kongcheck"
+)
+
+func main() { singlechecker.Main(kongcheck.Analyzer) }
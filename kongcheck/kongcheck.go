@@ -0,0 +1,342 @@
+// Package kongcheck provides an analyzer that validates Kong struct tags in source, so that grammar errors are
+// reported by editors and CI rather than by kong.New() at runtime.
+//
+// It reports unknown keys, malformed enums, defaults and enum values that don't fit the field type or the enum, and
+// xor/and groups with a single member.
+package kongcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer validates Kong struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name:     "kongcheck",
+	Doc:      "validate Kong struct tags: unknown keys, malformed enums, mistyped defaults and single-member xor/and groups",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// knownKeys are the tag keys Kong understands.
+var knownKeys = map[string]bool{
+	"aliases": true, "and": true, "arg": true, "atfile": true, "chdir": true, "cmd": true, "default": true,
+	"embed": true, "enabled": true, "enum": true, "enumfrom": true, "env": true, "envprefix": true, "errhelp": true,
+	"expand": true, "expandenv": true, "format": true, "group": true, "help": true, "hidden": true,
+	"instances": true, "local": true, "mapsep": true, "max": true, "maxcount": true, "min": true, "name": true,
+	"negatable": true, "noninterspersed": true, "optional": true, "override": true, "passthrough": true,
+	"pattern": true, "placeholder": true, "prefix": true, "quote": true, "removedin": true, "required": true,
+	"requiredif": true, "rest": true, "retry": true, "secret": true, "sep": true, "set": true, "setenv": true,
+	"short": true, "since": true, "source": true, "stability": true, "type": true, "unit": true,
+	"unknownargs": true, "xor": true, "xorprefix": true,
+}
+
+// groupMember is a field belonging to an xor or and group.
+type groupMember struct {
+	pos   token.Pos
+	field string
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	groups := map[string]map[string][]groupMember{"xor": {}, "and": {}}
+	inspect.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		for _, field := range n.(*ast.StructType).Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				continue
+			}
+			items, strict, err := parseTag(tag)
+			if err != nil {
+				pass.Reportf(field.Tag.Pos(), "malformed kong tag: %s", err)
+				continue
+			}
+			if items == nil {
+				continue
+			}
+			checkField(pass, field, items, strict)
+			name := fieldName(field)
+			for kind, members := range groups {
+				for _, value := range items[kind] {
+					for _, group := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+						members[group] = append(members[group], groupMember{field.Tag.Pos(), name})
+					}
+				}
+			}
+		}
+	})
+	for _, kind := range []string{"xor", "and"} {
+		names := make([]string, 0, len(groups[kind]))
+		for name := range groups[kind] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if members := groups[kind][name]; len(members) == 1 {
+				pass.Reportf(members[0].pos, "%s group %q only contains %s", kind, name, members[0].field)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// parseTag returns the Kong items of a struct tag, and whether every key must be a Kong key, which is the case for
+// `kong:"..."` tags. Tags without Kong keys, or typos of them, return nil items.
+func parseTag(tag string) (map[string][]string, bool, error) {
+	if s, ok := reflect.StructTag(tag).Lookup("kong"); ok {
+		if s == "-" {
+			return nil, false, nil
+		}
+		items, err := parseTagItems(s, kongChars)
+		return items, true, err
+	}
+	items, err := parseTagItems(tag, bareChars)
+	if err != nil {
+		// Tags that aren't for Kong may use any syntax.
+		return nil, false, nil //nolint:nilerr
+	}
+	for key := range items {
+		if knownKeys[key] || closestKey(key) != "" {
+			return items, false, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func checkField(pass *analysis.Pass, field *ast.Field, items map[string][]string, strict bool) {
+	pos := field.Tag.Pos()
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if knownKeys[key] {
+			continue
+		}
+		if suggestion := closestKey(key); suggestion != "" {
+			pass.Reportf(pos, "unknown kong tag key %q, did you mean %q?", key, suggestion)
+		} else if strict && key != "" {
+			pass.Reportf(pos, "unknown kong tag key %q", key)
+		}
+	}
+
+	typ := pass.TypesInfo.TypeOf(field.Type)
+	isSlice, isScalar, isMap := false, true, false
+	elem := typ
+	if typ != nil {
+		switch underlying := typ.Underlying().(type) {
+		case *types.Slice:
+			isSlice, isScalar = true, false
+			elem = underlying.Elem()
+		case *types.Pointer:
+			isScalar = false
+			elem = underlying.Elem()
+		case *types.Map:
+			isScalar, isMap = false, true
+			elem = nil
+		}
+	}
+	// Custom types, units and interpolated values can't be checked statically.
+	checkType := elem != nil && !has(items, "type") && !has(items, "unit")
+
+	enum, hasEnum := get(items, "enum")
+	dflt, hasDefault := get(items, "default")
+	var enumValues map[string]bool
+	if hasEnum && !strings.Contains(enum, "$") {
+		enumValues = map[string]bool{}
+		for _, part := range strings.Split(enum, ",") {
+			for _, value := range strings.Split(part, "|") {
+				value = strings.TrimSpace(value)
+				switch {
+				case value == "":
+					// An empty value is how an enum allows an empty default.
+					if hasDefault && dflt == "" {
+						break
+					}
+					pass.Reportf(pos, "enum %q contains an empty value", enum)
+				case enumValues[value]:
+					pass.Reportf(pos, "enum %q contains %q more than once", enum, value)
+				case checkType:
+					if err := checkValue(elem, value); err != nil {
+						pass.Reportf(pos, "enum value %q is not a valid %s: %s", value, elem, err)
+					}
+				}
+				enumValues[value] = true
+			}
+		}
+		if isScalar && !has(items, "default") && !has(items, "required") && !has(items, "arg") {
+			pass.Reportf(pos, "enum requires either a default or required")
+		}
+	}
+
+	// Map defaults are key=value pairs.
+	if !hasDefault || isMap || strings.Contains(dflt, "$") {
+		return
+	}
+	values := []string{dflt}
+	if isSlice {
+		sep := ","
+		if s, _ := get(items, "sep"); s != "" {
+			sep = s
+		} else if isFloat(elem) && strings.Contains(dflt, ";") {
+			// Slices of floats are separated by ";" with a decimal comma locale.
+			sep = ";"
+		}
+		if sep == "none" {
+			values = []string{dflt}
+		} else if dflt == "" {
+			values = nil
+		} else {
+			values = strings.Split(dflt, sep)
+		}
+	}
+	for _, value := range values {
+		if enumValues != nil && !enumValues[value] {
+			pass.Reportf(pos, "default %q is not one of the enum values %q", value, enum)
+		} else if checkType {
+			if err := checkValue(elem, value); err != nil {
+				pass.Reportf(pos, "default %q is not a valid %s: %s", value, elem, err)
+			}
+		}
+	}
+}
+
+// checkValue returns an error if "value" can't be decoded by Kong's built-in mappers into a value of type "typ".
+// Types it doesn't know how to check are accepted.
+func checkValue(typ types.Type, value string) error {
+	if named, ok := typ.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Duration" {
+			_, err := time.ParseDuration(value)
+			return err
+		}
+		// Named types may decode themselves.
+		if types.NewMethodSet(types.NewPointer(named)).Len() > 0 {
+			return nil
+		}
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok {
+		return nil
+	}
+	var err error
+	switch info := basic.Info(); {
+	case info&types.IsBoolean != 0:
+		switch strings.ToLower(value) {
+		case "true", "1", "yes", "false", "0", "no":
+		default:
+			err = fmt.Errorf("must be true, 1, yes, false, 0 or no")
+		}
+	case info&types.IsUnsigned != 0:
+		_, err = strconv.ParseUint(value, 0, bitSize(basic))
+	case info&types.IsInteger != 0:
+		_, err = strconv.ParseInt(value, 0, bitSize(basic))
+	case info&types.IsFloat != 0:
+		_, err = strconv.ParseFloat(value, bitSize(basic))
+	}
+	if numErr, ok := err.(*strconv.NumError); ok { //nolint:errorlint
+		err = numErr.Err
+	}
+	return err
+}
+
+func isFloat(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsFloat != 0
+}
+
+func bitSize(basic *types.Basic) int {
+	switch basic.Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+// closestKey returns the known key that "key" is most likely a typo of, if any.
+func closestKey(key string) string {
+	if len(key) < 3 {
+		return ""
+	}
+	for _, known := range sortedKnownKeys() {
+		if editDistance(key, known) == 1 {
+			return known
+		}
+	}
+	return ""
+}
+
+func sortedKnownKeys() []string {
+	keys := make([]string, 0, len(knownKeys))
+	for key := range knownKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of adjacent characters
+// that turn "a" into "b".
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	return types.ExprString(field.Type)
+}
+
+func has(items map[string][]string, key string) bool {
+	_, ok := items[key]
+	return ok
+}
+
+func get(items map[string][]string, key string) (string, bool) {
+	values, ok := items[key]
+	if !ok || len(values) == 0 {
+		return "", ok
+	}
+	return values[0], true
+}
@@ -0,0 +1,27 @@
+package kongcheck
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+// TestKnownKeys guards against Kong learning tag keys that kongcheck doesn't know about.
+func TestKnownKeys(t *testing.T) {
+	source, err := os.ReadFile("../tag.go")
+	if err != nil {
+		t.Skip("Kong's tag.go is not available")
+	}
+	re := regexp.MustCompile(`t\.(?:Has|Get|GetAll|GetSep|GetRune)\("([a-z]+)"`)
+	for _, match := range re.FindAllStringSubmatch(string(source), -1) {
+		if !knownKeys[match[1]] {
+			t.Errorf("tag key %q is missing from knownKeys", match[1])
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package a
+
+import "time"
+
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error { return nil }
+
+type CLI struct {
+	Valid    string        `help:"A flag." enum:"a,b|bee" default:"b"`
+	Timeout  time.Duration `default:"5s"`
+	Retries  int           `default:"3" min:"0"`
+	Level    Level         `default:"loud"`
+	Size     int           `default:"1MiB" unit:"bytes"`
+	Vars     string        `default:"${home}" help:"Interpolated."`
+	Tags     []string      `enum:"x,y" default:"x,y"`
+	JSONOnly string        `json:"only"`
+	Ignored  string        `kong:"-"`
+	Grouped1 bool          `xor:"format"`
+	Grouped2 bool          `xor:"format"`
+
+	Typo     string        `hlep:"Typo."`                      // want `unknown kong tag key "hlep", did you mean "help"\?`
+	Strict   string        `kong:"help='x',bogus"`             // want `unknown kong tag key "bogus"`
+	Count    int           `default:"three"`                   // want `default "three" is not a valid int: invalid syntax`
+	Small    uint8         `default:"300"`                     // want `default "300" is not a valid uint8: value out of range`
+	Wait     time.Duration `default:"5 s"`                     // want `default "5 s" is not a valid time.Duration: .*`
+	Flag     bool          `default:"maybe"`                   // want `default "maybe" is not a valid bool: must be true, 1, yes, false, 0 or no`
+	Colour   string        `enum:"red,,blue" default:"red"`    // want `enum "red,,blue" contains an empty value`
+	Shape    string        `enum:"box,box" default:"box"`      // want `enum "box,box" contains "box" more than once`
+	Mode     string        `enum:"fast,slow" default:"medium"` // want `default "medium" is not one of the enum values "fast,slow"`
+	NoDflt   string        `enum:"fast,slow"`                  // want `enum requires either a default or required`
+	Sizes    []int         `enum:"1,two" default:"1"`          // want `enum value "two" is not a valid int: invalid syntax`
+	Modes    []string      `enum:"a,b" default:"a,c"`          // want `default "c" is not one of the enum values "a,b"`
+	Alone    bool          `xor:"lonely"`                      // want `xor group "lonely" only contains Alone`
+	Together bool          `and:"pair"`                        // want `and group "pair" only contains Together`
+	Broken   string        `kong:"help='unterminated"`         // want `malformed kong tag: .*`
+}
+
+type Locale struct {
+	Ratios  []float64         `default:"0.5;1.5"`
+	Labels  map[string]string `enum:"a,b" default:"x=a"`
+	Empty   string            `enum:"one,two," default:""`
+	Pointer *int              `default:"x"` // want `default "x" is not a valid int: invalid syntax`
+}
@@ -0,0 +1,143 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintIssue is a grammar smell reported by Lint.
+type LintIssue struct {
+	// Path is the full path of the node the issue was found on.
+	Path string
+	// Message describes the issue.
+	Message string
+}
+
+func (l LintIssue) String() string {
+	return l.Path + ": " + l.Message
+}
+
+// Lint analyses a Kong model and reports grammar smells.
+//
+// None of the reported issues prevent a parser from being constructed, but they usually indicate a mistake in the
+// grammar. Lint is intended to be called from tests, eg.
+//
+//	parser := kong.Must(&cli)
+//	assert.Zero(t, kong.Lint(parser.Model))
+//
+// The following are reported:
+//
+//   - commands or command aliases that can never be reached
+//   - flags that are ambiguous with another flag in the same scope once case and separators are ignored
+//   - defaults that are not part of their enum
+//   - required flags on default commands, or required flags with a default
+//   - xor groups with a single member
+func Lint(app *Application) []LintIssue {
+	issues := []LintIssue{}
+	_ = Visit(app, func(v Visitable, next Next) error {
+		node, ok := v.(*Node)
+		if !ok {
+			if app, ok := v.(*Application); ok {
+				node = app.Node
+			} else {
+				return next(nil)
+			}
+		}
+		report := func(format string, args ...any) {
+			issues = append(issues, LintIssue{Path: node.FullPath(), Message: fmt.Sprintf(format, args...)})
+		}
+		lintCommands(node, report)
+		lintFlags(node, report)
+		return next(nil)
+	})
+	return issues
+}
+
+func lintCommands(node *Node, report func(format string, args ...any)) {
+	names := map[string]bool{}
+	for _, child := range node.Children {
+		if child.Type == CommandNode {
+			names[child.Name] = true
+			if strings.HasPrefix(child.Name, "-") {
+				report("command %q is unreachable as it will be parsed as a flag", child.Name)
+			}
+		}
+	}
+	aliases := map[string]string{}
+	for _, child := range node.Children {
+		for _, alias := range child.Aliases {
+			switch {
+			case names[alias]:
+				report("alias %q of command %q is unreachable as it is shadowed by command %q", alias, child.Name, alias)
+			case aliases[alias] != "":
+				report("alias %q of command %q is unreachable as it is shadowed by command %q", alias, child.Name, aliases[alias])
+			default:
+				aliases[alias] = child.Name
+			}
+		}
+	}
+}
+
+func lintFlags(node *Node, report func(format string, args ...any)) {
+	// Flags from ancestors are visible, so check for ambiguity against all of them.
+	seen := map[string]string{}
+	scope := []*Node{}
+	for n := node; n != nil; n = n.Parent {
+		scope = append([]*Node{n}, scope...)
+	}
+	for _, n := range scope {
+		for _, flag := range n.Flags {
+			for _, name := range append([]string{flag.Name}, flag.Aliases...) {
+				key := normaliseFlagName(name)
+				if other, ok := seen[key]; ok && other != flag.Name {
+					// Only report on the node declaring the flag, not on all its descendants.
+					if n == node {
+						report("flag --%s is ambiguous with --%s", name, other)
+					}
+					continue
+				}
+				seen[key] = flag.Name
+			}
+		}
+	}
+
+	xors := map[string][]*Flag{}
+	xorOrder := []string{}
+	for _, flag := range node.Flags {
+		if flag.HasDefault && flag.Enum != "" {
+			enums := flag.EnumMap()
+			defaults := []string{flag.Default}
+			if flag.IsSlice() {
+				defaults = splitValues(flag.Default, flag.Tag.Separator, flag.Tag.Quote)
+			}
+			for _, def := range defaults {
+				if !enums[def] {
+					report("default %q of --%s is not one of its enum values %q", flag.Redact(def), flag.Name, flag.Enum)
+				}
+			}
+		}
+		if flag.Required {
+			if flag.HasDefault {
+				report("required flag --%s has a default value, so required has no effect", flag.Name)
+			}
+			if node.Parent != nil && node.Parent.DefaultCmd == node {
+				report("required flag --%s is on default command %q, which can be selected implicitly", flag.Name, node.Name)
+			}
+		}
+		for _, xor := range flag.Xor {
+			if _, ok := xors[xor]; !ok {
+				xorOrder = append(xorOrder, xor)
+			}
+			xors[xor] = append(xors[xor], flag)
+		}
+	}
+	for _, xor := range xorOrder {
+		if flags := xors[xor]; len(flags) == 1 {
+			report("xor group %q only contains --%s", xor, flags[0].Name)
+		}
+	}
+}
+
+func normaliseFlagName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(name))
+}
@@ -0,0 +1,55 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestLintClean(t *testing.T) {
+	var cli struct {
+		Level string `enum:"debug,info" default:"info"`
+		One   bool   `xor:"a"`
+		Two   bool   `xor:"a"`
+		Cmd   struct {
+			Name string `required:""`
+		} `cmd:"" aliases:"c"`
+	}
+	p := mustNew(t, &cli)
+	assert.Zero(t, kong.Lint(p.Model))
+}
+
+func TestLint(t *testing.T) {
+	var cli struct {
+		DryRun bool
+		Dryrun bool     `name:"dryrun"`
+		Level  string   `enum:"debug,info" default:"warn"`
+		Levels []string `enum:"debug,info" default:"debug,warn"`
+		Lonely bool     `xor:"single"`
+		Must   string   `required:"" default:"x"`
+
+		Serve struct {
+			Port int `required:""`
+		} `cmd:"" default:"1"`
+		Status struct{} `cmd:"" aliases:"serve"`
+		Stat   struct{} `cmd:"" aliases:"st"`
+		State  struct{} `cmd:"" aliases:"st"`
+	}
+	p := mustNew(t, &cli)
+	issues := []string{}
+	for _, issue := range kong.Lint(p.Model) {
+		issues = append(issues, issue.String())
+	}
+	assert.Equal(t, []string{
+		`test: alias "serve" of command "status" is unreachable as it is shadowed by command "serve"`,
+		`test: alias "st" of command "state" is unreachable as it is shadowed by command "stat"`,
+		`test: flag --dryrun is ambiguous with --dry-run`,
+		`test: default "warn" of --level is not one of its enum values "debug,info"`,
+		`test: default "warn" of --levels is not one of its enum values "debug,info"`,
+		`test: required flag --must has a default value, so required has no effect`,
+		`test: xor group "single" only contains --lonely`,
+		`test serve: required flag --port is on default command "serve", which can be selected implicitly`,
+	}, issues)
+}
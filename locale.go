@@ -0,0 +1,70 @@
+package kong
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Languages whose numbers use a decimal comma, eg. "0,5", grouping digits with "." or spaces.
+var decimalCommaLanguages = map[string]bool{
+	"bg": true, "cs": true, "da": true, "de": true, "el": true, "es": true, "fi": true, "fr": true, "hr": true,
+	"hu": true, "id": true, "it": true, "lt": true, "lv": true, "nb": true, "nl": true, "nn": true, "no": true,
+	"pl": true, "pt": true, "ro": true, "ru": true, "sk": true, "sl": true, "sr": true, "sv": true, "tr": true,
+	"uk": true, "vi": true,
+}
+
+// Languages whose numbers use a decimal point, eg. "0.5".
+var decimalPointLanguages = map[string]bool{
+	"ar": true, "en": true, "he": true, "hi": true, "ja": true, "ko": true, "th": true, "zh": true,
+}
+
+// Locale parses floating point flags, and formats their defaults in help, according to the conventions of the
+// language of the BCP 47 tag, eg. "de" or "fr-CH".
+//
+// In locales with a decimal comma, "0,5" is parsed as 0.5, and "." and spaces are accepted as digit grouping, eg.
+// "1.000,5". Values without a comma, such as those in "default" tags, are parsed as Go floats. As "," then
+// separates decimals, slices of floats are separated by ";" unless tagged with "sep".
+func Locale(tag string) Option {
+	return OptionFunc(func(k *Kong) error {
+		language, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(tag, "_", "-")), "-")
+		switch {
+		case decimalCommaLanguages[language]:
+			k.decimalComma = true
+		case decimalPointLanguages[language]:
+			k.decimalComma = false
+			return nil
+		default:
+			return fmt.Errorf("unsupported locale %q", tag)
+		}
+		k.registry.RegisterKind(reflect.Float32, decimalCommaFloatDecoder(32))
+		k.registry.RegisterKind(reflect.Float64, decimalCommaFloatDecoder(64))
+		if k.typeFormatters == nil {
+			k.typeFormatters = map[reflect.Type]func(reflect.Value) string{}
+		}
+		for _, typ := range []reflect.Type{reflect.TypeOf(float32(0)), reflect.TypeOf(float64(0))} {
+			bits := typ.Bits()
+			if _, ok := k.typeFormatters[typ]; !ok {
+				k.typeFormatters[typ] = func(value reflect.Value) string {
+					return strings.Replace(strconv.FormatFloat(value.Float(), 'g', -1, bits), ".", ",", 1)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// decimalCommaFloatDecoder decodes floats written with a decimal comma, and optionally "." or spaces grouping digits.
+func decimalCommaFloatDecoder(bits int) MapperFunc {
+	decoder := floatDecoder(bits)
+	return func(ctx *DecodeContext, target reflect.Value) error {
+		token := ctx.Scan.Peek()
+		if s, ok := token.Value.(string); ok && strings.Contains(s, ",") {
+			s = strings.NewReplacer(".", "", " ", "", "\u00a0", "", "\u202f", "", ",", ".").Replace(s)
+			ctx.Scan.Pop()
+			ctx.Scan.PushTyped(s, token.Type)
+		}
+		return decoder(ctx, target)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 var (
@@ -41,6 +42,14 @@ func (r *DecodeContext) WithScanner(scan *Scanner) *DecodeContext {
 	}
 }
 
+func (r *DecodeContext) errorf(message Message, args ...any) error {
+	var messages catalog
+	if r.Value != nil {
+		messages = r.Value.messages
+	}
+	return messages.errorf(message, args...)
+}
+
 // MapperValue may be implemented by fields in order to provide custom mapping.
 // Mappers may additionally implement PlaceHolderProvider to provide custom placeholder text.
 type MapperValue interface {
@@ -290,6 +299,8 @@ func (r *Registry) RegisterDefaults() *Registry {
 		RegisterName("existingdir", existingDirMapper(r)).
 		RegisterName("counter", counterMapper()).
 		RegisterName("filecontent", fileContentMapper(r)).
+		RegisterName("password", passwordMapper()).
+		RegisterName("percent", percentMapper()).
 		RegisterKind(reflect.Ptr, ptrMapper{r})
 }
 
@@ -309,14 +320,14 @@ func (boolMapper) Decode(ctx *DecodeContext, target reflect.Value) error {
 				target.SetBool(false)
 
 			default:
-				return fmt.Errorf("bool value must be true, 1, yes, false, 0 or no but got %q", v)
+				return ctx.errorf(MessageBool, v)
 			}
 
 		case bool:
 			target.SetBool(v)
 
 		default:
-			return fmt.Errorf("expected bool but got %q (%T)", token.Value, token.Value)
+			return ctx.errorf(MessageExpectedType, "bool", token.Value, token.Value)
 		}
 	} else {
 		target.SetBool(true)
@@ -336,18 +347,45 @@ func durationDecoder() MapperFunc {
 		case string:
 			d, err = time.ParseDuration(v)
 			if err != nil {
-				return fmt.Errorf("expected duration but got %q: %v", v, err)
+				return ctx.errorf(MessageDuration, v, err)
 			}
 		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
 			d = reflect.ValueOf(v).Convert(reflect.TypeOf(time.Duration(0))).Interface().(time.Duration) //nolint: forcetypeassert
 		default:
-			return fmt.Errorf("expected duration but got %q", v)
+			return ctx.errorf(MessageExpectedType, "duration", v, v)
 		}
 		target.Set(reflect.ValueOf(d))
 		return nil
 	}
 }
 
+// builtinFormatter returns a formatter rendering values of "typ" in the form they are given on the command-line,
+// eg. "1h30m" for a time.Duration rather than "1h30m0s", or nil if %v suffices.
+func builtinFormatter(typ reflect.Type, tag *Tag) func(reflect.Value) string {
+	switch typ {
+	case reflect.TypeOf(time.Duration(0)):
+		return func(v reflect.Value) string {
+			s := time.Duration(v.Int()).String()
+			if strings.HasSuffix(s, "m0s") {
+				s = s[:len(s)-2]
+			}
+			if strings.HasSuffix(s, "h0m") {
+				s = s[:len(s)-2]
+			}
+			return s
+		}
+	case reflect.TypeOf(time.Time{}):
+		layout := time.RFC3339
+		if tag.Format != "" {
+			layout = tag.Format
+		}
+		return func(v reflect.Value) string {
+			return v.Interface().(time.Time).Format(layout) //nolint: forcetypeassert
+		}
+	}
+	return nil
+}
+
 func timeDecoder() MapperFunc {
 	return func(ctx *DecodeContext, target reflect.Value) error {
 		format := time.RFC3339
@@ -385,11 +423,11 @@ func intDecoder(bits int) MapperFunc { //nolint: dupl
 			sv = fmt.Sprintf("%0.f", v)
 
 		default:
-			return fmt.Errorf("expected an int but got %q (%T)", t, t.Value)
+			return ctx.errorf(MessageExpectedType, "an int", t, t.Value)
 		}
 		n, err := strconv.ParseInt(sv, 0, bits)
 		if err != nil {
-			return fmt.Errorf("expected a valid %d bit int but got %q", bits, sv)
+			return ctx.errorf(MessageInt, bits, sv)
 		}
 		target.SetInt(n)
 		return nil
@@ -414,11 +452,11 @@ func uintDecoder(bits int) MapperFunc { //nolint: dupl
 			sv = fmt.Sprintf("%0.f", v)
 
 		default:
-			return fmt.Errorf("expected an int but got %q (%T)", t, t.Value)
+			return ctx.errorf(MessageExpectedType, "an int", t, t.Value)
 		}
 		n, err := strconv.ParseUint(sv, 0, bits)
 		if err != nil {
-			return fmt.Errorf("expected a valid %d bit uint but got %q", bits, sv)
+			return ctx.errorf(MessageUint, bits, sv)
 		}
 		target.SetUint(n)
 		return nil
@@ -435,7 +473,7 @@ func floatDecoder(bits int) MapperFunc {
 		case string:
 			n, err := strconv.ParseFloat(v, bits)
 			if err != nil {
-				return fmt.Errorf("expected a float but got %q (%T)", t, t.Value)
+				return ctx.errorf(MessageExpectedType, "a float", t, t.Value)
 			}
 			target.SetFloat(n)
 
@@ -449,7 +487,7 @@ func floatDecoder(bits int) MapperFunc {
 			target.Set(reflect.ValueOf(v))
 
 		default:
-			return fmt.Errorf("expected an int but got %q (%T)", t, t.Value)
+			return ctx.errorf(MessageExpectedType, "an int", t, t.Value)
 		}
 		return nil
 	}
@@ -461,17 +499,17 @@ func mapDecoder(r *Registry) MapperFunc {
 			target.Set(reflect.MakeMap(target.Type()))
 		}
 		el := target.Type()
-		mapsep := ctx.Value.Tag.MapSep
+		mapsep := ctx.Value.Tag.MapSeparator
 		var childScanner *Scanner
 		if ctx.Value.Flag != nil {
 			t := ctx.Scan.Pop()
 			// If decoding a flag, we need an value.
 			if t.IsEOL() {
-				return fmt.Errorf("missing value, expecting \"<key>=<value>%c...\"", mapsep)
+				return ctx.errorf(MessageMissingValue, "<key>=<value>"+mapsep+"...")
 			}
 			switch v := t.Value.(type) {
 			case string:
-				childScanner = ScanAsType(t.Type, SplitEscaped(v, mapsep)...)
+				childScanner = ScanAsType(t.Type, splitValues(v, mapsep, ctx.Value.Tag.Quote)...)
 
 			case []map[string]any:
 				for _, m := range v {
@@ -486,11 +524,10 @@ func mapDecoder(r *Registry) MapperFunc {
 				return jsonTranscode(v, target.Addr().Interface())
 
 			default:
-				return fmt.Errorf("invalid map value %q (of type %T)", t, t.Value)
+				return ctx.errorf(MessageExpectedType, "a map", t, t.Value)
 			}
 		} else {
-			tokens := ctx.Scan.PopWhile(func(t Token) bool { return t.IsValue() })
-			childScanner = ScanFromTokens(tokens...)
+			childScanner = ctx.Scan.PopValues()
 		}
 		for !childScanner.Peek().IsEOL() {
 			var token string
@@ -500,7 +537,7 @@ func mapDecoder(r *Registry) MapperFunc {
 			}
 			parts := strings.SplitN(token, "=", 2)
 			if len(parts) != 2 {
-				return fmt.Errorf("expected \"<key>=<value>\" but got %q", token)
+				return ctx.errorf(MessageMapEntry, token)
 			}
 			key, value := parts[0], parts[1]
 
@@ -517,14 +554,14 @@ func mapDecoder(r *Registry) MapperFunc {
 			keyDecoder := r.ForNamedType(keyTypeName, el.Key())
 			keyValue := reflect.New(el.Key()).Elem()
 			if err := keyDecoder.Decode(ctx.WithScanner(keyScanner), keyValue); err != nil {
-				return fmt.Errorf("invalid map key %q", key)
+				return ctx.errorf(MessageMapKey, key)
 			}
 
 			valueScanner := ScanAsType(FlagValueToken, value)
 			valueDecoder := r.ForNamedType(valueTypeName, el.Elem())
 			valueValue := reflect.New(el.Elem()).Elem()
 			if err := valueDecoder.Decode(ctx.WithScanner(valueScanner), valueValue); err != nil {
-				return fmt.Errorf("invalid map value %q", value)
+				return ctx.errorf(MessageMapValue, value)
 			}
 
 			target.SetMapIndex(keyValue, valueValue)
@@ -536,17 +573,17 @@ func mapDecoder(r *Registry) MapperFunc {
 func sliceDecoder(r *Registry) MapperFunc {
 	return func(ctx *DecodeContext, target reflect.Value) error {
 		el := target.Type().Elem()
-		sep := ctx.Value.Tag.Sep
+		sep := ctx.Value.Tag.Separator
 		var childScanner *Scanner
 		if ctx.Value.Flag != nil {
 			t := ctx.Scan.Pop()
 			// If decoding a flag, we need a value.
 			if t.IsEOL() {
-				return fmt.Errorf("missing value, expecting \"<arg>%c...\"", sep)
+				return ctx.errorf(MessageMissingValue, "<arg>"+sep+"...")
 			}
 			switch v := t.Value.(type) {
 			case string:
-				childScanner = ScanAsType(t.Type, SplitEscaped(v, sep)...)
+				childScanner = ScanAsType(t.Type, splitValues(v, sep, ctx.Value.Tag.Quote)...).AllowNegativeNumbers(ctx.Scan.allowNegativeNumbers)
 
 			case []any:
 				return jsonTranscode(v, target.Addr().Interface())
@@ -556,8 +593,7 @@ func sliceDecoder(r *Registry) MapperFunc {
 				return jsonTranscode(v, target.Addr().Interface())
 			}
 		} else {
-			tokens := ctx.Scan.PopWhile(func(t Token) bool { return t.IsValue() })
-			childScanner = ScanFromTokens(tokens...)
+			childScanner = ctx.Scan.PopValues()
 		}
 		childDecoder := r.ForNamedType(ctx.Value.Tag.Type, el)
 		if childDecoder == nil {
@@ -595,7 +631,7 @@ func pathMapper(r *Registry) MapperFunc {
 			return err
 		}
 		if path != "-" {
-			path = ExpandPath(path)
+			path = expandValuePath(ctx, path)
 		}
 		target.SetString(path)
 		return nil
@@ -616,7 +652,7 @@ func fileMapper(r *Registry) MapperFunc {
 		if path == "-" {
 			file = os.Stdin
 		} else {
-			path = ExpandPath(path)
+			path = expandValuePath(ctx, path)
 			file, err = os.Open(path) //nolint: gosec
 			if err != nil {
 				return err
@@ -649,13 +685,13 @@ func existingFileMapper(r *Registry) MapperFunc {
 		}
 
 		if path != "-" {
-			path = ExpandPath(path)
+			path = expandValuePath(ctx, path)
 			stat, err := os.Stat(path)
 			if err != nil {
 				return err
 			}
 			if stat.IsDir() {
-				return fmt.Errorf("%q exists but is a directory", path)
+				return ctx.errorf(MessageIsDirectory, path)
 			}
 		}
 		target.SetString(path)
@@ -684,13 +720,13 @@ func existingDirMapper(r *Registry) MapperFunc {
 			return nil
 		}
 
-		path = ExpandPath(path)
+		path = expandValuePath(ctx, path)
 		stat, err := os.Stat(path)
 		if err != nil {
 			return err
 		}
 		if !stat.IsDir() {
-			return fmt.Errorf("%q exists but is not a directory", path)
+			return ctx.errorf(MessageNotDirectory, path)
 		}
 		target.SetString(path)
 		return nil
@@ -717,14 +753,14 @@ func fileContentMapper(r *Registry) MapperFunc {
 
 		var data []byte
 		if path != "-" {
-			path = ExpandPath(path)
+			path = expandValuePath(ctx, path)
 			data, err = os.ReadFile(path) //nolint:gosec
 		} else {
 			data, err = io.ReadAll(os.Stdin)
 		}
 		if err != nil {
 			if info, statErr := os.Stat(path); statErr == nil && info.IsDir() {
-				return fmt.Errorf("%q exists but is a directory: %w", path, err)
+				return ctx.errorf(MessageIsDirectory, path)
 			}
 			return err
 		}
@@ -780,7 +816,7 @@ func counterMapper() MapperFunc {
 			case string:
 				n, err := strconv.ParseInt(v, 10, 64)
 				if err != nil {
-					return fmt.Errorf("expected a counter but got %q (%T)", t, t.Value)
+					return ctx.errorf(MessageExpectedType, "a counter", t, t.Value)
 				}
 				target.SetInt(n)
 
@@ -788,7 +824,7 @@ func counterMapper() MapperFunc {
 				target.Set(reflect.ValueOf(v))
 
 			default:
-				return fmt.Errorf("expected a counter but got %q (%T)", t, t.Value)
+				return ctx.errorf(MessageExpectedType, "a counter", t, t.Value)
 			}
 			return nil
 		}
@@ -810,6 +846,34 @@ func counterMapper() MapperFunc {
 	}
 }
 
+// percentMapper decodes a ratio between 0 and 1, given either as a percentage such as "35%" or as a fraction
+// such as "0.35".
+func percentMapper() MapperFunc {
+	return func(ctx *DecodeContext, target reflect.Value) error {
+		if target.Kind() != reflect.Float32 && target.Kind() != reflect.Float64 {
+			return fmt.Errorf("type:\"percent\" must be used with a float field")
+		}
+		t, err := ctx.Scan.PopValue("percent")
+		if err != nil {
+			return err
+		}
+		raw := fmt.Sprintf("%v", t.Value)
+		number, percent := strings.CutSuffix(strings.TrimSpace(raw), "%")
+		n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil {
+			return ctx.errorf(MessagePercent, raw)
+		}
+		if percent {
+			n /= 100
+		}
+		if n < 0 || n > 1 {
+			return ctx.errorf(MessagePercentRange, raw)
+		}
+		target.SetFloat(n)
+		return nil
+	}
+}
+
 func urlMapper() MapperFunc {
 	return func(ctx *DecodeContext, target reflect.Value) error {
 		var urlStr string
@@ -835,28 +899,57 @@ func SplitEscaped(s string, sep rune) (out []string) {
 	if sep == -1 {
 		return []string{s}
 	}
-	escaped := false
+	return splitValues(s, string(sep), false)
+}
+
+// splitValues splits "s" on "sep", which may be several characters, unless "sep" is empty.
+//
+// A separator preceded by a \ is not split on. If "quoted" is true, separators within single or double quotes are
+// not split on either, and the quotes are removed from elements that are entirely quoted.
+func splitValues(s, sep string, quoted bool) (out []string) {
+	if sep == "" {
+		return []string{s}
+	}
+	unquote := func(token string) string {
+		if quoted && len(token) >= 2 && (token[0] == '"' || token[0] == '\'') && token[len(token)-1] == token[0] {
+			return token[1 : len(token)-1]
+		}
+		return token
+	}
 	token := ""
-	for i, ch := range s {
+	var quote byte
+	for i := 0; i < len(s); {
 		switch {
-		case escaped:
-			if ch != sep {
-				token += `\`
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+			token += s[i : i+1]
+			i++
+		case s[i] == '\\' && i < len(s)-1:
+			if strings.HasPrefix(s[i+1:], sep) {
+				token += sep
+				i += 1 + len(sep)
+			} else {
+				_, size := utf8.DecodeRuneInString(s[i+1:])
+				token += s[i : i+1+size]
+				i += 1 + size
 			}
-			token += string(ch)
-			escaped = false
-		case ch == '\\' && i < len(s)-1:
-			escaped = true
-		case ch == sep && !escaped:
-			out = append(out, token)
+		case quoted && (s[i] == '"' || s[i] == '\''):
+			quote = s[i]
+			token += s[i : i+1]
+			i++
+		case strings.HasPrefix(s[i:], sep):
+			out = append(out, unquote(token))
 			token = ""
-			escaped = false
+			i += len(sep)
 		default:
-			token += string(ch)
+			token += s[i : i+1]
+			i++
 		}
 	}
 	if token != "" {
-		out = append(out, token)
+		out = append(out, unquote(token))
 	}
 	return
 }
@@ -892,7 +985,7 @@ func (f *NamedFileContentFlag) Decode(ctx *DecodeContext) error { //nolint: revi
 	filename = ExpandPath(filename)
 	data, err := os.ReadFile(filename) //nolint: gosec
 	if err != nil {
-		return fmt.Errorf("failed to open %q: %v", filename, err)
+		return ctx.errorf(MessageOpenFile, filename, err)
 	}
 	f.Contents = data
 	f.Filename = filename
@@ -916,7 +1009,7 @@ func (f *FileContentFlag) Decode(ctx *DecodeContext) error { //nolint: revive
 	filename = ExpandPath(filename)
 	data, err := os.ReadFile(filename) //nolint: gosec
 	if err != nil {
-		return fmt.Errorf("failed to open %q: %v", filename, err)
+		return ctx.errorf(MessageOpenFile, filename, err)
 	}
 	*f = data
 	return nil
@@ -4,9 +4,12 @@
 package kong_test
 
 import (
+	"os/user"
 	"testing"
 
 	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
 )
 
 func TestPathMapper(t *testing.T) {
@@ -23,3 +26,46 @@ func TestPathMapper(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "-", cli.Path)
 }
+
+func TestPathMapperExpand(t *testing.T) {
+	u, err := user.Current()
+	assert.NoError(t, err)
+	t.Setenv("KONG_TEST_DIR", "/srv/data")
+	var cli struct {
+		Path string `type:"path" expand:""`
+		Raw  string `type:"path"`
+	}
+	p := mustNew(t, &cli)
+
+	_, err = p.Parse([]string{"--path=~"})
+	assert.NoError(t, err)
+	assert.Equal(t, u.HomeDir, cli.Path)
+
+	_, err = p.Parse([]string{"--path=~" + u.Username + "/conf"})
+	assert.NoError(t, err)
+	assert.Equal(t, u.HomeDir+"/conf", cli.Path)
+
+	_, err = p.Parse([]string{"--path=$KONG_TEST_DIR/a", "--raw=/x/$KONG_TEST_DIR"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/srv/data/a", cli.Path)
+	assert.Equal(t, "/x/$KONG_TEST_DIR", cli.Raw)
+
+	_, err = p.Parse([]string{"--path=%KONG_TEST_DIR%/b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/srv/data/b", cli.Path)
+
+	_, err = p.Parse([]string{"--path=/%KONG_TEST_UNSET%"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/%KONG_TEST_UNSET%", cli.Path)
+}
+
+func TestExpandPathsOption(t *testing.T) {
+	var cli struct {
+		Dir string `type:"existingdir"`
+	}
+	p := mustNew(t, &cli, kong.ExpandPaths())
+	t.Setenv("KONG_TEST_DIR", "/")
+	_, err := p.Parse([]string{"--dir=${KONG_TEST_DIR}"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/", cli.Dir)
+}
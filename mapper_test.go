@@ -144,6 +144,25 @@ func TestSplitEscaped(t *testing.T) {
 	assert.Equal(t, []string{"a,b,c"}, kong.SplitEscaped(`a,b,c`, -1))
 }
 
+func TestMultiCharacterSeparators(t *testing.T) {
+	var cli struct {
+		Hosts  []string          `sep:"::"`
+		Labels map[string]string `mapsep:" && "`
+		Links  []string          `quote:""`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"--hosts=a:1::b:2\\::c", "--labels=x=1 && y=2", `--links="http://x/?a=1,2",http://y/,'a,b'`})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a:1", "b:2::c"}, cli.Hosts)
+	assert.Equal(t, map[string]string{"x": "1", "y": "2"}, cli.Labels)
+	assert.Equal(t, []string{"http://x/?a=1,2", "http://y/", "a,b"}, cli.Links)
+	assert.Equal(t, `HOSTS::...`, p.Model.Flags[1].FormatPlaceHolder())
+
+	_, err = p.Parse([]string{`--links=select 'a,b' from t,"x"`})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"select 'a,b' from t", "x"}, cli.Links)
+}
+
 func TestJoinEscaped(t *testing.T) {
 	assert.Equal(t, `a,b`, kong.JoinEscaped([]string{"a", "b"}, ','))
 	assert.Equal(t, `a\,b,c`, kong.JoinEscaped([]string{`a,b`, `c`}, ','))
@@ -277,6 +296,91 @@ func TestFileContentFlag(t *testing.T) {
 	assert.Equal(t, []byte("hello world"), []byte(cli.File))
 }
 
+func TestAtFileTag(t *testing.T) {
+	var cli struct {
+		Token string `atfile:""`
+		Name  string
+	}
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("s3cret\n"), 0600))
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"--token", "@" + path, "--name=@" + path})
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", cli.Token)
+	assert.Equal(t, "@"+path, cli.Name)
+
+	_, err = p.Parse([]string{"--token=@@literal"})
+	assert.NoError(t, err)
+	assert.Equal(t, "@literal", cli.Token)
+
+	_, err = p.Parse([]string{"--token=@" + filepath.Join(t.TempDir(), "missing")})
+	assert.Error(t, err)
+
+	var bad struct {
+		Count int `atfile:""`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, "<anonymous struct>.Count: atfile only makes sense for strings")
+}
+
+func TestUnitTag(t *testing.T) {
+	var cli struct {
+		Interval int64   `unit:"ms" default:"250"`
+		Limit    uint    `unit:"MiB"`
+		Ratio    float64 `unit:"s"`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"--interval=2s", "--limit=1GiB", "--ratio=1500ms"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000), cli.Interval)
+	assert.Equal(t, uint(1024), cli.Limit)
+	assert.Equal(t, 1.5, cli.Ratio)
+
+	_, err = p.Parse([]string{"--limit=3"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(250), cli.Interval)
+	assert.Equal(t, uint(3), cli.Limit)
+
+	_, err = p.Parse([]string{"--interval=1500us"})
+	assert.EqualError(t, err, "--interval: \"1500us\" is not a whole number of ms that fits in int64")
+	_, err = p.Parse([]string{"--interval=2KB"})
+	assert.EqualError(t, err, "--interval: unknown unit \"KB\" in \"2KB\", expected one of ns,us,µs,ms,s,m,h")
+	_, err = p.Parse([]string{"--interval=1e30h"})
+	assert.EqualError(t, err, "--interval: \"1e30h\" is not a whole number of ms that fits in int64")
+	_, err = p.Parse([]string{"--interval=-1e30h"})
+	assert.EqualError(t, err, "--interval: \"-1e30h\" is not a whole number of ms that fits in int64")
+	_, err = p.Parse([]string{"--limit=1e30TiB"})
+	assert.EqualError(t, err, "--limit: \"1e30TiB\" is not a whole number of MiB that fits in uint")
+
+	assert.Equal(t, "NMiB", p.Model.Flags[2].FormatPlaceHolder())
+
+	var bad struct {
+		Name string `unit:"ms"`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, "<anonymous struct>.Name: unit only makes sense for numbers")
+}
+
+func TestPercentMapper(t *testing.T) {
+	var cli struct {
+		Sample float64 `type:"percent" default:"10%"`
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.1, cli.Sample)
+	_, err = p.Parse([]string{"--sample=35%"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.35, cli.Sample)
+	_, err = p.Parse([]string{"--sample=0.5"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, cli.Sample)
+	_, err = p.Parse([]string{"--sample=150%"})
+	assert.EqualError(t, err, "--sample: expected a value between 0% and 100% but got \"150%\"")
+	_, err = p.Parse([]string{"--sample=lots"})
+	assert.EqualError(t, err, "--sample: expected a percentage such as 35% or a ratio such as 0.35 but got \"lots\"")
+}
+
 func TestNamedFileContentFlag(t *testing.T) {
 	var cli struct {
 		File kong.NamedFileContentFlag
@@ -807,3 +911,60 @@ func TestDecode(t *testing.T) {
 
 	assert.Equal(t, c.Foo.Bar, "baz")
 }
+
+func TestEnumAliases(t *testing.T) {
+	var cli struct {
+		Format  string            `enum:"json,yaml|yml,table" default:"json" help:"Output format (${enum})."`
+		Formats []string          `enum:"json,yaml|yml" default:"yml"`
+		ByName  map[string]string `enum:"json,yaml|yml"`
+	}
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Writers(w, w), kong.Exit(func(int) {}))
+	_, err := p.Parse([]string{"--format=yml", "--formats=json,yml", "--by-name=a=yml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "yaml", cli.Format)
+	assert.Equal(t, []string{"json", "yaml"}, cli.Formats)
+	assert.Equal(t, map[string]string{"a": "yaml"}, cli.ByName)
+
+	_, err = p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"yaml"}, cli.Formats)
+
+	_, err = p.Parse([]string{"--format=xml"})
+	assert.EqualError(t, err, `--format must be one of "json","yaml","table" but got "xml"`)
+
+	ctx, err := kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.Contains(t, w.String(), "Output format (json,yaml,table).")
+}
+
+func TestLocale(t *testing.T) {
+	var cli struct {
+		Ratio  float64   `default:"0.5"`
+		Scale  float32   `default:"1.25"`
+		Ratios []float64 `default:"0.5;1.5"`
+	}
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Locale("de-DE"), kong.Writers(w, w), kong.Exit(func(int) {}))
+	_, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, cli.Ratio)
+	assert.Equal(t, float32(1.25), cli.Scale)
+	assert.Equal(t, []float64{0.5, 1.5}, cli.Ratios)
+
+	_, err = p.Parse([]string{"--ratio=1.000,5", "--scale", "2,5", "--ratios=0,25;3"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1000.5, cli.Ratio)
+	assert.Equal(t, float32(2.5), cli.Scale)
+	assert.Equal(t, []float64{0.25, 3}, cli.Ratios)
+
+	ctx, err := kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.Contains(t, w.String(), "--ratio=0,5")
+	assert.Contains(t, w.String(), "--scale=1,25")
+
+	_, err = kong.New(&cli, kong.Locale("xx"))
+	assert.EqualError(t, err, `unsupported locale "xx"`)
+}
@@ -0,0 +1,208 @@
+package kong
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Message identifies a built-in error message. See Messages.
+type Message string
+
+// Built-in error messages, and the arguments passed to their formats, in order.
+const (
+	// MessageUnknownFlag is given the flag, eg. "--flag".
+	MessageUnknownFlag Message = "unknown-flag"
+	// MessageUnexpectedArgument is given the argument.
+	MessageUnexpectedArgument Message = "unexpected-argument"
+	// MessageUnexpectedToken is given the token.
+	MessageUnexpectedToken Message = "unexpected-token"
+	// MessageUnexpectedFlagArgument is given the value of the flag.
+	MessageUnexpectedFlagArgument Message = "unexpected-flag-argument"
+	// MessageDidYouMean is given the original message and the closest candidate.
+	MessageDidYouMean Message = "did-you-mean"
+	// MessageDidYouMeanOneOf is given the original message and a list of the closest candidates.
+	MessageDidYouMeanOneOf Message = "did-you-mean-one-of"
+	// MessageRequiresEquals is given the flag as given and its summary.
+	MessageRequiresEquals Message = "requires-equals"
+	// MessagePerhapsTry is given the original message, the flag and the value that was mistaken for a flag.
+	MessagePerhapsTry Message = "perhaps-try"
+	// MessageMaxValues is given the flag and the maximum number of values.
+	MessageMaxValues Message = "max-values"
+	// MessageMaxTimes is given the flag and the maximum number of times it may be given.
+	MessageMaxTimes Message = "max-times"
+	// MessageMissingFlags is given a list of the missing flags.
+	MessageMissingFlags Message = "missing-flags"
+	// MessageExpected is given the missing command or argument.
+	MessageExpected Message = "expected"
+	// MessageExpectedOneOf is given a list of the commands or arguments that may be given.
+	MessageExpectedOneOf Message = "expected-one-of"
+	// MessageMissingPositionals is given a list of the missing positional arguments.
+	MessageMissingPositionals Message = "missing-positionals"
+	// MessageRequired is given the summary of the required argument.
+	MessageRequired Message = "required"
+	// MessageEnum is given the flag or argument, a list of the allowed values and the value.
+	MessageEnum Message = "enum"
+	// MessagePattern is given the flag or argument, the pattern and the value.
+	MessagePattern Message = "pattern"
+	// MessageMin is given the flag or argument, the minimum and the value.
+	MessageMin Message = "min"
+	// MessageMax is given the flag or argument, the maximum and the value.
+	MessageMax Message = "max"
+	// MessageXor is given the names of the two mutually exclusive flags.
+	MessageXor Message = "xor"
+	// MessageAnd is given the names of the flags that must be used together, joined by " and --".
+	MessageAnd Message = "and"
+	// MessageUnusedConfig is given a list of the configuration keys that match no flag.
+	MessageUnusedConfig Message = "unused-config"
+	// MessageUnusedEnvars is given a list of the environment variables that match no flag or argument.
+	MessageUnusedEnvars Message = "unused-envars"
+	// MessageExpectedValue is given a description of the value, the token and the type inferred for the token.
+	MessageExpectedValue Message = "expected-value"
+	// MessageExpectedType is given the expected type, eg. "an int", the value and its Go type.
+	MessageExpectedType Message = "expected-type"
+	// MessageMissingValue is given the expected form of the value, eg. "<key>=<value>;...".
+	MessageMissingValue Message = "missing-value"
+	// MessageBool is given the value.
+	MessageBool Message = "bool"
+	// MessageDuration is given the value and the error from time.ParseDuration.
+	MessageDuration Message = "duration"
+	// MessageInt is given the size in bits and the value.
+	MessageInt Message = "int"
+	// MessageUint is given the size in bits and the value.
+	MessageUint Message = "uint"
+	// MessageMapEntry is given the entry that is not in the form "<key>=<value>".
+	MessageMapEntry Message = "map-entry"
+	// MessageMapKey is given the key.
+	MessageMapKey Message = "map-key"
+	// MessageMapValue is given the value.
+	MessageMapValue Message = "map-value"
+	// MessageIsDirectory is given the path.
+	MessageIsDirectory Message = "is-directory"
+	// MessageNotDirectory is given the path.
+	MessageNotDirectory Message = "not-directory"
+	// MessageOpenFile is given the path and the error.
+	MessageOpenFile Message = "open-file"
+	// MessagePercent is given the value.
+	MessagePercent Message = "percent"
+	// MessagePercentRange is given the value.
+	MessagePercentRange Message = "percent-range"
+	// MessageUnknownUnit is given the unit, the value and a list of the known units.
+	MessageUnknownUnit Message = "unknown-unit"
+	// MessageUnitNumber is given the value.
+	MessageUnitNumber Message = "unit-number"
+	// MessageUnitWholeNumber is given the value, the unit and the Go type of the field.
+	MessageUnitWholeNumber Message = "unit-whole-number"
+	// MessageOutOfRange is given the value and the Go type of the field.
+	MessageOutOfRange Message = "out-of-range"
+)
+
+// DefaultMessages returns the formats of the built-in error messages, in fmt.Sprintf syntax.
+//
+// The returned map is a copy; use Messages to override them.
+func DefaultMessages() map[Message]string {
+	out := make(map[Message]string, len(defaultMessages))
+	for message, format := range defaultMessages {
+		out[message] = format
+	}
+	return out
+}
+
+var defaultMessages = map[Message]string{
+	MessageUnknownFlag:            "unknown flag %s",
+	MessageUnexpectedArgument:     "unexpected argument %s",
+	MessageUnexpectedToken:        "unexpected token %s",
+	MessageUnexpectedFlagArgument: "unexpected flag argument %q",
+	MessageDidYouMean:             "%s, did you mean %s?",
+	MessageDidYouMeanOneOf:        "%s, did you mean one of %s?",
+	MessageRequiresEquals:         "%s requires a value in the form %s",
+	MessagePerhapsTry:             "%s; perhaps try %s=%q?",
+	MessageMaxValues:              "%s accepts at most %d values",
+	MessageMaxTimes:               "%s can be given at most %d times",
+	MessageMissingFlags:           "missing flags: %s",
+	MessageExpected:               "expected %s",
+	MessageExpectedOneOf:          "expected one of %s",
+	MessageMissingPositionals:     "missing positional arguments %s",
+	MessageRequired:               "%s is required",
+	MessageEnum:                   "%s must be one of %s but got %q",
+	MessagePattern:                "%s must match %q but got %q",
+	MessageMin:                    "%s must be at least %v but got %s",
+	MessageMax:                    "%s must be at most %v but got %s",
+	MessageXor:                    "--%s and --%s can't be used together",
+	MessageAnd:                    "--%s must be used together",
+	MessageUnusedConfig:           "unknown configuration keys: %s",
+	MessageUnusedEnvars:           "unknown environment variables: %s",
+	MessageExpectedValue:          "expected %s value but got %q (%s)",
+	MessageExpectedType:           "expected %s but got %q (%T)",
+	MessageMissingValue:           "missing value, expecting %q",
+	MessageBool:                   "bool value must be true, 1, yes, false, 0 or no but got %q",
+	MessageDuration:               "expected duration but got %q: %v",
+	MessageInt:                    "expected a valid %d bit int but got %q",
+	MessageUint:                   "expected a valid %d bit uint but got %q",
+	MessageMapEntry:               "expected \"<key>=<value>\" but got %q",
+	MessageMapKey:                 "invalid map key %q",
+	MessageMapValue:               "invalid map value %q",
+	MessageIsDirectory:            "%q exists but is a directory",
+	MessageNotDirectory:           "%q exists but is not a directory",
+	MessageOpenFile:               "failed to open %q: %v",
+	MessagePercent:                "expected a percentage such as 35%% or a ratio such as 0.35 but got %q",
+	MessagePercentRange:           "expected a value between 0%% and 100%% but got %q",
+	MessageUnknownUnit:            "unknown unit %q in %q, expected one of %s",
+	MessageUnitNumber:             "expected a number with an optional unit but got %q",
+	MessageUnitWholeNumber:        "%q is not a whole number of %s that fits in %s",
+	MessageOutOfRange:             "%q is out of range for %s",
+}
+
+// Messages overrides the formats of built-in error messages, eg. to match the tone or terminology of a product.
+//
+// Formats are in fmt.Sprintf syntax and are given the same arguments, in the same order, as those in
+// DefaultMessages. Explicit argument indexes such as "%[2]s" may be used to reorder them.
+func Messages(messages map[Message]string) Option {
+	return OptionFunc(func(k *Kong) error {
+		if k.messages == nil {
+			k.messages = catalog{}
+		}
+		for message, format := range messages {
+			def, ok := defaultMessages[message]
+			if !ok {
+				return fmt.Errorf("unknown message %q", message)
+			}
+			if !strings.Contains(format, "%[") && countVerbs(format) != countVerbs(def) {
+				return fmt.Errorf("message %q: expected %d arguments but %q has %d", message, countVerbs(def), format, countVerbs(format))
+			}
+			k.messages[message] = format
+		}
+		return nil
+	})
+}
+
+// catalog of message formats overriding the defaults.
+type catalog map[Message]string
+
+func (c catalog) errorf(message Message, args ...any) error {
+	return errors.New(c.sprintf(message, args...))
+}
+
+func (c catalog) sprintf(message Message, args ...any) string {
+	format, ok := c[message]
+	if !ok {
+		format = defaultMessages[message]
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// countVerbs returns the number of formatting verbs in "format", ignoring "%%".
+func countVerbs(format string) int {
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+		n++
+	}
+	return n
+}
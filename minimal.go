@@ -0,0 +1,124 @@
+//go:build kong_minimal
+// +build kong_minimal
+
+package kong
+
+import (
+	"fmt"
+)
+
+// This file replaces help rendering, shell completion and model dumping in binaries built with the kong_minimal
+// tag. Options requesting a stripped feature fail in New().
+
+// errStripped returns the error for a feature that is not available in kong_minimal builds.
+func errStripped(feature string) error {
+	return fmt.Errorf("%s is not available in binaries built with the kong_minimal tag", feature)
+}
+
+// ShellCompletion is not available in kong_minimal builds.
+func ShellCompletion() Option {
+	return OptionFunc(func(k *Kong) error { return errStripped("ShellCompletion()") })
+}
+
+func (k *Kong) printCompletions(line string) {}
+
+// DebugModelFlag is not available in kong_minimal builds.
+func DebugModelFlag() Option {
+	return OptionFunc(func(k *Kong) error { return errStripped("DebugModelFlag()") })
+}
+
+func (k *Kong) addDebugModelFlag() {}
+
+// DefaultShortHelpPrinter is the default HelpPrinter for short help on error.
+func DefaultShortHelpPrinter(options HelpOptions, ctx *Context) error {
+	w := newMinimalHelpWriter(ctx)
+	w.section("usage")
+	w.print("Usage: " + usage(ctx))
+	return w.flush()
+}
+
+// DefaultHelpPrinter is the default HelpPrinter.
+//
+// In kong_minimal builds help is printed one item per line, without wrapping, alignment or groups, and HelpOptions
+// are ignored.
+func DefaultHelpPrinter(options HelpOptions, ctx *Context) error {
+	ctx.interpolateFlagRefs()
+	node := ctx.Selected()
+	if node == nil {
+		node = ctx.Model.Node
+	}
+	w := newMinimalHelpWriter(ctx)
+	w.section("usage")
+	w.print("Usage: " + usage(ctx))
+	if node.Help != "" {
+		w.section("help")
+		w.print("")
+		w.print(node.Help)
+	}
+	if len(node.Positional) > 0 {
+		w.section("arguments")
+		w.print("")
+		w.print("Arguments:")
+		for _, arg := range node.Positional {
+			w.print("  " + arg.Summary() + "  " + ctx.Kong.helpFormatter(arg))
+		}
+	}
+	if groups := node.AllFlags(true); len(groups) > 0 {
+		w.section("flags")
+		w.print("")
+		w.print("Flags:")
+		for _, group := range groups {
+			for _, flag := range group {
+				w.print("  " + flag.Summary() + "  " + ctx.Kong.helpFormatter(flag.Value))
+			}
+		}
+	}
+	if cmds := node.Leaves(true); len(cmds) > 0 {
+		w.section("commands")
+		w.print("")
+		w.print("Commands:")
+		for _, cmd := range cmds {
+			w.print("  " + cmd.Summary() + "  " + cmd.Help)
+		}
+	}
+	return w.flush()
+}
+
+// usage returns the usage line of the selected command.
+func usage(ctx *Context) string {
+	if cmd := ctx.Selected(); cmd != nil {
+		return ctx.Model.Name + " " + cmd.Summary()
+	}
+	return ctx.Model.Name + ctx.Model.Summary()
+}
+
+type minimalHelpWriter struct {
+	helpOutput
+}
+
+func newMinimalHelpWriter(ctx *Context) *minimalHelpWriter {
+	newSink := ctx.Kong.helpSink
+	if newSink == nil {
+		newSink = NewHelpSink
+	}
+	return &minimalHelpWriter{helpOutput{sink: newSink(ctx.Stdout)}}
+}
+
+func (w *minimalHelpWriter) section(name string) {
+	if w.err == nil {
+		w.err = w.sink.Section(name)
+	}
+}
+
+func (w *minimalHelpWriter) print(line string) {
+	if w.err == nil {
+		w.err = w.sink.WriteLine(line)
+	}
+}
+
+func (w *minimalHelpWriter) flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.sink.Flush()
+}
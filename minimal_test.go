@@ -0,0 +1,57 @@
+//go:build kong_minimal
+// +build kong_minimal
+
+package kong_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestMinimalStrippedOptions(t *testing.T) {
+	var cli struct{}
+	_, err := kong.New(&cli, kong.ShellCompletion())
+	assert.EqualError(t, err, "ShellCompletion() is not available in binaries built with the kong_minimal tag")
+	_, err = kong.New(&cli, kong.DebugModelFlag())
+	assert.EqualError(t, err, "DebugModelFlag() is not available in binaries built with the kong_minimal tag")
+}
+
+func TestMinimalHelp(t *testing.T) {
+	var cli struct {
+		Flag string `help:"A flag."`
+		Cmd  struct {
+			Arg string `arg:"" help:"An argument."`
+		} `cmd:"" help:"A command."`
+	}
+	w := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Name("test"), kong.Writers(w, w), kong.Exit(func(int) {}))
+
+	_, _ = p.Parse([]string{"--help"})
+	assert.Equal(t, `Usage: test <command> [flags]
+
+Flags:
+  --help  Show context-sensitive help.
+  --flag=STRING  A flag.
+
+Commands:
+  cmd <arg> [flags]  A command.
+`, w.String())
+
+	w.Reset()
+	_, _ = p.Parse([]string{"cmd", "--help"})
+	assert.Equal(t, `Usage: test cmd <arg> [flags]
+
+A command.
+
+Arguments:
+  <arg>  An argument.
+
+Flags:
+  --help  Show context-sensitive help.
+  --flag=STRING  A flag.
+`, w.String())
+}
@@ -0,0 +1,77 @@
+package kong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingFlagsError is returned when required flags are missing.
+type MissingFlagsError struct {
+	// Flags that are required but were not given, other than members of xor and and groups.
+	Flags   []*Flag
+	message string
+}
+
+func (m *MissingFlagsError) Error() string { return m.message }
+
+// GroupMissingFlags reports several missing required flags on separate lines, grouped by their help group and
+// with their help, so that they can all be fixed in one pass, eg.
+//
+//	missing flags:
+//	  --token=STRING    API token.
+//	  Database
+//	    --db-host=STRING    Database host.
+//	    --db-user=STRING    Database user.
+func GroupMissingFlags() Option {
+	return OptionFunc(func(k *Kong) error {
+		k.groupMissing = true
+		return nil
+	})
+}
+
+// formatMissingFlags lists "flags" grouped by their help group, followed by any "others", eg. "--a or --b".
+func formatMissingFlags(flags []*Flag, others []string) string {
+	type group struct {
+		title string
+		rows  [][2]string
+	}
+	ungrouped := &group{}
+	groups := []*group{ungrouped}
+	byKey := map[string]*group{}
+	for _, flag := range flags {
+		g := ungrouped
+		if flag.Group != nil {
+			if g = byKey[flag.Group.Key]; g == nil {
+				g = &group{title: flag.Group.Title}
+				byKey[flag.Group.Key] = g
+				groups = append(groups, g)
+			}
+		}
+		g.rows = append(g.rows, [2]string{flag.Summary(), strings.SplitN(flag.Help, "\n", 2)[0]})
+	}
+	for _, other := range others {
+		ungrouped.rows = append(ungrouped.rows, [2]string{other, ""})
+	}
+	out := &strings.Builder{}
+	for _, g := range groups {
+		indent := "  "
+		if g.title != "" {
+			fmt.Fprintf(out, "\n  %s", g.title)
+			indent = "    "
+		}
+		width := 0
+		for _, row := range g.rows {
+			if row[1] != "" && len(row[0]) > width {
+				width = len(row[0])
+			}
+		}
+		for _, row := range g.rows {
+			if row[1] == "" {
+				fmt.Fprintf(out, "\n%s%s", indent, row[0])
+			} else {
+				fmt.Fprintf(out, "\n%s%-*s    %s", indent, width, row[0], row[1])
+			}
+		}
+	}
+	return out.String()
+}
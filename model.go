@@ -1,7 +1,9 @@
 package kong
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"reflect"
@@ -19,6 +21,20 @@ type Application struct {
 	*Node
 	// Help flag, if the NoDefaultHelp() option is not specified.
 	HelpFlag *Flag
+	// Metadata set with the About() option.
+	Metadata Metadata
+
+	parser *Kong // Parser the model was built by, for options that affect parsing.
+}
+
+// Metadata describes an application beyond its name and description.
+type Metadata struct {
+	Author     string
+	License    string
+	Homepage   string
+	SupportURL string
+	// Long description, shown in full help after the description.
+	Long string
 }
 
 // Argument represents a branching positional argument.
@@ -46,6 +62,7 @@ type Node struct {
 	Detail      string // Detailed help displayed when describing command/arg alone.
 	Group       *Group
 	Hidden      bool
+	Stability   Stability
 	Flags       []*Flag
 	Positional  []*Positional
 	Children    []*Node
@@ -53,8 +70,9 @@ type Node struct {
 	Target      reflect.Value // Pointer to the value in the grammar that this Node is associated with.
 	Tag         *Tag
 	Aliases     []string
-	Passthrough bool // Set to true to stop flag parsing when encountered.
-	Active      bool // Denotes the node is part of an active branch in the CLI.
+	Passthrough bool   // Set to true to stop flag parsing when encountered.
+	Active      bool   // Denotes the node is part of an active branch in the CLI.
+	Rest        *Value // Receives all arguments after the first bare "--", if present.
 
 	Argument *Value // Populated when Type is ArgumentNode.
 }
@@ -94,7 +112,7 @@ func (n *Node) findNode(key reflect.Value) *Node {
 // If "hide" is true hidden flags will be omitted.
 func (n *Node) AllFlags(hide bool) (out [][]*Flag) {
 	if n.Parent != nil {
-		out = append(out, n.Parent.AllFlags(hide)...)
+		out = append(out, inheritedFlags(n.Parent.AllFlags(hide), n.Flags)...)
 	}
 	group := []*Flag{}
 	for _, flag := range n.Flags {
@@ -109,6 +127,37 @@ func (n *Node) AllFlags(hide bool) (out [][]*Flag) {
 	return
 }
 
+// inheritedFlags returns the ancestor flags in "groups" that are inherited by a node with "flags".
+//
+// Flags tagged "local" are not inherited, nor are flags overridden by one of "flags".
+func inheritedFlags(groups [][]*Flag, flags []*Flag) (out [][]*Flag) {
+	shadowed := map[string]bool{}
+	for _, flag := range flags {
+		for _, key := range flag.shadows {
+			shadowed[key] = true
+		}
+	}
+	for _, group := range groups {
+		kept := []*Flag{}
+	next:
+		for _, flag := range group {
+			if flag.Tag.Local {
+				continue
+			}
+			for _, key := range flag.keys() {
+				if shadowed[key] {
+					continue next
+				}
+			}
+			kept = append(kept, flag)
+		}
+		if len(kept) > 0 {
+			out = append(out, kept)
+		}
+	}
+	return out
+}
+
 // Leaves returns the leaf commands/arguments under Node.
 //
 // If "hidden" is true hidden leaves will be omitted.
@@ -143,8 +192,12 @@ func (n *Node) Depth() int {
 
 // Summary help string for the node (not including application name).
 func (n *Node) Summary() string {
+	return n.summary(n.FlagSummary(true))
+}
+
+func (n *Node) summary(flags string) string {
 	summary := n.Path()
-	if flags := n.FlagSummary(true); flags != "" {
+	if flags != "" {
 		summary += " " + flags
 	}
 	args := []string{}
@@ -255,29 +308,90 @@ type Value struct {
 	Target          reflect.Value
 	Required        bool
 	Set             bool            // Set to true when this value is set through some mechanism.
+	EnvVar          string          // Name of the envar that supplied the value, if any.
 	Format          string          // Formatting directive, if applicable.
 	Position        int             // Position (for positional arguments).
 	Passthrough     bool            // Deprecated: Use PassthroughMode instead. Set to true to stop flag parsing when encountered.
 	PassthroughMode PassthroughMode //
 	Active          bool            // Denotes the value is part of an active branch in the CLI.
+
+	formatter    func(reflect.Value) string // Registered with TypeFormatter.
+	helpTemplate string                     // Help referencing other flags, see Context.interpolateFlagRefs.
+	messages     catalog                    // Overridden with Messages.
+}
+
+// FormatValue formats "value" for display, using any formatter registered for the type with TypeFormatter.
+//
+// Secret values are redacted.
+func (v *Value) FormatValue(value reflect.Value) string {
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if v.formatter != nil && value.IsValid() && value.Kind() != reflect.Ptr {
+		return v.Redact(v.formatter(value))
+	}
+	return v.Redact(fmt.Sprintf("%v", value.Interface()))
+}
+
+// FormattedDefault returns the default value formatted for display.
+//
+// Defaults are only reformatted if a formatter is registered for the type with TypeFormatter.
+func (v *Value) FormattedDefault() string {
+	if v.formatter == nil || v.Default == "" {
+		return v.Redact(v.Default)
+	}
+	typ := v.Target.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	target := reflect.New(typ).Elem()
+	if err := v.Mapper.Decode(&DecodeContext{Value: v, Scan: ScanFromTokens(Token{Type: FlagValueToken, Value: v.Default})}, target); err != nil {
+		return v.Redact(v.Default)
+	}
+	return v.FormatValue(target)
+}
+
+// Redact returns a masked placeholder in place of "value" if the value is tagged as a secret, otherwise "value".
+//
+// Help printers, error messages and exporters should pass any user-supplied value through Redact before display.
+func (v *Value) Redact(value string) string {
+	if v.Tag != nil && v.Tag.Secret && value != "" {
+		return redacted
+	}
+	return value
 }
 
-// EnumMap returns a map of the enums in this value.
+// EnumMap returns a map of the enums in this value, including any aliases.
 func (v *Value) EnumMap() map[string]bool {
 	parts := strings.Split(v.Enum, ",")
 	out := make(map[string]bool, len(parts))
 	for _, part := range parts {
-		out[strings.TrimSpace(part)] = true
+		for _, name := range strings.Split(part, "|") {
+			out[strings.TrimSpace(name)] = true
+		}
 	}
 	return out
 }
 
-// EnumSlice returns a slice of the enums in this value.
+// EnumSlice returns a slice of the enums in this value, without aliases.
 func (v *Value) EnumSlice() []string {
 	parts := strings.Split(v.Enum, ",")
 	out := make([]string, len(parts))
 	for i, part := range parts {
-		out[i] = strings.TrimSpace(part)
+		canonical, _, _ := strings.Cut(part, "|")
+		out[i] = strings.TrimSpace(canonical)
+	}
+	return out
+}
+
+// EnumAliases returns a map of enum aliases to their canonical values, eg. "yml" to "yaml" for `enum:"json,yaml|yml"`.
+func (v *Value) EnumAliases() map[string]string {
+	out := map[string]string{}
+	for _, part := range strings.Split(v.Enum, ",") {
+		names := strings.Split(part, "|")
+		for _, alias := range names[1:] {
+			out[strings.TrimSpace(alias)] = strings.TrimSpace(names[0])
+		}
 	}
 	return out
 }
@@ -287,7 +401,7 @@ func (v *Value) ShortSummary() string {
 	if v.Flag != nil {
 		return fmt.Sprintf("--%s", v.Name)
 	}
-	argText := "<" + v.Name + ">"
+	argText := "<" + v.displayName() + ">"
 	if v.IsCumulative() {
 		argText += " ..."
 	}
@@ -297,6 +411,14 @@ func (v *Value) ShortSummary() string {
 	return argText
 }
 
+// displayName is the name of a positional argument in summaries, from its "placeholder" tag if any.
+func (v *Value) displayName() string {
+	if v.Tag != nil && v.Tag.PlaceHolder != "" {
+		return v.Tag.PlaceHolder
+	}
+	return v.Name
+}
+
 // Summary returns a human-readable summary of the value.
 func (v *Value) Summary() string {
 	if v.Flag != nil {
@@ -305,7 +427,7 @@ func (v *Value) Summary() string {
 		}
 		return fmt.Sprintf("--%s=%s", v.Name, v.Flag.FormatPlaceHolder())
 	}
-	argText := "<" + v.Name + ">"
+	argText := "<" + v.displayName() + ">"
 	if v.IsCumulative() {
 		argText += " ..."
 	}
@@ -351,14 +473,97 @@ func (v *Value) Parse(scan *Scanner, target reflect.Value) (err error) {
 	if target.Kind() == reflect.Ptr && target.IsNil() {
 		target.Set(reflect.New(target.Type().Elem()))
 	}
+	if v.Tag.AtFile {
+		if err = readAtFile(scan); err != nil {
+			return v.withErrHelp(fmt.Errorf("%s: %w", v.ShortSummary(), err))
+		}
+	}
+	raw := scan.Peek()
 	err = v.Mapper.Decode(&DecodeContext{Value: v, Scan: scan}, target)
 	if err != nil {
-		return fmt.Errorf("%s: %w", v.ShortSummary(), err)
+		var expected *expectedError
+		if errors.As(err, &expected) {
+			expected.messages = v.messages
+		}
+		if v.Tag.Secret {
+			err = &redactedError{error: err, secret: raw.String()}
+		}
+		return v.withErrHelp(fmt.Errorf("%s: %w", v.ShortSummary(), err))
+	}
+	if strings.Contains(v.Enum, "|") {
+		canonicaliseEnum(v.EnumAliases(), target)
 	}
 	v.Set = true
 	return nil
 }
 
+// canonicaliseEnum replaces enum aliases in string elements of "target" with their canonical values.
+func canonicaliseEnum(aliases map[string]string, target reflect.Value) {
+	switch target.Kind() {
+	case reflect.Ptr:
+		if !target.IsNil() {
+			canonicaliseEnum(aliases, target.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < target.Len(); i++ {
+			canonicaliseEnum(aliases, target.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range target.MapKeys() {
+			value := target.MapIndex(key)
+			if value.Kind() != reflect.String {
+				continue
+			}
+			if canonical, ok := aliases[value.String()]; ok {
+				target.SetMapIndex(key, reflect.ValueOf(canonical).Convert(value.Type()))
+			}
+		}
+	case reflect.String:
+		if canonical, ok := aliases[target.String()]; ok && target.CanSet() {
+			target.SetString(canonical)
+		}
+	}
+}
+
+// readAtFile replaces a next token of the form "@file" or "@-" with the content of the file or stdin, less one
+// trailing newline. "@@" escapes a literal "@".
+func readAtFile(scan *Scanner) error {
+	token := scan.Peek()
+	value, ok := token.Value.(string)
+	if !ok || !strings.HasPrefix(value, "@") {
+		return nil
+	}
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case strings.HasPrefix(value, "@@"):
+		token.Value = value[1:]
+	case value == "@-":
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		data, err = os.ReadFile(ExpandPath(value[1:])) //nolint:gosec
+	}
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		token.Value = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	}
+	scan.Pop()
+	scan.PushToken(token)
+	return nil
+}
+
+// withErrHelp appends the "errhelp" tag, if any, to "err".
+func (v *Value) withErrHelp(err error) error {
+	if v.Tag == nil || v.Tag.ErrHelp == "" {
+		return err
+	}
+	return fmt.Errorf("%w; %s", err, v.Tag.ErrHelp)
+}
+
 // Apply value to field.
 func (v *Value) Apply(value reflect.Value) {
 	v.Target.Set(value)
@@ -377,18 +582,26 @@ func (v *Value) ApplyDefault() error {
 // Reset this value to its default, either the zero value or the parsed result of its envar,
 // or its "default" tag.
 //
-// Does not include resolvers.
+// Envars are tried in order, so legacy names can be listed after their replacements.
+//
+// Does not include resolvers, nor envars of flags tagged with `source:"..."`, which are resolved in order with the
+// other sources.
 func (v *Value) Reset() error {
 	v.Target.Set(reflect.Zero(v.Target.Type()))
-	if len(v.Tag.Envs) != 0 {
+	v.EnvVar = ""
+	if len(v.Tag.Envs) != 0 && len(v.Tag.Sources) == 0 {
 		for _, env := range v.Tag.Envs {
 			envar, ok := os.LookupEnv(env)
 			// Parse the first non-empty ENV in the list
 			if ok {
+				if v.Tag.ExpandEnv {
+					envar = os.ExpandEnv(envar)
+				}
 				err := v.Parse(ScanFromTokens(Token{Type: FlagValueToken, Value: envar}), v.Target)
 				if err != nil {
-					return fmt.Errorf("%s (from envar %s=%q)", err, env, envar)
+					return fmt.Errorf("%s (from envar %s=%q)", err, env, v.Redact(envar))
 				}
+				v.EnvVar = env
 				return nil
 			}
 		}
@@ -410,14 +623,48 @@ type Flag struct {
 	Group       *Group // Logical grouping when displaying. May also be used by configuration loaders to group options logically.
 	Xor         []string
 	And         []string
+	Relations   []FlagRelation // Relationships with other flags, from Xor and And.
 	PlaceHolder string
 	Envs        []string
 	Aliases     []string
 	Short       rune
 	Hidden      bool
 	Negated     bool
+	Stability   Stability
+
+	placeHolderStyle    PlaceHolderStyle
+	placeHolderTemplate string   // Placeholder referencing other flags, see Context.interpolateFlagRefs.
+	shadows             []string // Keys of ancestor flags shadowed by this flag, if it is tagged "override".
+}
+
+// keys returns the command-line spellings of the flag, eg. "--name", "-n", and "--no-name".
+func (f *Flag) keys() []string {
+	keys := []string{"--" + f.Name}
+	for _, alias := range f.Aliases {
+		keys = append(keys, "--"+alias)
+	}
+	if f.Short != 0 {
+		keys = append(keys, "-"+string(f.Short))
+	}
+	if neg := negatableFlagName(f.Name, f.Tag.Negatable); neg != "" {
+		keys = append(keys, neg)
+	}
+	return keys
 }
 
+// PlaceHolderStyle controls how placeholders are generated for flags without a "placeholder" tag.
+type PlaceHolderStyle int
+
+const (
+	// UpperCasePlaceHolders uses the upper-cased type or flag name, eg. "--count=INT". This is the default.
+	UpperCasePlaceHolders PlaceHolderStyle = iota
+	// TypePlaceHolders uses the type, or a small enum, in angle brackets, eg. "--count=<int>" or "--level=<debug|info>".
+	TypePlaceHolders
+)
+
+// Enums with more than this many values are not listed in TypePlaceHolders.
+const maxPlaceHolderEnum = 5
+
 func (f *Flag) String() string {
 	out := "--" + f.Name
 	if f.Short != 0 {
@@ -436,24 +683,44 @@ func (f *Flag) FormatPlaceHolder() string {
 		return placeholderHelper.PlaceHolder(f)
 	}
 	tail := ""
-	if f.Value.IsSlice() && f.Value.Tag.Sep != -1 && f.Tag.Type == "" {
-		tail += string(f.Value.Tag.Sep) + "..."
+	if f.Value.IsSlice() && f.Value.Tag.Separator != "" && f.Tag.Type == "" {
+		tail += f.Value.Tag.Separator + "..."
 	}
 	if f.PlaceHolder != "" {
 		return f.PlaceHolder + tail
 	}
-	if f.HasDefault {
+	if f.HasDefault && !f.Tag.Secret {
 		if f.Value.Target.Kind() == reflect.String {
 			return strconv.Quote(f.Default) + tail
 		}
-		return f.Default + tail
+		return f.FormattedDefault() + tail
+	}
+	if f.Tag.Unit != "" {
+		return "N" + f.Tag.Unit
 	}
 	if f.Value.IsMap() {
-		if f.Value.Tag.MapSep != -1 && f.Tag.Type == "" {
-			tail = string(f.Value.Tag.MapSep) + "..."
+		if f.Value.Tag.MapSeparator != "" && f.Tag.Type == "" {
+			tail = f.Value.Tag.MapSeparator + "..."
+		}
+		if f.placeHolderStyle == TypePlaceHolders {
+			return "<key=value>" + tail
 		}
 		return "KEY=VALUE" + tail
 	}
+	if f.placeHolderStyle == TypePlaceHolders {
+		if enum := f.EnumSlice(); f.Enum != "" && len(enum) <= maxPlaceHolderEnum {
+			return "<" + strings.Join(enum, "|") + ">" + tail
+		}
+		typ := f.Target.Type()
+		for typ.Kind() == reflect.Slice || typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		name, _, _ := strings.Cut(typ.Name(), "[")
+		if name == "" {
+			name = typ.Kind().String()
+		}
+		return "<" + strings.ToLower(dashedString(name)) + ">" + tail
+	}
 	if f.Tag != nil && f.Tag.TypeName != "" {
 		return strings.ToUpper(dashedString(f.Tag.TypeName)) + tail
 	}
@@ -0,0 +1,350 @@
+package kong
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ModelChangeKind is the kind of a ModelChange.
+type ModelChangeKind string
+
+// Kinds of change reported by DiffModels.
+const (
+	CommandAdded   ModelChangeKind = "command-added"
+	CommandRemoved ModelChangeKind = "command-removed"
+	FlagAdded      ModelChangeKind = "flag-added"
+	FlagRemoved    ModelChangeKind = "flag-removed"
+	// FlagRenamed is a removed flag replaced by one keeping its name as an alias, or of the same type, default, enum
+	// and non-empty help. It is only breaking if the old name is not kept as an alias.
+	FlagRenamed    ModelChangeKind = "flag-renamed"
+	FlagRequired   ModelChangeKind = "flag-required"
+	ArgAdded       ModelChangeKind = "arg-added"
+	ArgRemoved     ModelChangeKind = "arg-removed"
+	ArgRequired    ModelChangeKind = "arg-required"
+	DefaultChanged ModelChangeKind = "default-changed"
+	EnumTightened  ModelChangeKind = "enum-tightened"
+	EnumWidened    ModelChangeKind = "enum-widened"
+)
+
+// ModelChange is a difference between two models, reported by DiffModels.
+type ModelChange struct {
+	Kind ModelChangeKind
+	// Command the change applies to, eg. "app user create".
+	Command string
+	// Flag the change applies to, if any, by its name in the new model.
+	Flag string
+	// Arg is the positional argument the change applies to, if any, by its name in the new model. Positional
+	// arguments are matched by position.
+	Arg string
+	// Old and New describe the change, eg. the previous and current defaults, or the previous name of a flag.
+	Old, New string
+	// Version is the "since" tag of an added flag or argument, or the "removedin" tag of a removed one, eg. for
+	// changelogs.
+	Version string
+	// Breaking is true if command-lines accepted by the old model may be rejected, or behave differently, with the
+	// new model.
+	Breaking bool
+}
+
+func (c ModelChange) String() string {
+	subject := c.Command
+	if c.Flag != "" {
+		subject += " --" + c.Flag
+	}
+	if c.Arg != "" {
+		subject += " <" + c.Arg + ">"
+	}
+	out := fmt.Sprintf("%s: %s", subject, c.Kind)
+	if c.Old != "" || c.New != "" {
+		out += fmt.Sprintf(" (%q -> %q)", c.Old, c.New)
+	}
+	if c.Version != "" {
+		out += " in " + c.Version
+	}
+	if c.Breaking {
+		out += " [breaking]"
+	}
+	return out
+}
+
+// ModelDiff is the list of changes between two models, in command and flag order.
+type ModelDiff []ModelChange
+
+// Breaking returns only the breaking changes.
+func (d ModelDiff) Breaking() ModelDiff {
+	out := ModelDiff{}
+	for _, change := range d {
+		if change.Breaking {
+			out = append(out, change)
+		}
+	}
+	return out
+}
+
+// Fingerprint returns a digest of the command-line interface described by the model: its commands, positional
+// arguments and flags, with their types, defaults, enums and whether they are required. Help text is not included.
+//
+// The fingerprint can be recorded by release tooling and compared to detect changes, which DiffModels then describes.
+// It does not cover everything that affects which command-lines are accepted: hidden flags and commands, and
+// constraints between flags such as "xor" and "requiredif", are not included.
+func (a *Application) Fingerprint() string {
+	h := sha256.New()
+	for _, command := range snapshotModel(a) {
+		fmt.Fprintf(h, "%s\n", command.path)
+		for _, positional := range command.positionals {
+			fmt.Fprintf(h, "\t<%s> %s default=%q enum=%v required=%v\n",
+				positional.name, positional.typ, positional.def, positional.enum, positional.required)
+		}
+		for _, flag := range command.flags {
+			fmt.Fprintf(h, "\t--%s %s short=%q aliases=%v default=%q enum=%v required=%v\n",
+				flag.name, flag.typ, flag.short, flag.aliases, flag.def, flag.enum, flag.required)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DiffModels reports the differences between the commands, positional arguments and flags of two models, eg. the previous and current
+// releases of an application, so release tooling can flag breaking changes.
+func DiffModels(previous, current *Application) ModelDiff {
+	diff := ModelDiff{}
+	oldCommands := map[string]modelCommand{}
+	for _, command := range snapshotModel(previous) {
+		oldCommands[command.path] = command
+	}
+	seen := map[string]bool{}
+	for _, command := range snapshotModel(current) {
+		seen[command.path] = true
+		old, ok := oldCommands[command.path]
+		if !ok {
+			diff = append(diff, ModelChange{Kind: CommandAdded, Command: command.path})
+			continue
+		}
+		diff = append(diff, diffPositionals(command.path, old.positionals, command.positionals)...)
+		diff = append(diff, diffFlags(command.path, old.flags, command.flags)...)
+	}
+	for _, command := range snapshotModel(previous) {
+		if !seen[command.path] {
+			diff = append(diff, ModelChange{Kind: CommandRemoved, Command: command.path, Breaking: true})
+		}
+	}
+	return diff
+}
+
+func diffPositionals(path string, oldArgs, newArgs []modelFlag) ModelDiff {
+	diff := ModelDiff{}
+	for i, arg := range newArgs {
+		if i >= len(oldArgs) {
+			diff = append(diff, ModelChange{Kind: ArgAdded, Command: path, Arg: arg.name, Version: arg.since, Breaking: arg.required})
+			continue
+		}
+		diff = append(diff, diffValue(ModelChange{Command: path, Arg: arg.name}, ArgRequired, oldArgs[i], arg)...)
+	}
+	for _, arg := range oldArgs[min(len(newArgs), len(oldArgs)):] {
+		diff = append(diff, ModelChange{Kind: ArgRemoved, Command: path, Arg: arg.name, Version: arg.removedIn, Breaking: true})
+	}
+	return diff
+}
+
+func diffFlags(path string, oldFlags, newFlags []modelFlag) ModelDiff {
+	diff := ModelDiff{}
+	oldByName := map[string]modelFlag{}
+	for _, flag := range oldFlags {
+		oldByName[flag.name] = flag
+	}
+	newByName := map[string]modelFlag{}
+	for _, flag := range newFlags {
+		newByName[flag.name] = flag
+	}
+	// Pair removed flags with added flags that look like renames.
+	renamedFrom := map[string]string{}
+	renamed := map[string]bool{}
+	for _, flag := range oldFlags {
+		if _, ok := newByName[flag.name]; ok {
+			continue
+		}
+		for _, candidate := range newFlags {
+			if _, ok := oldByName[candidate.name]; ok || renamedFrom[candidate.name] != "" {
+				continue
+			}
+			if candidate.hasAlias(flag.name) || (candidate.sameShape(flag) && flag.help != "" && candidate.help == flag.help) {
+				renamedFrom[candidate.name] = flag.name
+				renamed[flag.name] = true
+				break
+			}
+		}
+	}
+	for _, flag := range newFlags {
+		previous, ok := oldByName[flag.name]
+		if from := renamedFrom[flag.name]; from != "" {
+			previous = oldByName[from]
+			diff = append(diff, ModelChange{Kind: FlagRenamed, Command: path, Flag: flag.name, Old: from, New: flag.name,
+				Breaking: !flag.hasAlias(from)})
+		} else if !ok {
+			diff = append(diff, ModelChange{Kind: FlagAdded, Command: path, Flag: flag.name, Version: flag.since, Breaking: flag.required})
+			continue
+		}
+		diff = append(diff, diffValue(ModelChange{Command: path, Flag: flag.name}, FlagRequired, previous, flag)...)
+	}
+	for _, flag := range oldFlags {
+		if _, ok := newByName[flag.name]; !ok && !renamed[flag.name] {
+			diff = append(diff, ModelChange{Kind: FlagRemoved, Command: path, Flag: flag.name, Version: flag.removedIn, Breaking: true})
+		}
+	}
+	return diff
+}
+
+// diffValue reports the changes between the "previous" and "current" versions of a flag or positional argument,
+// filling in the subject of "base". "required" is the kind reported when the value becomes required.
+func diffValue(base ModelChange, required ModelChangeKind, previous, current modelFlag) ModelDiff {
+	diff := ModelDiff{}
+	change := func(kind ModelChangeKind, old, new string, breaking bool) {
+		c := base
+		c.Kind, c.Old, c.New, c.Breaking = kind, old, new, breaking
+		diff = append(diff, c)
+	}
+	if current.required && !previous.required {
+		change(required, "", "", true)
+	}
+	if current.def != previous.def {
+		change(DefaultChanged, previous.def, current.def, true)
+	}
+	removed, added := diffEnums(previous.enum, current.enum)
+	if len(removed) > 0 {
+		change(EnumTightened, strings.Join(removed, ","), "", true)
+	}
+	if len(added) > 0 {
+		change(EnumWidened, "", strings.Join(added, ","), false)
+	}
+	return diff
+}
+
+// diffEnums returns the values removed from and added to an enum. An empty enum accepts any value.
+func diffEnums(before, after []string) (removed, added []string) {
+	switch {
+	case len(before) == 0 && len(after) == 0:
+		return nil, nil
+	case len(before) == 0:
+		return []string{"*"}, nil
+	case len(after) == 0:
+		return nil, []string{"*"}
+	}
+	oldSet := map[string]bool{}
+	for _, value := range before {
+		oldSet[value] = true
+	}
+	newSet := map[string]bool{}
+	for _, value := range after {
+		newSet[value] = true
+		if !oldSet[value] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range before {
+		if !newSet[value] {
+			removed = append(removed, value)
+		}
+	}
+	return removed, added
+}
+
+type modelCommand struct {
+	path        string
+	positionals []modelFlag
+	flags       []modelFlag
+}
+
+type modelFlag struct {
+	name      string
+	typ       string
+	short     string
+	aliases   []string
+	def       string
+	enum      []string // Sorted, including aliases.
+	required  bool
+	help      string
+	since     string
+	removedIn string
+}
+
+func (f modelFlag) hasAlias(name string) bool {
+	for _, alias := range f.aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f modelFlag) sameShape(other modelFlag) bool {
+	return f.typ == other.typ && f.def == other.def && strings.Join(f.enum, ",") == strings.Join(other.enum, ",")
+}
+
+// snapshotModel flattens the commands and flags of "app" into a comparable form, in a stable order.
+func snapshotModel(app *Application) []modelCommand {
+	out := []modelCommand{}
+	_ = Visit(app, func(v Visitable, next Next) error {
+		node, ok := v.(*Node)
+		if !ok {
+			if app, ok := v.(*Application); ok {
+				node = app.Node
+			} else {
+				return next(nil)
+			}
+		}
+		command := modelCommand{path: modelPath(node)}
+		for _, positional := range node.Positional {
+			command.positionals = append(command.positionals, snapshotValue(positional))
+		}
+		for _, flag := range node.Flags {
+			f := snapshotValue(flag.Value)
+			f.aliases = append([]string{}, flag.Aliases...)
+			sort.Strings(f.aliases)
+			if flag.Short != 0 {
+				f.short = string(flag.Short)
+			}
+			command.flags = append(command.flags, f)
+		}
+		sort.Slice(command.flags, func(i, j int) bool { return command.flags[i].name < command.flags[j].name })
+		out = append(out, command)
+		return next(nil)
+	})
+	return out
+}
+
+// snapshotValue returns the comparable form of a flag or positional argument.
+func snapshotValue(value *Value) modelFlag {
+	f := modelFlag{
+		name:      value.Name,
+		def:       value.Default,
+		required:  value.Required,
+		help:      value.OrigHelp,
+		since:     value.Tag.Since,
+		removedIn: value.Tag.RemovedIn,
+	}
+	if value.Target.IsValid() {
+		f.typ = value.Target.Type().String()
+	}
+	if value.Enum != "" {
+		for v := range value.EnumMap() {
+			f.enum = append(f.enum, v)
+		}
+		sort.Strings(f.enum)
+	}
+	return f
+}
+
+// modelPath is the path of "node" from the root, without aliases, eg. "app user create <id>".
+func modelPath(node *Node) string {
+	parts := []string{}
+	for n := node; n != nil; n = n.Parent {
+		name := n.Name
+		if n.Type == ArgumentNode {
+			name = "<" + name + ">"
+		}
+		parts = append([]string{name}, parts...)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,104 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestDiffModels(t *testing.T) {
+	var v1 struct {
+		Deploy struct {
+			Region  string `enum:"eu,us,ap" default:"eu"`
+			Timeout int    `default:"30" help:"Timeout in seconds."`
+			Verbose bool   `help:"Be chatty."`
+			Force   bool   `removedin:"2.0"`
+			DryRun  bool   `help:"Only print."`
+		} `cmd:""`
+		Status struct{} `cmd:""`
+	}
+	var v2 struct {
+		Deploy struct {
+			Region  string `enum:"eu,us" default:"us"`
+			Timeout int    `default:"30" help:"Timeout in seconds." required:""`
+			Chatty  bool   `help:"Be chatty."`
+			Plan    bool   `help:"Only print." aliases:"dry-run"`
+			Wait    bool   `since:"2.0"`
+		} `cmd:""`
+		Rollback struct{} `cmd:""`
+	}
+	old := mustNew(t, &v1, kong.Name("app")).Model
+	current := mustNew(t, &v2, kong.Name("app")).Model
+	assert.Equal(t, old.Fingerprint(), mustNew(t, &v1, kong.Name("app")).Model.Fingerprint())
+	assert.NotEqual(t, old.Fingerprint(), current.Fingerprint())
+
+	diff := kong.DiffModels(old, current)
+	assert.Equal(t, kong.ModelDiff{
+		{Kind: kong.FlagRenamed, Command: "app deploy", Flag: "chatty", Old: "verbose", New: "chatty", Breaking: true},
+		{Kind: kong.FlagRenamed, Command: "app deploy", Flag: "plan", Old: "dry-run", New: "plan"},
+		{Kind: kong.DefaultChanged, Command: "app deploy", Flag: "region", Old: "eu", New: "us", Breaking: true},
+		{Kind: kong.EnumTightened, Command: "app deploy", Flag: "region", Old: "ap", Breaking: true},
+		{Kind: kong.FlagRequired, Command: "app deploy", Flag: "timeout", Breaking: true},
+		{Kind: kong.FlagAdded, Command: "app deploy", Flag: "wait", Version: "2.0"},
+		{Kind: kong.FlagRemoved, Command: "app deploy", Flag: "force", Version: "2.0", Breaking: true},
+		{Kind: kong.CommandAdded, Command: "app rollback"},
+		{Kind: kong.CommandRemoved, Command: "app status", Breaking: true},
+	}, diff)
+	assert.Equal(t, 6, len(diff.Breaking()))
+	assert.Equal(t, `app deploy --chatty: flag-renamed ("verbose" -> "chatty") [breaking]`, diff[0].String())
+	assert.Equal(t, `app deploy --wait: flag-added in 2.0`, diff[5].String())
+}
+
+func TestDiffModelsPositionals(t *testing.T) {
+	var v1 struct {
+		Copy struct {
+			Mode string `arg:"" enum:"fast,safe,slow"`
+			From string `arg:""`
+			Into string `arg:"" optional:""`
+			Log  string `arg:"" optional:"" removedin:"2.0"`
+		} `cmd:""`
+	}
+	var v2 struct {
+		Copy struct {
+			Mode string `arg:"" enum:"fast,safe"`
+			From string `arg:""`
+			Into string `arg:""`
+		} `cmd:""`
+		Move struct {
+			From  string `arg:""`
+			Into  string `arg:""`
+			Force string `arg:"" since:"2.0"`
+		} `cmd:""`
+	}
+	var v3 struct {
+		Copy struct {
+			Mode string `arg:"" enum:"fast,safe"`
+			From string `arg:""`
+			Into string `arg:""`
+		} `cmd:""`
+		Move struct {
+			From  string `arg:""`
+			Into  string `arg:""`
+			Force string `arg:"" since:"2.0"`
+			Extra string `arg:"" since:"3.0"`
+		} `cmd:""`
+	}
+	old := mustNew(t, &v1, kong.Name("app")).Model
+	current := mustNew(t, &v2, kong.Name("app")).Model
+	assert.NotEqual(t, old.Fingerprint(), current.Fingerprint())
+
+	assert.Equal(t, kong.ModelDiff{
+		{Kind: kong.EnumTightened, Command: "app copy", Arg: "mode", Old: "slow", Breaking: true},
+		{Kind: kong.ArgRequired, Command: "app copy", Arg: "into", Breaking: true},
+		{Kind: kong.ArgRemoved, Command: "app copy", Arg: "log", Version: "2.0", Breaking: true},
+		{Kind: kong.CommandAdded, Command: "app move"},
+	}, kong.DiffModels(old, current))
+
+	diff := kong.DiffModels(current, mustNew(t, &v3, kong.Name("app")).Model)
+	assert.Equal(t, kong.ModelDiff{
+		{Kind: kong.ArgAdded, Command: "app move", Arg: "extra", Version: "3.0", Breaking: true},
+	}, diff)
+	assert.Equal(t, `app move <extra>: arg-added in 3.0 [breaking]`, diff[0].String())
+}
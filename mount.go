@@ -0,0 +1,52 @@
+package kong
+
+import "fmt"
+
+// Mount mounts an existing parser, eg. one constructed by another library, as the command "name" of the CLI, so
+// that tools can be composed without sharing their grammars.
+//
+// The remainder of the command-line after "name", including any flags, is parsed by "parser" when the command is
+// run, and the command it selects is then run. Help for the mounted command, eg. "app name --help", is that of
+// "parser", written to the same writers and with its application name prefixed by that of the CLI. "help" defaults
+// to the description of "parser".
+//
+// "tags" is a list of extra tag strings to parse, in the form <key>:"<value>".
+func Mount(name, help string, parser *Kong, tags ...string) Option {
+	return OptionFunc(func(k *Kong) error {
+		if parser == nil {
+			return fmt.Errorf("kong: Mount %q requires a parser", name)
+		}
+		if help == "" {
+			help = parser.Model.Help
+		}
+		k.dynamicCommands = append(k.dynamicCommands, &dynamicCommand{
+			name: name,
+			help: help,
+			cmd:  &mountedCommand{parser: parser},
+			tags: append([]string{`cmd:"" passthrough:""`}, tags...),
+		})
+		k.postBuildOptions = append(k.postBuildOptions, OptionFunc(func(k *Kong) error {
+			parser.Model.Name = k.Model.Name + " " + name
+			parser.Stdout = k.Stdout
+			parser.Stderr = k.Stderr
+			parser.Exit = k.Exit
+			return nil
+		}))
+		return nil
+	})
+}
+
+// mountedCommand delegates parsing and running of its arguments to another parser.
+type mountedCommand struct {
+	Args []string `arg:"" optional:""`
+
+	parser *Kong
+}
+
+func (m *mountedCommand) Run() error {
+	ctx, err := m.parser.Parse(m.Args)
+	if err != nil {
+		return err
+	}
+	return ctx.Run()
+}
@@ -0,0 +1,62 @@
+package kong_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type mountedGetCmd struct {
+	Key     string `arg:""`
+	Verbose bool   `short:"v"`
+}
+
+func (m *mountedGetCmd) Run(out *[]string) error {
+	*out = append(*out, "get "+m.Key)
+	if m.Verbose {
+		*out = append(*out, "verbose")
+	}
+	return nil
+}
+
+func TestMount(t *testing.T) {
+	out := []string{}
+	var sub struct {
+		Get mountedGetCmd `cmd:"" help:"Get a key."`
+	}
+	subParser := mustNew(t, &sub, kong.Name("store"), kong.Description("Manage the store."), kong.Bind(&out))
+
+	var cli struct {
+		Debug bool
+	}
+	w := &bytes.Buffer{}
+	exited := false
+	p := mustNew(t, &cli,
+		kong.Name("app"),
+		kong.Writers(w, w),
+		kong.Exit(func(int) { exited = true }),
+		kong.Mount("store", "", subParser),
+	)
+	ctx, err := p.Parse([]string{"--debug", "store", "get", "-v", "name"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Debug)
+	assert.NoError(t, ctx.Run())
+	assert.Equal(t, []string{"get name", "verbose"}, out)
+
+	ctx, err = p.Parse([]string{"store", "--help"})
+	assert.NoError(t, err)
+	_ = ctx.Run() // Parsing continues after help, as Exit does not terminate.
+	assert.True(t, exited)
+	assert.Contains(t, w.String(), "Usage: app store <command>")
+	assert.Contains(t, w.String(), "get <key> [flags]")
+
+	w.Reset()
+	ctx, err = kong.Trace(p, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, kong.DefaultHelpPrinter(kong.HelpOptions{}, ctx))
+	assert.Contains(t, w.String(), "store [<args> ...]")
+	assert.Contains(t, w.String(), "Manage the store.")
+}
@@ -1,10 +1,17 @@
 package kong
 
+import "reflect"
+
 // negatableDefault is a placeholder value for the Negatable tag to indicate
 // the negated flag is --no-<flag-name>. This is needed as at the time of
 // parsing a tag, the field's flag name is not yet known.
 const negatableDefault = "_"
 
+// isBoolType returns true if typ is a bool or a pointer to a bool, ie. can be negated.
+func isBoolType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Bool || typ == tristateType || (typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Bool)
+}
+
 // negatableFlagName returns the name of the flag for a negatable field, or
 // an empty string if the field is not negatable.
 func negatableFlagName(name, negation string) string {
@@ -0,0 +1,59 @@
+package kong
+
+import (
+	"reflect"
+)
+
+// NoInput is bound for injection into Run() methods, and is true if --no-input was given. Commands that prompt
+// should fail instead.
+type NoInput bool
+
+// NoInputFlag adds a --no-input flag to the application that disables prompting, so that scripts and CI pipelines
+// fail fast instead of waiting for input that will never come.
+//
+// With --no-input, required flags tagged `type:"password"` that are not otherwise provided are errors rather than
+// prompted for, and kong.NoInput is bound as true so that commands can do the same for their own prompts.
+func NoInputFlag() Option {
+	return OptionFunc(func(k *Kong) error {
+		k.noInputFlag = true
+		return nil
+	})
+}
+
+type noInputFlag bool
+
+// addNoInputFlag adds the --no-input flag to the root if enabled with NoInputFlag.
+func (k *Kong) addNoInputFlag() {
+	if !k.noInputFlag {
+		return
+	}
+	var target noInputFlag
+	value := reflect.ValueOf(&target).Elem()
+	flag := &Flag{
+		Value: &Value{
+			Name:         "no-input",
+			Help:         "Fail instead of prompting for input.",
+			OrigHelp:     "Fail instead of prompting for input.",
+			Target:       value,
+			Tag:          &Tag{},
+			Mapper:       k.registry.ForValue(value),
+			DefaultValue: reflect.ValueOf(false),
+		},
+	}
+	flag.Flag = flag
+	k.Model.Flags = append(k.Model.Flags, flag)
+}
+
+// noInput returns whether --no-input is set. It can be used before values are applied.
+func (c *Context) noInput() NoInput {
+	for _, flag := range c.Flags() {
+		value, ok := c.values[flag.Value]
+		if !ok {
+			value = flag.Target
+		}
+		if set, ok := value.Interface().(noInputFlag); ok && bool(set) {
+			return true
+		}
+	}
+	return false
+}
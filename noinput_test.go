@@ -0,0 +1,47 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type noInputCmd struct {
+	noInput kong.NoInput
+}
+
+func (c *noInputCmd) Run(noInput kong.NoInput) error {
+	c.noInput = noInput
+	return nil
+}
+
+func TestNoInputFlag(t *testing.T) {
+	var cli struct {
+		Token  string     `type:"password" required:""`
+		Secret string     `type:"password"`
+		Cmd    noInputCmd `cmd:""`
+	}
+	prompts := []string{}
+	p := mustNew(t, &cli, kong.NoInputFlag(), kong.PasswordPrompt(func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "hunter2", nil
+	}))
+
+	_, err := p.Parse([]string{"cmd", "--no-input"})
+	assert.EqualError(t, err, "--token is required, and can't be prompted for with --no-input")
+
+	ctx, err := p.Parse([]string{"cmd", "--no-input", "--token=s3cret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "", cli.Secret)
+	assert.NoError(t, ctx.Run())
+	assert.True(t, bool(cli.Cmd.noInput))
+	assert.Equal(t, 0, len(prompts))
+
+	ctx, err = p.Parse([]string{"cmd"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.False(t, bool(cli.Cmd.noInput))
+	assert.Equal(t, []string{"Enter --token: ", "Enter --secret: "}, prompts)
+}
@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 // An Option applies optional changes to the Kong application.
@@ -35,6 +36,26 @@ func (v Vars) Apply(k *Kong) error {
 	return nil
 }
 
+// VarsFunc sets variables whose values are computed on demand, for values that are expensive to compute.
+//
+// Each function is called at most once, the first time its variable is interpolated into the model, so
+// unreferenced variables are never computed and are not available via Vars bindings. Variables referenced only by
+// help are computed the first time help is printed, while those referenced by defaults, enums, placeholders or envars
+// are computed by New(). Variables set with Vars take precedence.
+type VarsFunc map[string]func() string
+
+// Apply lets VarsFunc act as an Option.
+func (v VarsFunc) Apply(k *Kong) error {
+	if k.lazyVars == nil {
+		k.lazyVars = map[string]func() string{}
+		k.lazyValues = Vars{}
+	}
+	for key, fn := range v {
+		k.lazyVars[key] = fn
+	}
+	return nil
+}
+
 // CloneWith clones the current Vars and merges "vars" onto the clone.
 func (v Vars) CloneWith(vars Vars) Vars {
 	out := make(Vars, len(v)+len(vars))
@@ -65,6 +86,48 @@ func WithHyphenPrefixedParameters(enable bool) Option {
 	})
 }
 
+// NegativeNumberPolicy controls whether arguments such as "-123" and "-1.5" are numbers or short flags.
+type NegativeNumberPolicy int
+
+// Negative number policies.
+const (
+	// NegativeNumbersAsFlags treats "-1" as the short flag "-1". This is the default.
+	NegativeNumbersAsFlags NegativeNumberPolicy = iota
+	// NegativeNumbersAsValues always treats negative numbers as values, for both flags and positional arguments.
+	NegativeNumbersAsValues
+	// NegativeNumbersAuto treats negative numbers as values unless the application has a short flag that is a digit.
+	NegativeNumbersAuto
+)
+
+// NegativeNumbers sets the policy for arguments that look like negative numbers.
+//
+// Unlike WithHyphenPrefixedParameters, which accepts any hyphen-prefixed flag value, this only applies to numbers
+// but also covers positional arguments and slice elements.
+func NegativeNumbers(policy NegativeNumberPolicy) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.negativeNumbers = policy
+		return nil
+	})
+}
+
+func (k *Kong) negativeNumbersAreValues() bool {
+	switch k.negativeNumbers {
+	case NegativeNumbersAsValues:
+		return true
+	case NegativeNumbersAuto:
+		digitFlag := false
+		_ = Visit(k.Model, func(node Visitable, next Next) error {
+			if flag, ok := node.(*Flag); ok && flag.Short >= '0' && flag.Short <= '9' {
+				digitFlag = true
+			}
+			return next(nil)
+		})
+		return !digitFlag
+	default:
+		return false
+	}
+}
+
 type embedded struct {
 	strct any
 	tags  []string
@@ -99,7 +162,7 @@ type dynamicCommand struct {
 // "tags" is a list of extra tag strings to parse, in the form <key>:"<value>".
 func DynamicCommand(name, help, group string, cmd any, tags ...string) Option {
 	return OptionFunc(func(k *Kong) error {
-		if run := getMethod(reflect.Indirect(reflect.ValueOf(cmd)), "Run"); !run.IsValid() {
+		if run := k.getRunMethod(reflect.Indirect(reflect.ValueOf(cmd))); !run.IsValid() {
 			return fmt.Errorf("kong: DynamicCommand %q must be a type with a 'Run' method; got %T", name, cmd)
 		}
 
@@ -114,6 +177,22 @@ func DynamicCommand(name, help, group string, cmd any, tags ...string) Option {
 	})
 }
 
+// RunMethods sets the names of the entry point methods Context.Run() looks for on commands, in order of preference.
+//
+// The first named method a command implements is called, so a library wrapping Kong can standardise a richer
+// signature, eg. RunMethods("RunE", "Run"), while commands implementing only Run() keep working. The default is "Run".
+//
+// Options that check for entry points, such as DynamicCommand, must come after this option.
+func RunMethods(names ...string) Option {
+	return OptionFunc(func(k *Kong) error {
+		if len(names) == 0 {
+			return fmt.Errorf("kong: RunMethods requires at least one method name")
+		}
+		k.runMethods = names
+		return nil
+	})
+}
+
 // NoDefaultHelp disables the default help flags.
 func NoDefaultHelp() Option {
 	return OptionFunc(func(k *Kong) error {
@@ -133,6 +212,32 @@ func PostBuild(fn func(*Kong) error) Option {
 	})
 }
 
+// OnFlag registers a function called each time the flag "name", eg. "--verbose", "-v" or "verbose", is encountered
+// while parsing, with the raw value given on the command-line.
+//
+// The value is empty for flags given without one, such as booleans and counters. Callbacks are called in
+// command-line order before any hooks, which makes them suitable for order-sensitive behaviour such as increasing
+// the log level as soon as "-v" is seen.
+func OnFlag(name string, fn func(ctx *Context, value string) error) Option {
+	return PostBuild(func(k *Kong) error {
+		found := false
+		_ = Visit(k.Model, func(node Visitable, next Next) error {
+			if flag, ok := node.(*Flag); ok && (name == flag.Name || name == "--"+flag.Name || (flag.Short != 0 && name == "-"+string(flag.Short))) {
+				if k.flagCallbacks == nil {
+					k.flagCallbacks = map[*Flag][]func(*Context, string) error{}
+				}
+				k.flagCallbacks[flag] = append(k.flagCallbacks[flag], fn)
+				found = true
+			}
+			return next(nil)
+		})
+		if !found {
+			return fmt.Errorf("kong: OnFlag: unknown flag %q", name)
+		}
+		return nil
+	})
+}
+
 // WithBeforeReset registers a hook to run before fields values are reset to their defaults
 // (as specified in the grammar) or to zero values.
 func WithBeforeReset(fn any) Option {
@@ -154,6 +259,25 @@ func WithAfterApply(fn any) Option {
 	return withHook("AfterApply", fn)
 }
 
+// WithHookPriority registers the hooks of "options", eg. WithAfterApply(fn), with the given priority.
+//
+// Hooks run in order of increasing priority, and the default priority is 0. Hooks of equal priority run in path
+// order from the root, with the hooks of each node before those registered by options, in registration order.
+// Nodes in the grammar can set the priority of their own hooks by implementing HookPrioritizer.
+func WithHookPriority(priority int, options ...Option) Option {
+	return OptionFunc(func(k *Kong) error {
+		previous := k.hookPriority
+		k.hookPriority = priority
+		defer func() { k.hookPriority = previous }()
+		for _, option := range options {
+			if err := option.Apply(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // withHook registers a named hook.
 func withHook(name string, fn any) Option {
 	value := reflect.ValueOf(fn)
@@ -162,7 +286,7 @@ func withHook(name string, fn any) Option {
 	}
 
 	return OptionFunc(func(k *Kong) error {
-		k.hooks[name] = append(k.hooks[name], value)
+		k.hooks[name] = append(k.hooks[name], hook{fn: value, priority: k.hookPriority})
 		return nil
 	})
 }
@@ -183,6 +307,20 @@ func Description(description string) Option {
 	})
 }
 
+// About sets application metadata, such as the author, license and homepage.
+//
+// The long description is shown in full help after the description, and the remaining fields in a footer of the
+// application's help.
+func About(meta Metadata) Option {
+	return PostBuild(func(k *Kong) error {
+		k.Model.Metadata = meta
+		if meta.Long != "" {
+			k.Model.Detail = meta.Long
+		}
+		return nil
+	})
+}
+
 // TypeMapper registers a mapper to a type.
 func TypeMapper(typ reflect.Type, mapper Mapper) Option {
 	return OptionFunc(func(k *Kong) error {
@@ -191,6 +329,55 @@ func TypeMapper(typ reflect.Type, mapper Mapper) Option {
 	})
 }
 
+// TypeFormatter registers a function that formats values of a type for display, eg. defaults in help.
+//
+// This is useful for types whose default "%v" formatting is unfriendly, such as byte sizes. Pointers to
+// the type are dereferenced before formatting.
+func TypeFormatter(typ reflect.Type, formatter func(value reflect.Value) string) Option {
+	return OptionFunc(func(k *Kong) error {
+		if k.typeFormatters == nil {
+			k.typeFormatters = map[reflect.Type]func(reflect.Value) string{}
+		}
+		k.typeFormatters[typ] = formatter
+		return nil
+	})
+}
+
+// TypeTags registers tags, such as enum, help and placeholder, applied to every field of a type, including pointers,
+// slices and maps of it, eg.
+//
+//	kong.TypeTags(reflect.TypeOf(Region("")), `enum:"us,eu" default:"us" help:"Cloud region."`)
+//
+// Tags on a field take precedence over those registered for its type.
+func TypeTags(typ reflect.Type, tags string) Option {
+	return OptionFunc(func(k *Kong) error {
+		items, err := parseTagItems(tags, bareChars)
+		if err != nil {
+			return fmt.Errorf("tags for %s: %w", typ, err)
+		}
+		if k.typeTags == nil {
+			k.typeTags = map[reflect.Type]map[string][]string{}
+		}
+		k.typeTags[typ] = items
+		return nil
+	})
+}
+
+// typeTagsFor returns the tags registered with TypeTags for "typ", or for the type it points to or holds.
+func (k *Kong) typeTagsFor(typ reflect.Type) map[string][]string {
+	for {
+		if tags, ok := k.typeTags[typ]; ok {
+			return tags
+		}
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+		default:
+			return nil
+		}
+	}
+}
+
 // KindMapper registers a mapper to a kind.
 func KindMapper(kind reflect.Kind, mapper Mapper) Option {
 	return OptionFunc(func(k *Kong) error {
@@ -479,6 +666,45 @@ func Configuration(loader ConfigurationLoader, paths ...string) Option {
 	})
 }
 
+// UnusedConfigPolicy controls what happens when configuration holds keys that match no flag.
+type UnusedConfigPolicy int
+
+// Unused configuration key policies.
+const (
+	// IgnoreUnusedConfig silently ignores unused configuration keys. This is the default.
+	IgnoreUnusedConfig UnusedConfigPolicy = iota
+	// WarnUnusedConfig writes a warning listing unused configuration keys to Kong.Stderr.
+	WarnUnusedConfig
+	// RejectUnusedConfig fails parsing if there are unused configuration keys.
+	RejectUnusedConfig
+)
+
+// UnusedConfigKeys sets the policy for configuration keys that match no flag on the selected command path, such
+// as the typo "verbos" in a configuration file.
+//
+// Only resolvers implementing UnusedKeysReporter, such as the JSON resolver, are checked.
+func UnusedConfigKeys(policy UnusedConfigPolicy) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.unusedConfig = policy
+		return nil
+	})
+}
+
+// UnusedEnvars sets the policy for environment variables starting with "prefix" followed by "_", eg. "MYAPP_",
+// that are not read by any flag or argument, such as stale variables left behind after a flag is renamed.
+//
+// The policies are as for UnusedConfigKeys.
+func UnusedEnvars(prefix string, policy UnusedConfigPolicy) Option {
+	return OptionFunc(func(k *Kong) error {
+		if prefix == "" {
+			return errors.New("unused envars prefix cannot be empty")
+		}
+		k.unusedEnvPrefix = strings.TrimSuffix(prefix, "_") + "_"
+		k.unusedEnvars = policy
+		return nil
+	})
+}
+
 // ExpandPath is a helper function to expand a relative or home-relative path to an absolute path.
 //
 // eg. ~/.someconf -> /home/alec/.someconf
@@ -500,6 +726,48 @@ func ExpandPath(path string) string {
 	return abspath
 }
 
+var windowsEnvVarRe = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_]*)%`)
+
+// expandValuePath expands a path decoded into a value, additionally expanding ~, ~user and environment
+// variables if the value is tagged with `expand:""`.
+func expandValuePath(ctx *DecodeContext, path string) string {
+	if ctx.Value != nil && ctx.Value.Tag != nil && ctx.Value.Tag.Expand {
+		path = expandHomeAndEnv(path)
+	}
+	return ExpandPath(path)
+}
+
+// expandHomeAndEnv expands a leading ~ or ~user, then $VAR, ${VAR} and %VAR% references.
+//
+// Unset %VAR% references are left as is, as cmd.exe does.
+func expandHomeAndEnv(path string) string {
+	if strings.HasPrefix(path, "~") {
+		name, rest := path[1:], ""
+		if i := strings.IndexAny(name, `/\`); i >= 0 {
+			name, rest = name[:i], name[i:]
+		}
+		var (
+			u   *user.User
+			err error
+		)
+		if name == "" {
+			u, err = user.Current()
+		} else {
+			u, err = user.Lookup(name)
+		}
+		if err == nil {
+			path = u.HomeDir + rest
+		}
+	}
+	path = os.ExpandEnv(path)
+	return windowsEnvVarRe.ReplaceAllStringFunc(path, func(ref string) string {
+		if value, ok := os.LookupEnv(ref[1 : len(ref)-1]); ok {
+			return value
+		}
+		return ref
+	})
+}
+
 func siftStrings(ss []string, filter func(s string) bool) []string {
 	i := 0
 	ss = append([]string(nil), ss...)
@@ -512,6 +780,16 @@ func siftStrings(ss []string, filter func(s string) bool) []string {
 	return ss[0:i]
 }
 
+// envarName derives an environment variable name from a prefix and flag name.
+//
+//	envarName("PREFIX", "some.value") == "PREFIX_SOME_VALUE"
+func envarName(prefix, flag string) string {
+	replacer := strings.NewReplacer("-", "_", ".", "_")
+	names := append([]string{prefix}, camelCase(replacer.Replace(flag))...)
+	names = siftStrings(names, func(s string) bool { return !(s == "_" || strings.TrimSpace(s) == "") })
+	return strings.ToUpper(strings.Join(names, "_"))
+}
+
 // DefaultEnvars option inits environment names for flags.
 // The name will not generate if tag "env" is "-".
 // Predefined environment variables are skipped.
@@ -530,10 +808,7 @@ func DefaultEnvars(prefix string) Option {
 		case len(env) > 0:
 			return
 		}
-		replacer := strings.NewReplacer("-", "_", ".", "_")
-		names := append([]string{prefix}, camelCase(replacer.Replace(flag.Name))...)
-		names = siftStrings(names, func(s string) bool { return !(s == "_" || strings.TrimSpace(s) == "") })
-		name := strings.ToUpper(strings.Join(names, "_"))
+		name := envarName(prefix, flag.Name)
 		flag.Envs = append(flag.Envs, name)
 		flag.Value.Tag.Envs = append(flag.Value.Tag.Envs, name)
 	}
@@ -554,6 +829,139 @@ func DefaultEnvars(prefix string) Option {
 	})
 }
 
+// NegationPrefix changes the prefix used to negate flags tagged with `negatable:""`, from the default of "no-".
+//
+// eg. NegationPrefix("disable-") will negate --color with --disable-color.
+func NegationPrefix(prefix string) Option {
+	return OptionFunc(func(k *Kong) error {
+		if prefix == "" {
+			return errors.New("negation prefix cannot be empty")
+		}
+		k.negationPrefix = prefix
+		return nil
+	})
+}
+
+// AutoNegatable makes all boolean flags with a default of "true" negatable, as if they were tagged with
+// `negatable:""`.
+func AutoNegatable() Option {
+	return OptionFunc(func(k *Kong) error {
+		k.autoNegatable = true
+		return nil
+	})
+}
+
+// ExpandPaths expands ~, ~user, $VAR and %VAR% in the values of all path types, as if they were tagged
+// with `expand:""`.
+func ExpandPaths() Option {
+	return OptionFunc(func(k *Kong) error {
+		k.expandPaths = true
+		return nil
+	})
+}
+
+// RequireEqualsForValues requires values of long flags to be given in the form --flag=value.
+//
+// Space separated values, eg. "--flag value", are rejected. This avoids values being silently
+// misparsed as positional arguments, and vice versa.
+func RequireEqualsForValues() Option {
+	return OptionFunc(func(k *Kong) error {
+		k.requireEquals = true
+		return nil
+	})
+}
+
+// SlashFlags additionally accepts Windows-style flags in the form /flag and /flag:value.
+//
+// Flag names and aliases are matched case-insensitively, short flags case-sensitively, negatable flags are negated
+// with eg. /no-color, and /? shows help. Arguments starting with / that do not match a flag, such as absolute paths,
+// are treated as positional arguments.
+func SlashFlags() Option {
+	return OptionFunc(func(k *Kong) error {
+		k.slashFlags = true
+		return nil
+	})
+}
+
+// PlaceHolders sets the style of placeholders generated for flags without a "placeholder" tag.
+func PlaceHolders(style PlaceHolderStyle) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.placeHolderStyle = style
+		return nil
+	})
+}
+
+// AutoShortFlags assigns short flags to all flags that don't have one.
+//
+// The short flag is the first character of the flag name that is not already used by a flag in the same scope (ie.
+// by an ancestor or descendant command), trying lower case characters first, then upper case. Flags are processed in
+// declaration order from the root down, so assignment is deterministic. Hidden flags and flags named in "exclude"
+// are skipped, as are flags for which no free character can be found.
+func AutoShortFlags(exclude ...string) Option {
+	excluded := map[string]bool{}
+	for _, name := range exclude {
+		excluded[strings.TrimPrefix(name, "--")] = true
+	}
+	return PostBuild(func(k *Kong) error {
+		return Visit(k.Model, func(v Visitable, next Next) error {
+			var node *Node
+			switch v := v.(type) {
+			case *Application:
+				node = v.Node
+			case *Node:
+				node = v
+			default:
+				return next(nil)
+			}
+			for _, flag := range node.Flags {
+				if flag.Short != 0 || flag.Hidden || excluded[flag.Name] {
+					continue
+				}
+				used := usedShortFlags(node)
+				for _, r := range shortFlagCandidates(flag.Name) {
+					if !used[r] {
+						flag.Short = r
+						flag.Tag.Short = r
+						break
+					}
+				}
+			}
+			return next(nil)
+		})
+	})
+}
+
+// Short flags used by ancestors of node, node itself, and all its descendants.
+func usedShortFlags(node *Node) map[rune]bool {
+	used := map[rune]bool{}
+	for parent := node.Parent; parent != nil; parent = parent.Parent {
+		for _, flag := range parent.Flags {
+			used[flag.Short] = true
+		}
+	}
+	_ = Visit(node, func(v Visitable, next Next) error {
+		if flag, ok := v.(*Flag); ok {
+			used[flag.Short] = true
+		}
+		return next(nil)
+	})
+	return used
+}
+
+func shortFlagCandidates(name string) []rune {
+	lower := []rune{}
+	upper := []rune{}
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			lower = append(lower, unicode.ToLower(r))
+			if unicode.IsLetter(r) {
+				upper = append(upper, unicode.ToUpper(r))
+			}
+		}
+	}
+	return append(lower, upper...)
+}
+
 // FlagNamer allows you to override the default kebab-case automated flag name generation.
 func FlagNamer(namer func(fieldName string) string) Option {
 	return OptionFunc(func(k *Kong) error {
@@ -1,6 +1,7 @@
 package kong
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -183,3 +184,193 @@ func TestCallbackNonPointerError(t *testing.T) {
 	err = callFunction(reflect.ValueOf(method), p.bindings)
 	assert.EqualError(t, err, "ERROR: failed")
 }
+
+func TestAutoShortFlags(t *testing.T) {
+	var cli struct {
+		Force   bool
+		Follow  bool
+		Debug   bool `short:"f"`
+		Hidden  bool `hidden:""`
+		Exclude bool
+		Cmd     struct {
+			Dry bool
+			Foo string `short:"F"`
+		} `cmd:""`
+	}
+	p, err := New(&cli, AutoShortFlags("exclude"))
+	assert.NoError(t, err)
+	shorts := map[string]string{}
+	_ = Visit(p.Model, func(v Visitable, next Next) error {
+		if flag, ok := v.(*Flag); ok && flag.Short != 0 {
+			shorts[flag.Name] = string(flag.Short)
+		}
+		return next(nil)
+	})
+	assert.Equal(t, map[string]string{
+		"help":   "h",
+		"force":  "o",
+		"follow": "l",
+		"debug":  "f",
+		"dry":    "d",
+		"foo":    "F",
+	}, shorts)
+	_, err = p.Parse([]string{"-ol", "cmd", "-d"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Force && cli.Follow && cli.Cmd.Dry)
+}
+
+func TestRequireEqualsForValues(t *testing.T) {
+	var cli struct {
+		Name    string
+		Verbose bool
+		Level   int      `type:"counter" short:"l"`
+		Count   int      `short:"c"`
+		Args    []string `arg:"" optional:""`
+	}
+	p, err := New(&cli, RequireEqualsForValues())
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"--name", "x"})
+	assert.EqualError(t, err, "--name requires a value in the form --name=STRING")
+	_, err = p.Parse([]string{"--name=x", "--verbose", "--level", "-c", "3", "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "x", cli.Name)
+	assert.Equal(t, 3, cli.Count)
+	assert.Equal(t, []string{"a"}, cli.Args)
+}
+
+func TestSlashFlags(t *testing.T) {
+	var cli struct {
+		Output  string `short:"o" aliases:"out"`
+		Verbose bool
+		Color   bool   `negatable:"" default:"true"`
+		Version bool   `short:"V"`
+		Path    string `arg:"" optional:""`
+	}
+	p, err := New(&cli, SlashFlags())
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"/Verbose", "/o:report.txt", "/tmp/data"})
+	assert.NoError(t, err)
+	assert.True(t, cli.Verbose)
+	assert.Equal(t, "report.txt", cli.Output)
+	assert.Equal(t, "/tmp/data", cli.Path)
+
+	_, err = p.Parse([]string{`/out:c:\reports\a.txt`})
+	assert.NoError(t, err)
+	assert.Equal(t, `c:\reports\a.txt`, cli.Output)
+
+	_, err = p.Parse([]string{"/No-Color"})
+	assert.NoError(t, err)
+	assert.False(t, cli.Color)
+
+	_, err = p.Parse([]string{"/v"})
+	assert.NoError(t, err)
+	assert.False(t, cli.Version)
+	assert.Equal(t, "/v", cli.Path)
+
+	p, err = New(&cli)
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"/verbose"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/verbose", cli.Path)
+}
+
+func TestNegativeNumbers(t *testing.T) {
+	var cli struct {
+		Offset  float64
+		Deltas  []int
+		Verbose bool  `short:"v"`
+		Start   int   `arg:""`
+		Rest    []int `arg:"" optional:""`
+	}
+	p, err := New(&cli, NegativeNumbers(NegativeNumbersAsValues))
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"--offset", "-1.5", "--deltas", "-1", "-v", "-10", "3", "-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, -1.5, cli.Offset)
+	assert.Equal(t, []int{-1}, cli.Deltas)
+	assert.True(t, cli.Verbose)
+	assert.Equal(t, -10, cli.Start)
+	assert.Equal(t, []int{3, -2}, cli.Rest)
+
+	p, err = New(&cli)
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"-10"})
+	assert.EqualError(t, err, `unknown flag -1, did you mean one of "-h", "-v"?`)
+
+	var digits struct {
+		One   bool `short:"1"`
+		Start int  `arg:""`
+	}
+	p, err = New(&digits, NegativeNumbers(NegativeNumbersAuto))
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"-1", "5"})
+	assert.NoError(t, err)
+	assert.True(t, digits.One)
+	assert.Equal(t, 5, digits.Start)
+}
+
+func TestOnFlag(t *testing.T) {
+	var cli struct {
+		Verbose int `short:"v" type:"counter"`
+		Name    []string
+	}
+	seen := []string{}
+	p, err := New(&cli,
+		OnFlag("-v", func(ctx *Context, value string) error {
+			seen = append(seen, "v"+value)
+			return nil
+		}),
+		OnFlag("name", func(ctx *Context, value string) error {
+			if value == "bad" {
+				return errors.New("bad name")
+			}
+			seen = append(seen, "name="+value)
+			return nil
+		}),
+	)
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"-vv", "--name", "a,b", "--verbose=3"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v", "v", "name=a,b", "v3"}, seen)
+
+	_, err = p.Parse([]string{"--name=bad"})
+	assert.EqualError(t, err, "bad name")
+
+	_, err = New(&cli, OnFlag("--missing", func(*Context, string) error { return nil }))
+	assert.EqualError(t, err, `kong: OnFlag: unknown flag "--missing"`)
+}
+
+func TestPresets(t *testing.T) {
+	var cli struct {
+		Color bool     `default:"true"`
+		Depth int      `short:"d"`
+		Args  []string `arg:"" optional:""`
+	}
+	p, err := New(&cli, POSIXPreset())
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"-d", "-1", "a", "--color=false"})
+	assert.NoError(t, err)
+	assert.Equal(t, -1, cli.Depth)
+	assert.Equal(t, []string{"a", "--color=false"}, cli.Args)
+	assert.True(t, cli.Color)
+
+	p, err = New(&cli, GNUPreset())
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"a", "b", "--no-color"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, cli.Args)
+	assert.False(t, cli.Color)
+
+	p, err = New(&cli, WindowsPreset())
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"/DEPTH:3"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cli.Depth)
+	assert.Equal(t, "<int>", p.Model.Flags[2].FormatPlaceHolder())
+
+	p, err = New(&cli, Preset(GNUPreset(), NegationPrefix("disable-")))
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"--disable-color"})
+	assert.NoError(t, err)
+	assert.False(t, cli.Color)
+}
@@ -0,0 +1,265 @@
+package kong
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats supported by OutputFormatFlag and Formatter.
+const (
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputTable = "table"
+)
+
+// OutputFormatFlag is a flag type that selects how commands encode their results, eg. --output=json.
+//
+// Run() methods observe it by accepting a kong.Formatter parameter. Unless the field is tagged otherwise, the
+// flag accepts "json", "yaml" and "table", defaults to "table", and has a standard help.
+type OutputFormatFlag string
+
+const outputFormatHelp = "Output format (${enum})."
+
+// Formatter is bound for injection into Run() methods, and encodes results in the format selected by an
+// OutputFormatFlag, or "table" if the application has none.
+//
+// Values are encoded as they are by encoding/json, so json struct tags and marshalers are honoured by all formats.
+type Formatter struct {
+	Format string
+	Writer io.Writer
+}
+
+// Encode v to the Writer in the selected format.
+func (f Formatter) Encode(v any) error {
+	switch f.Format {
+	case OutputJSON:
+		enc := json.NewEncoder(f.Writer)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case OutputYAML, OutputTable:
+		node, err := newOutputNode(v)
+		if err != nil {
+			return err
+		}
+		if f.Format == OutputYAML {
+			w := &strings.Builder{}
+			node.writeYAML(w, "")
+			_, err = io.WriteString(f.Writer, w.String())
+			return err
+		}
+		return node.writeTable(f.Writer)
+	default:
+		return fmt.Errorf("unsupported output format %q", f.Format)
+	}
+}
+
+// formatter returns the Formatter for the OutputFormatFlag in the Context, if any.
+func (c *Context) formatter() Formatter {
+	f := Formatter{Format: OutputTable, Writer: c.Kong.Stdout}
+	for _, flag := range c.Flags() {
+		if format, ok := flag.Target.Interface().(OutputFormatFlag); ok && format != "" {
+			f.Format = string(format)
+		}
+	}
+	return f
+}
+
+// outputNode is a JSON value that retains the order of object keys.
+type outputNode struct {
+	object bool
+	array  bool
+	keys   []string      // Object keys.
+	values []*outputNode // Object values or array elements.
+	scalar any           // string, json.Number, bool or nil.
+}
+
+func newOutputNode(v any) (*outputNode, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeOutputNode(dec)
+}
+
+func decodeOutputNode(dec *json.Decoder) (*outputNode, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return &outputNode{scalar: token}, nil
+	}
+	node := &outputNode{object: delim == '{', array: delim == '['}
+	for dec.More() {
+		if node.object {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, key.(string)) //nolint:forcetypeassert
+		}
+		value, err := decodeOutputNode(dec)
+		if err != nil {
+			return nil, err
+		}
+		node.values = append(node.values, value)
+	}
+	_, err = dec.Token() // Closing delimiter.
+	return node, err
+}
+
+func (n *outputNode) isEmpty() bool {
+	return (n.object || n.array) && len(n.values) == 0
+}
+
+// MarshalJSON re-encodes the node, for nested values in table cells.
+func (n *outputNode) MarshalJSON() ([]byte, error) {
+	switch {
+	case n.array:
+		return json.Marshal(n.values)
+	case n.object:
+		w := &bytes.Buffer{}
+		w.WriteByte('{')
+		for i, key := range n.keys {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			data, err := json.Marshal(map[string]*outputNode{key: n.values[i]})
+			if err != nil {
+				return nil, err
+			}
+			w.Write(data[1 : len(data)-1])
+		}
+		w.WriteByte('}')
+		return w.Bytes(), nil
+	default:
+		return json.Marshal(n.scalar)
+	}
+}
+
+func (n *outputNode) writeYAML(w *strings.Builder, indent string) {
+	switch {
+	case n.object && !n.isEmpty():
+		for i, key := range n.keys {
+			value := n.values[i]
+			if (value.object || value.array) && !value.isEmpty() {
+				fmt.Fprintf(w, "%s%s:\n", indent, yamlScalar(key))
+				value.writeYAML(w, indent+"  ")
+			} else {
+				fmt.Fprintf(w, "%s%s: %s\n", indent, yamlScalar(key), value.yamlScalar())
+			}
+		}
+	case n.array && !n.isEmpty():
+		for _, value := range n.values {
+			if (value.object || value.array) && !value.isEmpty() {
+				// Render the element indented, then replace the indentation of its first line with the "- " marker.
+				element := &strings.Builder{}
+				value.writeYAML(element, indent+"  ")
+				w.WriteString(indent + "- " + strings.TrimPrefix(element.String(), indent+"  "))
+			} else {
+				fmt.Fprintf(w, "%s- %s\n", indent, value.yamlScalar())
+			}
+		}
+	default:
+		fmt.Fprintf(w, "%s%s\n", indent, n.yamlScalar())
+	}
+}
+
+func (n *outputNode) yamlScalar() string {
+	switch {
+	case n.object:
+		return "{}"
+	case n.array:
+		return "[]"
+	}
+	switch scalar := n.scalar.(type) {
+	case nil:
+		return "null"
+	case string:
+		return yamlScalar(scalar)
+	default:
+		return fmt.Sprint(scalar)
+	}
+}
+
+var yamlPlainRe = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./-]*$`)
+
+// yamlScalar returns s unquoted if YAML would read it back as the same string, otherwise quoted.
+func yamlScalar(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+	default:
+		if yamlPlainRe.MatchString(s) {
+			return s
+		}
+	}
+	data, _ := json.Marshal(s) //nolint:errchkjson
+	return string(data)
+}
+
+// writeTable writes arrays of objects, and objects, as a table with a column per key.
+func (n *outputNode) writeTable(out io.Writer) error {
+	var rows []*outputNode
+	switch {
+	case n.object:
+		rows = []*outputNode{n}
+	case n.array:
+		rows = n.values
+	default:
+		_, err := fmt.Fprintln(out, n.cell())
+		return err
+	}
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		if !row.object {
+			columns = nil
+			break
+		}
+		for _, key := range row.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	if columns == nil {
+		for _, row := range rows {
+			fmt.Fprintln(w, row.cell())
+		}
+		return w.Flush()
+	}
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, key := range row.keys {
+			for j, column := range columns {
+				if column == key {
+					cells[j] = row.values[i].cell()
+				}
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+func (n *outputNode) cell() string {
+	if n.object || n.array {
+		data, _ := json.Marshal(n) //nolint:errchkjson
+		return string(data)
+	}
+	if n.scalar == nil {
+		return ""
+	}
+	return fmt.Sprint(n.scalar)
+}
@@ -0,0 +1,125 @@
+package kong_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type outputItem struct {
+	Name   string   `json:"name"`
+	Size   int      `json:"size"`
+	Tags   []string `json:"tags,omitempty"`
+	Public bool     `json:"public"`
+}
+
+type outputListCmd struct{}
+
+func (outputListCmd) Run(f kong.Formatter) error {
+	return f.Encode([]outputItem{
+		{Name: "alpha", Size: 10, Tags: []string{"a", "b"}, Public: true},
+		{Name: "beta two", Size: 200},
+	})
+}
+
+func TestOutputFormatFlag(t *testing.T) {
+	var cli struct {
+		Output kong.OutputFormatFlag `short:"o"`
+		List   outputListCmd         `cmd:""`
+	}
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"Table", []string{"list"}, `
+NAME      SIZE  TAGS       PUBLIC
+alpha     10    ["a","b"]  true
+beta two  200              false
+`},
+		{"JSON", []string{"list", "-o", "json"}, `
+[
+  {
+    "name": "alpha",
+    "size": 10,
+    "tags": [
+      "a",
+      "b"
+    ],
+    "public": true
+  },
+  {
+    "name": "beta two",
+    "size": 200,
+    "public": false
+  }
+]
+`},
+		{"YAML", []string{"list", "--output=yaml"}, `
+- name: alpha
+  size: 10
+  tags:
+    - a
+    - b
+  public: true
+- name: "beta two"
+  size: 200
+  public: false
+`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			p := mustNew(t, &cli, kong.Writers(stdout, stdout))
+			ctx, err := p.Parse(test.args)
+			assert.NoError(t, err)
+			assert.NoError(t, ctx.Run())
+			assert.Equal(t, strings.TrimPrefix(test.expected, "\n"), stdout.String())
+		})
+	}
+}
+
+func TestOutputFormatFlagDefaults(t *testing.T) {
+	var cli struct {
+		Output kong.OutputFormatFlag
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, kong.OutputFormatFlag("table"), cli.Output)
+
+	_, err = p.Parse([]string{"--output=xml"})
+	assert.EqualError(t, err, `--output must be one of "json","yaml","table" but got "xml"`)
+
+	flag := p.Model.Flags[1]
+	assert.Equal(t, "Output format (json,yaml,table).", flag.Help)
+
+	var restricted struct {
+		Output kong.OutputFormatFlag `enum:"json,table" default:"json" help:"How to print results."`
+	}
+	p = mustNew(t, &restricted)
+	_, err = p.Parse([]string{"--output=yaml"})
+	assert.Error(t, err)
+	_, err = p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, kong.OutputFormatFlag("json"), restricted.Output)
+}
+
+func TestFormatterEncode(t *testing.T) {
+	w := &bytes.Buffer{}
+	f := kong.Formatter{Format: kong.OutputYAML, Writer: w}
+	assert.NoError(t, f.Encode(map[string]any{"empty": []int{}, "nested": map[string]any{"on": "yes"}, "nil": nil}))
+	assert.Equal(t, "empty: []\nnested:\n  \"on\": \"yes\"\nnil: null\n", w.String())
+
+	w.Reset()
+	f.Format = kong.OutputTable
+	assert.NoError(t, f.Encode(outputItem{Name: "alpha", Size: 1}))
+	assert.Equal(t, "NAME   SIZE  PUBLIC\nalpha  1     false\n", w.String())
+
+	f.Format = "xml"
+	assert.EqualError(t, f.Encode(1), `unsupported output format "xml"`)
+}
@@ -0,0 +1,83 @@
+package kong
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// errNotTerminal is returned by readPassword if stdin is not a terminal.
+var errNotTerminal = errors.New("stdin is not a terminal")
+
+// PasswordPrompt overrides how values of flags tagged `type:"password"` are read when they are not otherwise
+// provided.
+//
+// "fn" is passed a prompt such as "Enter --token: ". By default the value is read from the terminal with echo
+// disabled, and no prompt is made if stdin is not a terminal.
+func PasswordPrompt(fn func(prompt string) (string, error)) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.passwordPrompt = fn
+		return nil
+	})
+}
+
+func passwordMapper() MapperFunc {
+	return func(ctx *DecodeContext, target reflect.Value) error {
+		if target.Kind() != reflect.String {
+			return fmt.Errorf("\"password\" must be applied to a string not %s", target.Type())
+		}
+		return ctx.Scan.PopValueInto("password", target.Addr().Interface())
+	}
+}
+
+// promptPasswords prompts for the value of each active password flag not provided on the command-line, by an
+// envar, by a resolver or by a default, and of each flag with "prompt" in its `source:"..."` chain.
+func (c *Context) promptPasswords() error {
+	prompt := c.Kong.passwordPrompt
+	if prompt == nil {
+		prompt = readPassword
+	}
+	inserted := []*Path{}
+	for _, path := range c.Path {
+		for _, flag := range path.Flags {
+			if len(flag.Tag.Sources) > 0 {
+				resolved, err := c.promptSourced(path, flag, prompt)
+				if err != nil {
+					return err
+				}
+				if resolved != nil {
+					inserted = append(inserted, resolved)
+				}
+				continue
+			}
+			if flag.Tag.Type != "password" || flag.EnvVar != "" || flag.HasDefault {
+				continue
+			}
+			if _, ok := c.values[flag.Value]; ok {
+				continue
+			}
+			if c.noInput() {
+				if flag.Required {
+					return fmt.Errorf("%s is required, and can't be prompted for with --no-input", flag.ShortSummary())
+				}
+				continue
+			}
+			password, err := prompt(fmt.Sprintf("Enter --%s: ", flag.Name))
+			if errors.Is(err, errNotTerminal) {
+				continue
+			} else if err != nil {
+				return fmt.Errorf("%s: %w", flag.ShortSummary(), err)
+			}
+			if err := flag.Parse(Scan().PushTyped(password, FlagValueToken), c.getValue(flag.Value)); err != nil {
+				return err
+			}
+			inserted = append(inserted, &Path{
+				Flag:      flag,
+				Resolved:  true,
+				remainder: c.scan.PeekAll(),
+			})
+		}
+	}
+	c.Path = append(c.Path, inserted...)
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build freebsd || darwin || dragonfly || netbsd || openbsd
+// +build freebsd darwin dragonfly netbsd openbsd
+
+package kong
+
+import "syscall"
+
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
@@ -0,0 +1,11 @@
+//go:build !appengine
+// +build !appengine
+
+package kong
+
+import "syscall"
+
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
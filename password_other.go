@@ -0,0 +1,8 @@
+//go:build appengine || (!linux && !freebsd && !darwin && !dragonfly && !netbsd && !openbsd)
+// +build appengine !linux,!freebsd,!darwin,!dragonfly,!netbsd,!openbsd
+
+package kong
+
+func readPassword(prompt string) (string, error) {
+	return "", errNotTerminal
+}
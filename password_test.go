@@ -0,0 +1,45 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestPasswordPrompt(t *testing.T) {
+	var cli struct {
+		Token string `type:"password" env:"KONG_TEST_TOKEN"`
+	}
+	prompts := []string{}
+	p := mustNew(t, &cli, kong.PasswordPrompt(func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "hunter2", nil
+	}))
+	_, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", cli.Token)
+	assert.Equal(t, []string{"Enter --token: "}, prompts)
+
+	_, err = p.Parse([]string{"--token=s3cret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", cli.Token)
+
+	t.Setenv("KONG_TEST_TOKEN", "from-env")
+	_, err = p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", cli.Token)
+	assert.Equal(t, 1, len(prompts))
+
+	assert.Equal(t, "********", p.Model.Flags[1].FormatValue(p.Model.Flags[1].Target))
+}
+
+func TestPasswordRequiresString(t *testing.T) {
+	var cli struct {
+		Token int `type:"password"`
+	}
+	p := mustNew(t, &cli, kong.PasswordPrompt(func(string) (string, error) { return "1", nil }))
+	_, err := p.Parse(nil)
+	assert.EqualError(t, err, "--token: \"password\" must be applied to a string not int")
+}
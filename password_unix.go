@@ -0,0 +1,50 @@
+//go:build (!appengine && linux) || freebsd || darwin || dragonfly || netbsd || openbsd
+// +build !appengine,linux freebsd darwin dragonfly netbsd openbsd
+
+package kong
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// readPassword prompts on stderr and reads a line from the terminal on stdin with echo disabled.
+func readPassword(prompt string) (string, error) {
+	fd := os.Stdin.Fd()
+	var state syscall.Termios
+	if err := termios(fd, ioctlGetTermios, &state); err != nil {
+		return "", errNotTerminal
+	}
+	noEcho := state
+	noEcho.Lflag &^= syscall.ECHO
+	noEcho.Lflag |= syscall.ICANON | syscall.ISIG
+	noEcho.Iflag |= syscall.ICRNL
+	if err := termios(fd, ioctlSetTermios, &noEcho); err != nil {
+		return "", err
+	}
+	defer termios(fd, ioctlSetTermios, &state) //nolint: errcheck
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func termios(fd uintptr, request uintptr, state *syscall.Termios) error {
+	if _, _, err := syscall.Syscall6(
+		syscall.SYS_IOCTL,
+		fd,
+		request,
+		uintptr(unsafe.Pointer(state)), //nolint: gas
+		0, 0, 0,
+	); err != 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package kong
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"sort"
+)
+
+// PluginSymbol is the symbol LoadPlugins looks up in each plugin.
+//
+// It must be a function with the signature:
+//
+//	func Commands() []kong.DynamicCommandSpec
+const PluginSymbol = "Commands"
+
+// DynamicCommandSpec describes a command provided by a Go plugin.
+//
+// The fields correspond to the arguments of DynamicCommand().
+type DynamicCommandSpec struct {
+	Name  string
+	Help  string
+	Group string
+	Cmd   any
+	Tags  []string
+}
+
+// LoadPlugins opens every Go plugin (*.so) in "dir" and mounts the commands it exposes.
+//
+// Each plugin must export PluginSymbol. Plugins are loaded in lexical order, and a missing
+// directory is not an error. "dir" will have ~ expanded.
+//
+// Go plugins must be built with the same toolchain and versions of any shared
+// packages, including kong, as the host binary.
+func LoadPlugins(dir string) Option {
+	return OptionFunc(func(k *Kong) error {
+		paths, err := filepath.Glob(filepath.Join(ExpandPath(dir), "*.so"))
+		if err != nil {
+			return err
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			p, err := plugin.Open(path)
+			if err != nil {
+				return fmt.Errorf("kong: plugin %s: %w", path, err)
+			}
+			sym, err := p.Lookup(PluginSymbol)
+			if err != nil {
+				return fmt.Errorf("kong: plugin %s: %w", path, err)
+			}
+			if err := mountPluginCommands(k, sym); err != nil {
+				return fmt.Errorf("kong: plugin %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+func mountPluginCommands(k *Kong, sym plugin.Symbol) error {
+	commands, ok := sym.(func() []DynamicCommandSpec)
+	if !ok {
+		return fmt.Errorf("%s must be a func() []kong.DynamicCommandSpec but is %T", PluginSymbol, sym)
+	}
+	for _, spec := range commands() {
+		if err := DynamicCommand(spec.Name, spec.Help, spec.Group, spec.Cmd, spec.Tags...).Apply(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
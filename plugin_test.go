@@ -0,0 +1,50 @@
+package kong
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+type pluginCmd struct {
+	Flag bool
+	ran  bool
+}
+
+func (p *pluginCmd) Run() error {
+	p.ran = true
+	return nil
+}
+
+func TestMountPluginCommands(t *testing.T) {
+	var cli struct{}
+	cmd := &pluginCmd{}
+	commands := func() []DynamicCommandSpec {
+		return []DynamicCommandSpec{{Name: "ext", Help: "An extension.", Cmd: cmd}}
+	}
+	k, err := New(&cli, OptionFunc(func(k *Kong) error { return mountPluginCommands(k, commands) }))
+	assert.NoError(t, err)
+	ctx, err := k.Parse([]string{"ext", "--flag"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.True(t, cmd.Flag)
+	assert.True(t, cmd.ran)
+
+	_, err = New(&cli, OptionFunc(func(k *Kong) error { return mountPluginCommands(k, "nope") }))
+	assert.EqualError(t, err, "Commands must be a func() []kong.DynamicCommandSpec but is string")
+}
+
+func TestLoadPlugins(t *testing.T) {
+	var cli struct{}
+	dir := t.TempDir()
+	_, err := New(&cli, LoadPlugins(dir))
+	assert.NoError(t, err)
+	_, err = New(&cli, LoadPlugins(filepath.Join(dir, "missing")))
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bad.so"), []byte("not a plugin"), 0600))
+	_, err = New(&cli, LoadPlugins(dir))
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package kong
+
+import (
+	"strings"
+)
+
+// PreprocessArgs registers a function that rewrites or rejects the command-line before it is parsed, eg. to
+// translate legacy syntax such as "-longopt" or old command names, without wrapping main().
+//
+// Functions run in the order they are registered, before command-line aliases are expanded. An error aborts
+// parsing.
+func PreprocessArgs(fn func(args []string) ([]string, error)) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.preprocessArgs = append(k.preprocessArgs, fn)
+		return nil
+	})
+}
+
+// RenamedArgs replaces arguments that were renamed, eg. {"-longopt": "--long-opt", "rm": "remove"}, warning that
+// the old name is deprecated. Flags given as "-longopt=value" are also replaced. Old command names are only replaced
+// where a command is expected, so positional arguments and flag values equal to one are kept. Arguments after "--"
+// are not replaced.
+func RenamedArgs(renames map[string]string) Option {
+	return OptionFunc(func(k *Kong) error {
+		return PreprocessArgs(func(args []string) ([]string, error) {
+			out := make([]string, 0, len(args))
+			node, commands := k.Model.Node, true
+			for i := 0; i < len(args); i++ {
+				arg := args[i]
+				if arg == "--" {
+					return append(out, args[i:]...), nil
+				}
+				name, value, hasValue := strings.Cut(arg, "=")
+				if !strings.HasPrefix(name, "-") {
+					if commands {
+						if renamed, ok := renames[arg]; ok && command(node, arg) == nil && command(node, renamed) != nil {
+							formatMultilineMessage(k.Stderr, []string{k.Model.Name, "warning"},
+								"%s is deprecated, use %s instead", arg, renamed)
+							arg = renamed
+						}
+						if child := commandChild(node, arg); child != nil {
+							node = child
+						} else {
+							commands = false
+						}
+					}
+					out = append(out, arg)
+					continue
+				}
+				if renamed, ok := renames[name]; ok {
+					formatMultilineMessage(k.Stderr, []string{k.Model.Name, "warning"}, "%s is deprecated, use %s instead",
+						name, renamed)
+					name, arg = renamed, renamed
+					if hasValue {
+						arg += "=" + value
+					}
+				}
+				out = append(out, arg)
+				if !hasValue && i+1 < len(args) && flagTakesValue(node, name) {
+					i++
+					out = append(out, args[i])
+				}
+			}
+			return out, nil
+		}).Apply(k)
+	})
+}
+
+// commandChild returns the child of "node" selected by the non-flag argument "arg": the command named "arg", or
+// else a branching argument. It returns nil if "arg" is a positional argument, after which no command follows.
+func commandChild(node *Node, arg string) *Node {
+	if child := command(node, arg); child != nil {
+		return child
+	}
+	for _, child := range node.Children {
+		if child.Type == ArgumentNode {
+			return child
+		}
+	}
+	return nil
+}
+
+// command returns the child command of "node" named or aliased "name", if any.
+func command(node *Node, name string) *Node {
+	for _, child := range node.Children {
+		if child.Type != CommandNode {
+			continue
+		}
+		if child.Name == name {
+			return child
+		}
+		for _, alias := range child.Aliases {
+			if alias == name {
+				return child
+			}
+		}
+	}
+	return nil
+}
+
+// flagTakesValue returns true if "key", eg. "--name" or "-n", is a flag of "node" or its ancestors that consumes the
+// following argument as its value.
+func flagTakesValue(node *Node, key string) bool {
+	for ; node != nil; node = node.Parent {
+		for _, flag := range node.Flags {
+			for _, k := range flag.keys() {
+				if k == key {
+					return !flag.IsBool() && !flag.IsCounter()
+				}
+			}
+		}
+	}
+	return false
+}
+
+// preprocess the command-line with the functions registered with PreprocessArgs.
+func (k *Kong) preprocess(args []string) ([]string, error) {
+	var err error
+	for _, fn := range k.preprocessArgs {
+		if args, err = fn(args); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
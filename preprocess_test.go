@@ -0,0 +1,82 @@
+package kong_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestPreprocessArgs(t *testing.T) {
+	var cli struct {
+		LongOpt string
+		Args    []string `arg:"" optional:""`
+	}
+	calls := []string{}
+	p := mustNew(t, &cli,
+		kong.PreprocessArgs(func(args []string) ([]string, error) {
+			calls = append(calls, "first")
+			for _, arg := range args {
+				if arg == "--forbidden" {
+					return nil, errors.New("--forbidden is not supported")
+				}
+			}
+			return append(args, "appended"), nil
+		}),
+		kong.PreprocessArgs(func(args []string) ([]string, error) {
+			calls = append(calls, "second:"+strings.Join(args, " "))
+			return args, nil
+		}))
+
+	_, err := p.Parse([]string{"--long-opt=x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "x", cli.LongOpt)
+	assert.Equal(t, []string{"appended"}, cli.Args)
+	assert.Equal(t, []string{"first", "second:--long-opt=x appended"}, calls)
+
+	_, err = p.Parse([]string{"--forbidden"})
+	assert.EqualError(t, err, "--forbidden is not supported")
+}
+
+func TestRenamedArgs(t *testing.T) {
+	var cli struct {
+		LongOpt string
+		Remove  struct {
+			Args []string `arg:"" optional:""`
+		} `cmd:""`
+	}
+	stderr := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(&bytes.Buffer{}, stderr),
+		kong.RenamedArgs(map[string]string{"-longopt": "--long-opt", "rm": "remove"}))
+
+	_, err := p.Parse([]string{"-longopt=x", "rm", "--", "rm", "-longopt"})
+	assert.NoError(t, err)
+	assert.Equal(t, "x", cli.LongOpt)
+	assert.Equal(t, []string{"rm", "-longopt"}, cli.Remove.Args)
+	assert.Equal(t, "test: warning: -longopt is deprecated, use --long-opt instead\n"+
+		"test: warning: rm is deprecated, use remove instead\n", stderr.String())
+}
+
+func TestRenamedArgsCommandPosition(t *testing.T) {
+	var cli struct {
+		Name   string
+		Remove struct {
+			Force bool
+			Label string
+			Args  []string `arg:"" optional:""`
+		} `cmd:""`
+	}
+	stderr := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Writers(&bytes.Buffer{}, stderr), kong.RenamedArgs(map[string]string{"rm": "remove"}))
+
+	_, err := p.Parse([]string{"--name", "rm", "rm", "--force", "--label", "rm", "rm", "x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "rm", cli.Name)
+	assert.Equal(t, "rm", cli.Remove.Label)
+	assert.Equal(t, []string{"rm", "x"}, cli.Remove.Args)
+	assert.Equal(t, "test: warning: rm is deprecated, use remove instead\n", stderr.String())
+}
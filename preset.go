@@ -0,0 +1,60 @@
+package kong
+
+// Preset bundles several options into one, so parsing behaviour can be standardised across many binaries, eg.
+//
+//	var CompanyDefaults = kong.Preset(kong.GNUPreset(), kong.UsageOnError(), kong.AutoVersion())
+//
+// Options are applied in order, so later options override earlier ones.
+func Preset(options ...Option) Option {
+	return OptionFunc(func(k *Kong) error {
+		for _, option := range options {
+			if err := option.Apply(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// POSIXPreset configures POSIX utility conventions: flags must precede positional arguments, negative numbers are
+// values rather than flags where unambiguous, and placeholders are upper-cased, eg. "--count=INT".
+func POSIXPreset() Option {
+	return Preset(
+		NegativeNumbers(NegativeNumbersAuto),
+		PlaceHolders(UpperCasePlaceHolders),
+		PostBuild(func(k *Kong) error {
+			return Visit(k.Model, func(node Visitable, next Next) error {
+				switch node := node.(type) {
+				case *Application:
+					node.Tag.NonInterspersed = true
+				case *Node:
+					if node.Tag != nil {
+						node.Tag.NonInterspersed = true
+					}
+				}
+				return next(nil)
+			})
+		}),
+	)
+}
+
+// GNUPreset configures GNU conventions: flags may be interspersed with positional arguments, boolean flags that
+// default to true can be negated with --no-flag, negative numbers are values where unambiguous, and placeholders
+// are upper-cased.
+func GNUPreset() Option {
+	return Preset(
+		NegationPrefix("no-"),
+		AutoNegatable(),
+		NegativeNumbers(NegativeNumbersAuto),
+		PlaceHolders(UpperCasePlaceHolders),
+	)
+}
+
+// WindowsPreset configures Windows conventions: flags may also be given as /flag and /flag:value and are matched
+// case-insensitively, /? shows help, and placeholders describe types, eg. "--count=<int>".
+func WindowsPreset() Option {
+	return Preset(
+		SlashFlags(),
+		PlaceHolders(TypePlaceHolders),
+	)
+}
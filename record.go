@@ -0,0 +1,178 @@
+package kong
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Invocation is a record of a single successfully parsed command-line.
+//
+// Invocations are written as JSON, one per line, and can be replayed through
+// the same parser with Kong.Replay(). This is useful for reproducing bug
+// reports and for building regression tests from real usage.
+type Invocation struct {
+	// Command is the selected command path, eg. "user create <id>".
+	Command string `json:"command,omitempty"`
+	// Flags contains the values of flags explicitly set on the command-line, keyed by flag name.
+	Flags map[string]string `json:"flags,omitempty"`
+	// Args are the original command-line arguments.
+	Args []string `json:"args"`
+}
+
+// NewInvocation creates an Invocation from a parsed Context.
+//
+// Values of flags tagged as secret are masked.
+func NewInvocation(ctx *Context) *Invocation {
+	inv := &Invocation{
+		Command: ctx.Command(),
+		Flags:   map[string]string{},
+		// Secrets are also masked in the original arguments, so must be provided by other means on replay.
+		Args: ctx.redactArgs(),
+	}
+	for _, path := range ctx.Path {
+		if path.Flag == nil || path.Resolved {
+			continue
+		}
+		inv.Flags[path.Flag.Name] = path.Flag.FormatValue(path.Flag.Target)
+	}
+	return inv
+}
+
+// WriteInvocation appends a JSON encoded Invocation for ctx to w.
+func WriteInvocation(w io.Writer, ctx *Context) error {
+	return json.NewEncoder(w).Encode(NewInvocation(ctx))
+}
+
+// ReadInvocations reads all Invocations previously written with WriteInvocation.
+func ReadInvocations(r io.Reader) ([]*Invocation, error) {
+	out := []*Invocation{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		inv := &Invocation{}
+		if err := json.Unmarshal(scanner.Bytes(), inv); err != nil {
+			return nil, fmt.Errorf("invocation %d: %w", line, err)
+		}
+		out = append(out, inv)
+	}
+	return out, scanner.Err()
+}
+
+// Replay parses a previously recorded Invocation.
+func (k *Kong) Replay(inv *Invocation) (*Context, error) {
+	return k.Parse(inv.Args)
+}
+
+// RecordInvocations appends every successfully parsed command-line to the file at "path".
+//
+// "path" will have ~ and any variables expanded.
+func RecordInvocations(path string) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.recordPath = path
+		return nil
+	})
+}
+
+func (k *Kong) recordInvocation(ctx *Context) error {
+	// Replaying these would be confusing at best, and RerunCommand replaying itself would never end.
+	if selected := ctx.Selected(); selected != nil && selected.Target.IsValid() {
+		switch selected.Target.Interface().(type) {
+		case HistoryCommand, RerunCommand:
+			return nil
+		}
+	}
+	path, err := interpolate(ExpandPath(k.recordPath), k.vars, nil)
+	if err != nil {
+		return err
+	}
+	w, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) //nolint: gosec
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return WriteInvocation(w, ctx)
+}
+
+// History returns the Invocations recorded with RecordInvocations, oldest first.
+func (k *Kong) History() ([]*Invocation, error) {
+	if k.recordPath == "" {
+		return nil, fmt.Errorf("history requires the RecordInvocations() option")
+	}
+	path, err := interpolate(ExpandPath(k.recordPath), k.vars, nil)
+	if err != nil {
+		return nil, err
+	}
+	r, err := os.Open(path) //nolint: gosec
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ReadInvocations(r)
+}
+
+// HistoryCommand lists the invocations recorded with RecordInvocations, numbered for use with RerunCommand.
+//
+// Invocations of HistoryCommand and RerunCommand themselves are not recorded.
+//
+// Add it to a CLI as eg. a "history" command.
+type HistoryCommand struct {
+	Last int `short:"n" help:"Only list the last N invocations." placeholder:"N"`
+}
+
+// Run writes the numbered invocations to Kong.Stdout.
+func (h HistoryCommand) Run(app *Kong) error {
+	history, err := app.History()
+	if err != nil {
+		return err
+	}
+	start := 0
+	if h.Last > 0 && h.Last < len(history) {
+		start = len(history) - h.Last
+	}
+	for i := start; i < len(history); i++ {
+		args := make([]string, len(history[i].Args))
+		for j, arg := range history[i].Args {
+			args[j] = quoteArg(arg)
+		}
+		fmt.Fprintf(app.Stdout, "%5d  %s\n", i+1, strings.Join(args, " "))
+	}
+	return nil
+}
+
+// RerunCommand replays an invocation recorded with RecordInvocations through the parser and runs it.
+//
+// Add it to a CLI as eg. a "rerun" command. Values passed to Context.Run() are not available to the replayed
+// command, so it should only rely on bindings registered with options such as Bind(). Secret values are masked
+// when recorded, so must be provided by other means, such as envars.
+type RerunCommand struct {
+	N int `arg:"" optional:"" help:"Number of the invocation to run again, as listed by history. Defaults to the latest." placeholder:"N"`
+}
+
+// Run replays and runs the invocation.
+func (r RerunCommand) Run(app *Kong) error {
+	history, err := app.History()
+	if err != nil {
+		return err
+	}
+	n := r.N
+	if n == 0 {
+		n = len(history)
+	}
+	if n < 1 || n > len(history) {
+		return fmt.Errorf("no invocation %d in history", r.N)
+	}
+	ctx, err := app.Replay(history[n-1])
+	if err != nil {
+		return err
+	}
+	return ctx.Run()
+}
@@ -0,0 +1,155 @@
+package kong_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestRecordAndReplayInvocations(t *testing.T) {
+	var cli struct {
+		Debug bool
+		User  struct {
+			Name  string `arg:""`
+			Admin bool
+		} `cmd:""`
+	}
+	path := filepath.Join(t.TempDir(), "invocations.jsonl")
+	p := mustNew(t, &cli, kong.RecordInvocations(path))
+	_, err := p.Parse([]string{"--debug", "user", "alice", "--admin"})
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"user", "bob"})
+	assert.NoError(t, err)
+
+	r, err := os.Open(path)
+	assert.NoError(t, err)
+	defer r.Close()
+	invocations, err := kong.ReadInvocations(r)
+	assert.NoError(t, err)
+	assert.Equal(t, []*kong.Invocation{
+		{Command: "user <name>", Flags: map[string]string{"debug": "true", "admin": "true"}, Args: []string{"--debug", "user", "alice", "--admin"}},
+		{Command: "user <name>", Args: []string{"user", "bob"}},
+	}, invocations)
+
+	ctx, err := p.Replay(invocations[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "user <name>", ctx.Command())
+	assert.True(t, cli.Debug)
+	assert.True(t, cli.User.Admin)
+	assert.Equal(t, "alice", cli.User.Name)
+}
+
+func TestWriteInvocation(t *testing.T) {
+	var cli struct {
+		Count int `default:"1"`
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"--count=3"})
+	assert.NoError(t, err)
+	w := &bytes.Buffer{}
+	assert.NoError(t, kong.WriteInvocation(w, ctx))
+	assert.Equal(t, `{"flags":{"count":"3"},"args":["--count=3"]}`+"\n", w.String())
+}
+
+func TestInvocationMasksSecrets(t *testing.T) {
+	var cli struct {
+		Token string `secret:""`
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"--token=hunter2"})
+	assert.NoError(t, err)
+	inv := kong.NewInvocation(ctx)
+	assert.Equal(t, map[string]string{"token": "********"}, inv.Flags)
+	assert.Equal(t, []string{"--token=********"}, inv.Args)
+}
+
+func TestInvocationMasksOnlySecretArgs(t *testing.T) {
+	var cli struct {
+		Token string `secret:"" short:"t"`
+		Name  string
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"-t", "x", "--name=alex"})
+	assert.NoError(t, err)
+	inv := kong.NewInvocation(ctx)
+	assert.Equal(t, []string{"-t", "********", "--name=alex"}, inv.Args)
+}
+
+func TestInvocationMasksSecretForms(t *testing.T) {
+	var cli struct {
+		Token   string   `secret:"" short:"t" aliases:"tok"`
+		Verbose bool     `short:"v"`
+		Args    []string `arg:"" optional:""`
+	}
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"-thunter2"}, []string{"-t********"}},
+		{[]string{"-vthunter2", "a"}, []string{"-vt********", "a"}},
+		{[]string{"-vt", "hunter2"}, []string{"-vt", "********"}},
+		{[]string{"--tok", "hunter2", "a"}, []string{"--tok", "********", "a"}},
+		{[]string{"--tok=hunter2"}, []string{"--tok=********"}},
+		{[]string{"/token:hunter2", "/tmp"}, []string{"/token:********", "/tmp"}},
+	}
+	for _, test := range tests {
+		p := mustNew(t, &cli, kong.SlashFlags())
+		ctx, err := p.Parse(test.args)
+		assert.NoError(t, err, "%v", test.args)
+		assert.Equal(t, test.expected, kong.NewInvocation(ctx).Args, "%v", test.args)
+	}
+}
+
+type historyGreetCmd struct {
+	Name string `arg:""`
+}
+
+func (g historyGreetCmd) Run(app *kong.Kong) error {
+	app.Printf("hello %s", g.Name)
+	return nil
+}
+
+func TestHistoryAndRerunCommands(t *testing.T) {
+	var cli struct {
+		Greet   historyGreetCmd     `cmd:""`
+		History kong.HistoryCommand `cmd:""`
+		Rerun   kong.RerunCommand   `cmd:""`
+	}
+	w := &bytes.Buffer{}
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	p := mustNew(t, &cli, kong.RecordInvocations(path), kong.Writers(w, w))
+	run := func(args ...string) error {
+		ctx, err := p.Parse(args)
+		if err != nil {
+			return err
+		}
+		return ctx.Run()
+	}
+	assert.NoError(t, run("greet", "alice"))
+	assert.NoError(t, run("greet", "bob smith"))
+	w.Reset()
+	assert.NoError(t, run("history"))
+	assert.Equal(t, "    1  greet alice\n    2  greet \"bob smith\"\n", w.String())
+	w.Reset()
+	assert.NoError(t, run("history", "-n", "1"))
+	assert.Equal(t, "    2  greet \"bob smith\"\n", w.String())
+
+	w.Reset()
+	assert.NoError(t, run("rerun", "1"))
+	assert.Equal(t, "test: hello alice\n", w.String())
+
+	// Without N, the latest invocation other than history and rerun is run again. The replay is itself recorded.
+	w.Reset()
+	assert.NoError(t, run("rerun"))
+	assert.Equal(t, "test: hello alice\n", w.String())
+	w.Reset()
+	assert.NoError(t, run("history"))
+	assert.Equal(t, "    1  greet alice\n    2  greet \"bob smith\"\n    3  greet alice\n    4  greet alice\n", w.String())
+
+	assert.EqualError(t, run("rerun", "42"), "no invocation 42 in history")
+}
@@ -0,0 +1,93 @@
+package kong
+
+import (
+	"strings"
+)
+
+// RelationKind is the kind of relationship between flags.
+type RelationKind string
+
+// Relationships between flags.
+const (
+	// RelationXor flags are mutually exclusive, from the "xor" tag.
+	RelationXor RelationKind = "xor"
+	// RelationAnd flags must be given together, from the "and" tag.
+	RelationAnd RelationKind = "and"
+	// RelationRequires flags are required by some commands, from the "requiredif" tag.
+	RelationRequires RelationKind = "requires"
+)
+
+// FlagRelation describes the relationship of a flag to the other flags of a group, or to the commands that require
+// it, so that help, completion and documentation can describe it without interpreting tags.
+type FlagRelation struct {
+	Kind     RelationKind
+	Group    string   // Name of the group, eg. "format" for `xor:"format"`, including any xorprefix.
+	Flags    []*Flag  // The other flags in the group, in declaration order.
+	Commands []string // Full paths of the commands requiring the flag, eg. "cluster deploy", for RelationRequires.
+}
+
+// String describes the relation, eg. "mutually exclusive with --json, --yaml".
+func (r FlagRelation) String() string {
+	if r.Kind == RelationRequires {
+		return "required by " + strings.Join(r.Commands, ", ")
+	}
+	names := make([]string, len(r.Flags))
+	for i, flag := range r.Flags {
+		names[i] = "--" + flag.Name
+	}
+	if r.Kind == RelationAnd {
+		return "requires " + strings.Join(names, ", ")
+	}
+	return "mutually exclusive with " + strings.Join(names, ", ")
+}
+
+// annotateRelations sets Flag.Relations from the xor and and groups of the flags of each node, and from their
+// requiredif tags. As when they are validated, groups only relate flags declared on the same node.
+func annotateRelations(node *Node) {
+	_ = Visit(node, func(v Visitable, next Next) error {
+		n, ok := v.(*Node)
+		if !ok {
+			return next(nil)
+		}
+		for _, flag := range n.Flags {
+			flag.Relations = nil
+		}
+		for _, kind := range []RelationKind{RelationXor, RelationAnd} {
+			order := []string{}
+			groups := map[string][]*Flag{}
+			for _, flag := range n.Flags {
+				names := flag.Xor
+				if kind == RelationAnd {
+					names = flag.And
+				}
+				for _, name := range names {
+					if _, ok := groups[name]; !ok {
+						order = append(order, name)
+					}
+					groups[name] = append(groups[name], flag)
+				}
+			}
+			for _, name := range order {
+				members := groups[name]
+				for _, flag := range members {
+					others := []*Flag{}
+					for _, other := range members {
+						if other != flag {
+							others = append(others, other)
+						}
+					}
+					if len(others) > 0 {
+						flag.Relations = append(flag.Relations, FlagRelation{Kind: kind, Group: name, Flags: others})
+					}
+				}
+			}
+		}
+		for _, flag := range n.Flags {
+			if len(flag.Tag.RequiredIf) > 0 {
+				commands := append([]string{}, flag.Tag.RequiredIf...)
+				flag.Relations = append(flag.Relations, FlagRelation{Kind: RelationRequires, Commands: commands})
+			}
+		}
+		return next(nil)
+	})
+}
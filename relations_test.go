@@ -0,0 +1,48 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestFlagRelations(t *testing.T) {
+	var cli struct {
+		JSON  bool   `xor:"format"`
+		YAML  bool   `xor:"format"`
+		Table bool   `xor:"format,style"`
+		Plain bool   `xor:"style"`
+		User  string `and:"auth"`
+		Pass  string `and:"auth"`
+		Alone bool   `xor:"single"`
+		Zone  string `requiredif:"cmd=sub,sub deep"`
+		Sub   struct {
+			Other bool     `xor:"format"`
+			Deep  struct{} `cmd:""`
+		} `cmd:""`
+	}
+	p := mustNew(t, &cli)
+	flags := map[string]*kong.Flag{}
+	for _, flag := range p.Model.Flags {
+		flags[flag.Name] = flag
+	}
+
+	relations := func(name string) []string {
+		out := []string{}
+		for _, relation := range flags[name].Relations {
+			out = append(out, string(relation.Kind)+":"+relation.Group+": "+relation.String())
+		}
+		return out
+	}
+	assert.Equal(t, []string{"xor:format: mutually exclusive with --yaml, --table"}, relations("json"))
+	assert.Equal(t, []string{
+		"xor:format: mutually exclusive with --json, --yaml",
+		"xor:style: mutually exclusive with --plain",
+	}, relations("table"))
+	assert.Equal(t, []string{"and:auth: requires --pass"}, relations("user"))
+	assert.Equal(t, []string{}, relations("alone"))
+	assert.Equal(t, []string{"requires:: required by sub, sub deep"}, relations("zone"))
+	assert.Equal(t, 0, len(p.Model.Children[0].Flags[0].Relations))
+}
@@ -0,0 +1,247 @@
+package kong
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// REPLOption configures REPL.
+type REPLOption func(r *repl) error
+
+// REPLPrompt sets the prompt displayed before each line. Defaults to "<name>> ".
+func REPLPrompt(prompt string) REPLOption {
+	return func(r *repl) error {
+		r.prompt = prompt
+		return nil
+	}
+}
+
+// REPLInput sets the reader lines are read from. Defaults to os.Stdin.
+func REPLInput(in io.Reader) REPLOption {
+	return func(r *repl) error {
+		r.readLine = lineReader(in, r.parser.Stdout)
+		return nil
+	}
+}
+
+// REPLReadLine replaces the line reader.
+//
+// This can be used to plug in a line editor with tab completion, using Complete() to
+// provide candidates from the grammar. io.EOF ends the session.
+func REPLReadLine(readLine func(prompt string) (string, error)) REPLOption {
+	return func(r *repl) error {
+		r.readLine = readLine
+		return nil
+	}
+}
+
+// REPLHistoryFile loads history from, and appends each line to, the file at "path".
+//
+// "path" will have ~ and any variables expanded.
+func REPLHistoryFile(path string) REPLOption {
+	return func(r *repl) error {
+		path, err := interpolate(ExpandPath(path), r.parser.vars, nil)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path) //nolint: gosec
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				r.history = append(r.history, line)
+			}
+		}
+		r.historyPath = path
+		return nil
+	}
+}
+
+type repl struct {
+	parser      *Kong
+	prompt      string
+	readLine    func(prompt string) (string, error)
+	history     []string
+	historyPath string
+}
+
+type replExit struct{ code int }
+
+// REPL runs an interactive session over the command tree of "parser".
+//
+// Each line is split into words as a shell would, parsed with the grammar and the
+// selected command run in-process. Errors are reported and the session continues. The
+// builtin commands "history" and "exit" are also available, and the session ends on EOF.
+func REPL(parser *Kong, options ...REPLOption) error {
+	r := &repl{
+		parser:   parser,
+		prompt:   parser.Model.Name + "> ",
+		readLine: lineReader(os.Stdin, parser.Stdout),
+	}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return err
+		}
+	}
+
+	// Help and errors call Exit, which must not terminate the session.
+	exit := parser.Exit
+	defer func() { parser.Exit = exit }()
+	parser.Exit = func(code int) { panic(replExit{code}) }
+
+	for {
+		line, err := r.readLine(r.prompt)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if err := r.record(line); err != nil {
+			return err
+		}
+		switch line {
+		case "exit", "quit":
+			return nil
+		case "history":
+			for i, entry := range r.history {
+				fmt.Fprintf(parser.Stdout, "%5d  %s\n", i+1, entry)
+			}
+			continue
+		}
+		r.run(line)
+	}
+}
+
+func (r *repl) record(line string) error {
+	r.history = append(r.history, line)
+	if r.historyPath == "" {
+		return nil
+	}
+	w, err := os.OpenFile(r.historyPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) //nolint: gosec
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	_, err = fmt.Fprintln(w, line)
+	return err
+}
+
+func (r *repl) run(line string) {
+	defer func() {
+		if v := recover(); v != nil {
+			if _, ok := v.(replExit); !ok {
+				panic(v)
+			}
+		}
+	}()
+	args, err := splitShellWords(line)
+	if err != nil {
+		r.parser.Errorf("%s", err)
+		return
+	}
+	// FatalIfErrorf reports errors as the parser is configured to, eg. with usage, then "exits".
+	ctx, err := r.parser.Parse(args)
+	r.parser.FatalIfErrorf(err)
+	r.parser.FatalIfErrorf(ctx.Run())
+}
+
+func lineReader(in io.Reader, prompt io.Writer) func(string) (string, error) {
+	scanner := bufio.NewScanner(in)
+	return func(p string) (string, error) {
+		fmt.Fprint(prompt, p)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return scanner.Text(), nil
+	}
+}
+
+// Complete returns completion candidates for the last word of "line".
+//
+// The words before it are traced with the grammar of "parser", and candidates are those of Context.Suggest: in
+// grammar order, subcommands and their aliases, flags, and enum values. Hidden commands and flags are not offered.
+func Complete(parser *Kong, line string) []string {
+	words, _ := splitShellWords(line)
+	partial := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	candidates := []string{}
+	ctx, err := Trace(parser, words)
+	if err != nil {
+		return candidates
+	}
+	for _, candidate := range ctx.Suggest(partial) {
+		candidates = append(candidates, candidate.Value)
+	}
+	return candidates
+}
+
+func hasAlias(node *Node, name string) bool {
+	for _, alias := range node.Aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitShellWords splits "line" into words, honouring shell-style quotes and backslash escapes.
+func splitShellWords(line string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
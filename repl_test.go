@@ -0,0 +1,73 @@
+package kong_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type replGreetCmd struct {
+	Name  string `arg:""`
+	Shout bool
+	out   *[]string
+}
+
+func (g *replGreetCmd) Run() error {
+	greeting := "hello " + g.Name
+	if g.Shout {
+		greeting = strings.ToUpper(greeting)
+	}
+	*g.out = append(*g.out, greeting)
+	return nil
+}
+
+func TestREPL(t *testing.T) {
+	var out []string
+	var cli struct {
+		Greet  replGreetCmd `cmd:""`
+		Secret struct{}     `cmd:"" hidden:""`
+	}
+	cli.Greet.out = &out
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	exited := false
+	p := mustNew(t, &cli, kong.Name("app"), kong.Writers(stdout, stderr), kong.Exit(func(int) { exited = true }))
+	history := filepath.Join(t.TempDir(), "history")
+	input := strings.NewReader("greet alice\n\ngreet 'bob smith' --shout\ngreet\n--help\nhistory\ngreet carol\n")
+	err := kong.REPL(p, kong.REPLInput(input), kong.REPLHistoryFile(history))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello alice", "HELLO BOB SMITH", "hello carol"}, out)
+	assert.Contains(t, stderr.String(), "app: error: missing positional arguments <name>")
+	assert.Contains(t, stdout.String(), "Usage: app <command>")
+	assert.Contains(t, stdout.String(), "    2  greet 'bob smith' --shout\n")
+	assert.False(t, exited)
+
+	data, err := os.ReadFile(history)
+	assert.NoError(t, err)
+	assert.Equal(t, "greet alice\ngreet 'bob smith' --shout\ngreet\n--help\nhistory\ngreet carol\n", string(data))
+}
+
+func TestComplete(t *testing.T) {
+	var cli struct {
+		Verbose bool
+		Greet   struct {
+			Shout bool
+			Color string `enum:"red,blue" default:"red"`
+			Name  string `arg:""`
+		} `cmd:"" aliases:"g"`
+		Get    struct{} `cmd:""`
+		Secret struct{} `cmd:"" hidden:""`
+	}
+	p := mustNew(t, &cli)
+	assert.Equal(t, []string{"greet", "g", "get"}, kong.Complete(p, ""))
+	assert.Equal(t, []string{"greet"}, kong.Complete(p, "gr"))
+	assert.Equal(t, []string{"--help", "--verbose", "--shout", "--color"}, kong.Complete(p, "g --"))
+	assert.Equal(t, []string{"--shout"}, kong.Complete(p, "greet --s"))
+	assert.Equal(t, []string{"--color=red", "--color=blue"}, kong.Complete(p, "greet --color="))
+}
@@ -3,6 +3,7 @@ package kong
 import (
 	"encoding/json"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -27,6 +28,12 @@ func (r ResolverFunc) Resolve(context *Context, parent *Path, flag *Flag) (any,
 }
 func (r ResolverFunc) Validate(app *Application) error { return nil } //nolint: revive
 
+// UnusedKeysReporter can be implemented by a Resolver to report the keys it holds that match none of the flags on
+// the selected command path. See UnusedConfigKeys.
+type UnusedKeysReporter interface {
+	UnusedKeys(context *Context) []string
+}
+
 // JSON returns a Resolver that retrieves values from a JSON source.
 //
 // Flag names are used as JSON keys indirectly, by tring snake_case and camelCase variants.
@@ -36,30 +43,61 @@ func JSON(r io.Reader) (Resolver, error) {
 	if err != nil {
 		return nil, err
 	}
-	var f ResolverFunc = func(context *Context, parent *Path, flag *Flag) (any, error) {
-		name := strings.ReplaceAll(flag.Name, "-", "_")
-		snakeCaseName := snakeCase(flag.Name)
-		raw, ok := values[name]
-		if ok {
-			return raw, nil
-		} else if raw, ok = values[snakeCaseName]; ok {
-			return raw, nil
-		}
-		raw = values
-		for _, part := range strings.Split(name, ".") {
-			if values, ok := raw.(map[string]any); ok {
-				raw, ok = values[part]
-				if !ok {
-					return nil, nil
-				}
-			} else {
+	return jsonResolver(values), nil
+}
+
+type jsonResolver map[string]any
+
+func (j jsonResolver) Validate(app *Application) error { return nil } //nolint: revive
+
+func (j jsonResolver) Resolve(context *Context, parent *Path, flag *Flag) (any, error) { //nolint: revive
+	name := strings.ReplaceAll(flag.Name, "-", "_")
+	snakeCaseName := snakeCase(flag.Name)
+	raw, ok := j[name]
+	if ok {
+		return raw, nil
+	} else if raw, ok = j[snakeCaseName]; ok {
+		return raw, nil
+	}
+	raw = map[string]any(j)
+	for _, part := range strings.Split(name, ".") {
+		if values, ok := raw.(map[string]any); ok {
+			raw, ok = values[part]
+			if !ok {
 				return nil, nil
 			}
+		} else {
+			return nil, nil
 		}
-		return raw, nil
 	}
+	return raw, nil
+}
 
-	return f, nil
+// UnusedKeys returns the keys, nested keys joined with ".", that match no flag on the selected command path.
+func (j jsonResolver) UnusedKeys(context *Context) []string {
+	names := map[string]bool{}
+	for _, flag := range context.Flags() {
+		names[strings.ReplaceAll(flag.Name, "-", "_")] = true
+		names[snakeCase(flag.Name)] = true
+	}
+	unused := []string{}
+	var walk func(prefix string, values map[string]any)
+	walk = func(prefix string, values map[string]any) {
+		for key, value := range values {
+			key = prefix + key
+			if names[key] {
+				continue
+			}
+			if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+				walk(key+".", nested)
+				continue
+			}
+			unused = append(unused, key)
+		}
+	}
+	walk("", j)
+	sort.Strings(unused)
+	return unused
 }
 
 func snakeCase(name string) string {
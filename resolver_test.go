@@ -365,3 +365,113 @@ func TestValidatingResolverErrors(t *testing.T) {
 	_, err := mustNew(t, &cli, kong.Resolvers(resolver)).Parse(nil)
 	assert.EqualError(t, err, "invalid")
 }
+
+func TestEnvarsCommandEnvPrefix(t *testing.T) {
+	type Start struct {
+		Port    int
+		Host    string `env:"BIND"`
+		NoEnv   string `env:"-"`
+		LogFile string
+	}
+	var cli struct {
+		Server struct {
+			Start Start `cmd:"" envprefix:"START_"`
+		} `cmd:"" envprefix:"MYAPP_SERVER_"`
+		Other struct {
+			Port int
+		} `cmd:""`
+	}
+	parser := newEnvParser(t, &cli, envMap{
+		"MYAPP_SERVER_START_PORT":     "8080",
+		"MYAPP_SERVER_START_BIND":     "localhost",
+		"MYAPP_SERVER_START_LOG_FILE": "/tmp/log",
+		"PORT":                        "1234",
+	})
+	_, err := parser.Parse([]string{"server", "start"})
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, cli.Server.Start.Port)
+	assert.Equal(t, "localhost", cli.Server.Start.Host)
+	assert.Equal(t, "/tmp/log", cli.Server.Start.LogFile)
+	assert.Equal(t, "", cli.Server.Start.NoEnv)
+	assert.Equal(t, 0, cli.Other.Port)
+}
+
+func TestEnvarsFallbackChain(t *testing.T) {
+	var cli struct {
+		Dir   string `env:"NEW_DIR,LEGACY_DIR" expandenv:""`
+		Plain string `env:"NEW_PLAIN,LEGACY_PLAIN"`
+	}
+	parser := newEnvParser(t, &cli, envMap{
+		"KONG_HOME":    "/home/kong",
+		"LEGACY_DIR":   "${KONG_HOME}/data",
+		"LEGACY_PLAIN": "${KONG_HOME}",
+	})
+	ctx, err := parser.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/kong/data", cli.Dir)
+	assert.Equal(t, "${KONG_HOME}", cli.Plain)
+	envs := map[string]string{}
+	for _, flag := range ctx.Model.Flags {
+		envs[flag.Name] = flag.EnvVar
+	}
+	assert.Equal(t, map[string]string{"help": "", "dir": "LEGACY_DIR", "plain": "LEGACY_PLAIN"}, envs)
+
+	t.Setenv("NEW_DIR", "/new")
+	_, err = parser.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/new", cli.Dir)
+}
+
+func TestUnusedConfigKeys(t *testing.T) {
+	var cli struct {
+		Verbose bool
+		Labels  map[string]string
+		Deploy  struct {
+			Region string
+		} `cmd:""`
+		Build struct {
+			Fast bool
+		} `cmd:""`
+	}
+	config := `{"verbos": true, "labels": {"a": "b"}, "region": "eu", "fast": true, "server": {"hots": "x"}}`
+	r, err := kong.JSON(strings.NewReader(config))
+	assert.NoError(t, err)
+
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Name("test"), kong.Writers(w, w), kong.Resolvers(r), kong.UnusedConfigKeys(kong.WarnUnusedConfig))
+	_, err = p.Parse([]string{"deploy"})
+	assert.NoError(t, err)
+	assert.Equal(t, "eu", cli.Deploy.Region)
+	assert.Equal(t, "test: warning: unknown configuration keys: fast, server.hots, verbos\n", w.String())
+
+	p = mustNew(t, &cli, kong.Resolvers(r), kong.UnusedConfigKeys(kong.RejectUnusedConfig))
+	_, err = p.Parse([]string{"build"})
+	assert.EqualError(t, err, "unknown configuration keys: region, server.hots, verbos")
+
+	p = mustNew(t, &cli, kong.Resolvers(r))
+	_, err = p.Parse([]string{"build"})
+	assert.NoError(t, err)
+}
+
+func TestUnusedEnvars(t *testing.T) {
+	var cli struct {
+		Region string
+		Debug  bool `env:"KONGTEST_VERBOSE"`
+	}
+	t.Setenv("KONGTEST_REGION", "eu")
+	t.Setenv("KONGTEST_VERBOSE", "true")
+	t.Setenv("KONGTEST_ZONE", "a")
+	t.Setenv("KONGTESTS_OTHER", "x")
+
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Name("test"), kong.Writers(w, w), kong.DefaultEnvars("KONGTEST"),
+		kong.UnusedEnvars("KONGTEST", kong.WarnUnusedConfig))
+	_, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu", cli.Region)
+	assert.Equal(t, "test: warning: unknown environment variables: KONGTEST_ZONE\n", w.String())
+
+	p = mustNew(t, &cli, kong.DefaultEnvars("KONGTEST"), kong.UnusedEnvars("KONGTEST_", kong.RejectUnusedConfig))
+	_, err = p.Parse(nil)
+	assert.EqualError(t, err, "unknown environment variables: KONGTEST_ZONE")
+}
@@ -0,0 +1,115 @@
+package kong
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy of a command, set with the "retry" tag, eg. `retry:"3,backoff=1s"`.
+//
+// The command's Run() method is retried when it fails with an error implementing Temporary() returning true.
+type RetryPolicy struct {
+	// Retries is the maximum number of retries after the first attempt.
+	Retries int
+	// Backoff is the delay before the first retry, doubling for each subsequent retry.
+	Backoff time.Duration
+}
+
+// RetryEvent describes a failed attempt to run a command that is about to be retried. See OnRetry.
+type RetryEvent struct {
+	// Command being run, as returned by Context.Command().
+	Command string
+	// Attempt that failed, starting at 1.
+	Attempt int
+	// Retries is the maximum number of retries, as configured by the "retry" tag.
+	Retries int
+	// Delay before the next attempt.
+	Delay time.Duration
+	Err   error
+}
+
+// OnRetry calls "fn" before each retry of a command with a "retry" tag, eg. for logging.
+//
+// By default a warning is written to Kong.Stderr.
+func OnRetry(fn func(RetryEvent)) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.onRetry = fn
+		return nil
+	})
+}
+
+func parseRetry(s string) (*RetryPolicy, error) {
+	parts := strings.Split(s, ",")
+	retries, err := strconv.Atoi(parts[0])
+	if err != nil || retries < 0 {
+		return nil, fmt.Errorf("invalid retry %q, must be a non-negative number of retries", s)
+	}
+	policy := &RetryPolicy{Retries: retries}
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "backoff":
+			if policy.Backoff, err = time.ParseDuration(value); err != nil || policy.Backoff < 0 {
+				return nil, fmt.Errorf("invalid retry backoff %q", value)
+			}
+		default:
+			return nil, fmt.Errorf("unknown retry option %q, expected backoff", key)
+		}
+	}
+	return policy, nil
+}
+
+// isTemporary returns true if "err" wraps an error implementing Temporary() returning true.
+func isTemporary(err error) bool {
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+// boundContext returns the context.Context in "binds", eg. from Interrupts, or context.Background().
+func boundContext(binds bindings) context.Context {
+	if b := binds[contextType]; b != nil {
+		if v, ok := b.Get(); ok {
+			if ctx, ok := v.Interface().(context.Context); ok && ctx != nil {
+				return ctx
+			}
+		}
+	}
+	return context.Background()
+}
+
+// callWithRetry calls the Run() method of "node", retrying temporary failures according to its "retry" tag.
+//
+// Retrying stops, returning the last error, once the bound context.Context is cancelled.
+func (c *Context) callWithRetry(node *Node, method reflect.Value, binds bindings) error {
+	err := callFunction(method, binds)
+	if node.Tag == nil || node.Tag.Retry == nil {
+		return err
+	}
+	ctx := boundContext(binds)
+	policy := node.Tag.Retry
+	delay := policy.Backoff
+	for attempt := 1; attempt <= policy.Retries && err != nil && isTemporary(err) && ctx.Err() == nil; attempt++ {
+		event := RetryEvent{Command: c.Command(), Attempt: attempt, Retries: policy.Retries, Delay: delay, Err: err}
+		if c.Kong.onRetry != nil {
+			c.Kong.onRetry(event)
+		} else {
+			formatMultilineMessage(c.Kong.Stderr, []string{c.Model.Name, "warning"}, "%s: %s, retrying in %s (%d/%d)",
+				event.Command, err, delay, attempt, policy.Retries)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay *= 2
+		err = callFunction(method, binds)
+	}
+	return err
+}
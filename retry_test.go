@@ -0,0 +1,95 @@
+package kong_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type temporaryError struct{ temporary bool }
+
+func (e temporaryError) Error() string   { return "connection reset" }
+func (e temporaryError) Temporary() bool { return e.temporary }
+
+type retryCmd struct {
+	Failures  int
+	Permanent bool
+	attempts  int `kong:"-"`
+}
+
+func (c *retryCmd) Run() error {
+	c.attempts++
+	if c.Permanent {
+		return temporaryError{false}
+	}
+	if c.attempts <= c.Failures {
+		return temporaryError{true}
+	}
+	return nil
+}
+
+func TestRetry(t *testing.T) {
+	var cli struct {
+		Sync retryCmd `cmd:"" retry:"2,backoff=1ms"`
+	}
+	var events []kong.RetryEvent
+	p := mustNew(t, &cli, kong.OnRetry(func(event kong.RetryEvent) { events = append(events, event) }))
+
+	ctx, err := p.Parse([]string{"sync", "--failures=2"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.Equal(t, 3, cli.Sync.attempts)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, kong.RetryEvent{Command: "sync", Attempt: 2, Retries: 2, Delay: 2 * time.Millisecond, Err: temporaryError{true}}, events[1])
+
+	cli.Sync.attempts = 0
+	ctx, err = p.Parse([]string{"sync", "--failures=3"})
+	assert.NoError(t, err)
+	assert.EqualError(t, ctx.Run(), "connection reset")
+	assert.Equal(t, 3, cli.Sync.attempts)
+
+	cli.Sync.attempts = 0
+	ctx, err = p.Parse([]string{"sync", "--permanent"})
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(ctx.Run(), temporaryError{false}))
+	assert.Equal(t, 1, cli.Sync.attempts)
+}
+
+func TestRetryDefaultWarning(t *testing.T) {
+	var cli struct {
+		Sync retryCmd `cmd:"" retry:"1"`
+	}
+	w := &strings.Builder{}
+	p := mustNew(t, &cli, kong.Name("app"), kong.Writers(w, w))
+	ctx, err := p.Parse([]string{"sync", "--failures=1"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.Equal(t, "app: warning: sync: connection reset, retrying in 0s (1/1)\n", w.String())
+}
+
+func TestRetryCancelled(t *testing.T) {
+	var cli struct {
+		Sync retryCmd `cmd:"" retry:"3,backoff=1h"`
+	}
+	bound, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := mustNew(t, &cli, kong.BindTo(bound, (*context.Context)(nil)), kong.OnRetry(func(kong.RetryEvent) { cancel() }))
+	ctx, err := p.Parse([]string{"sync", "--failures=3"})
+	assert.NoError(t, err)
+	assert.EqualError(t, ctx.Run(), "connection reset")
+	assert.Equal(t, 1, cli.Sync.attempts)
+}
+
+func TestRetryInvalid(t *testing.T) {
+	var cli struct {
+		Sync retryCmd `cmd:"" retry:"3,jitter=1s"`
+	}
+	_, err := kong.New(&cli)
+	assert.EqualError(t, err, `<anonymous struct>.Sync: unknown retry option "jitter", expected backoff`)
+}
@@ -0,0 +1,149 @@
+package kong
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RunStrategy determines how RunInvocations runs several Contexts.
+type RunStrategy int
+
+const (
+	// RunSequential runs every invocation in order, regardless of failures.
+	RunSequential RunStrategy = iota
+	// RunFailFast runs invocations in order, stopping at the first failure.
+	RunFailFast
+	// RunParallel runs up to "parallelism" invocations concurrently. No further invocations are started after a
+	// failure.
+	//
+	// Invocations sharing a grammar, such as those returned by Kong.ParseInvocations, can't run concurrently. Parse
+	// each invocation with its own Kong instead, eg. using SplitInvocations.
+	RunParallel
+)
+
+func (r RunStrategy) String() string {
+	switch r {
+	case RunSequential:
+		return "sequential"
+	case RunFailFast:
+		return "fail-fast"
+	case RunParallel:
+		return "parallel"
+	}
+	return fmt.Sprintf("RunStrategy(%d)", int(r))
+}
+
+// InvocationError is the failure of a single invocation run by RunInvocations.
+type InvocationError struct {
+	// Index of the invocation in the Contexts passed to RunInvocations.
+	Index int
+	// Command that was run, as returned by Context.Command().
+	Command string
+	Err     error
+}
+
+func (e *InvocationError) Error() string { return fmt.Sprintf("%s: %s", e.Command, e.Err) }
+
+func (e *InvocationError) Unwrap() error { return e.Err }
+
+// InvocationErrors are the failures of the invocations run by RunInvocations, in invocation order.
+type InvocationErrors []*InvocationError
+
+func (e InvocationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e InvocationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// SplitInvocations splits a command-line holding several command invocations separated by "separator", eg.
+// "build --fast + test --race", into the arguments of each invocation. Empty invocations are dropped.
+func SplitInvocations(args []string, separator string) [][]string {
+	out := [][]string{}
+	start := 0
+	for i := 0; i <= len(args); i++ {
+		if i < len(args) && args[i] != separator {
+			continue
+		}
+		if i > start {
+			out = append(out, args[start:i])
+		}
+		start = i + 1
+	}
+	return out
+}
+
+// RunInvocations calls Run() on each of "contexts" according to "strategy", with "binds" passed to each.
+//
+// "parallelism" bounds the number of concurrent invocations for RunParallel, with zero or less being unbounded,
+// and is otherwise ignored. Failures are returned as InvocationErrors.
+func RunInvocations(contexts []*Context, strategy RunStrategy, parallelism int, binds ...any) error {
+	var errs InvocationErrors
+	switch strategy {
+	case RunSequential, RunFailFast:
+		for i, ctx := range contexts {
+			if err := ctx.Run(binds...); err != nil {
+				errs = append(errs, &InvocationError{Index: i, Command: ctx.Command(), Err: err})
+				if strategy == RunFailFast {
+					break
+				}
+			}
+		}
+
+	case RunParallel:
+		for _, ctx := range contexts {
+			if ctx.shared {
+				return errors.New("invocations sharing a grammar can't be run in parallel")
+			}
+		}
+		if parallelism <= 0 || parallelism > len(contexts) {
+			parallelism = len(contexts)
+		}
+		var (
+			lock   sync.Mutex
+			wg     sync.WaitGroup
+			failed bool
+		)
+		slots := make(chan struct{}, parallelism)
+		for i, ctx := range contexts {
+			slots <- struct{}{}
+			lock.Lock()
+			stop := failed
+			lock.Unlock()
+			if stop {
+				break
+			}
+			wg.Add(1)
+			go func(i int, ctx *Context) {
+				defer func() { <-slots; wg.Done() }()
+				if err := ctx.Run(binds...); err != nil {
+					lock.Lock()
+					failed = true
+					errs = append(errs, &InvocationError{Index: i, Command: ctx.Command(), Err: err})
+					lock.Unlock()
+				}
+			}(i, ctx)
+		}
+		wg.Wait()
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+
+	default:
+		return fmt.Errorf("unknown run strategy %d", int(strategy))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,106 @@
+package kong_test
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type runnerCmd struct {
+	Fail bool
+}
+
+func (c *runnerCmd) Run(ctx *kong.Context, out *[]string) error {
+	*out = append(*out, ctx.Command())
+	if c.Fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+type runnerCLI struct {
+	Build runnerCmd `cmd:""`
+	Test  runnerCmd `cmd:""`
+	Ship  runnerCmd `cmd:""`
+}
+
+func TestRunInvocations(t *testing.T) {
+	args := []string{"build", "+", "test", "--fail", "+", "ship"}
+	for _, test := range []struct {
+		strategy kong.RunStrategy
+		ran      []string
+	}{
+		{kong.RunSequential, []string{"build", "test", "ship"}},
+		{kong.RunFailFast, []string{"build", "test"}},
+	} {
+		t.Run(fmt.Sprint(test.strategy), func(t *testing.T) {
+			var cli runnerCLI
+			ctxs, err := mustNew(t, &cli).ParseInvocations(args, "+")
+			assert.NoError(t, err)
+			out := []string{}
+			err = kong.RunInvocations(ctxs, test.strategy, 0, &out)
+			assert.EqualError(t, err, "test: failed")
+			var errs kong.InvocationErrors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, 1, errs[0].Index)
+			assert.Equal(t, test.ran, out)
+		})
+	}
+}
+
+type parallelCmd struct {
+	Fail    bool
+	running *int32
+	peak    *int32
+}
+
+func (c *parallelCmd) Run() error {
+	n := atomic.AddInt32(c.running, 1)
+	defer atomic.AddInt32(c.running, -1)
+	for {
+		peak := atomic.LoadInt32(c.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(c.peak, peak, n) {
+			break
+		}
+	}
+	if c.Fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestRunInvocationsParallel(t *testing.T) {
+	var running, peak int32
+	ctxs := []*kong.Context{}
+	for _, args := range kong.SplitInvocations([]string{"a", "+", "b", "--fail", "+", "c", "+", "d", "--fail"}, "+") {
+		var cli struct {
+			A parallelCmd `cmd:""`
+			B parallelCmd `cmd:""`
+			C parallelCmd `cmd:""`
+			D parallelCmd `cmd:""`
+		}
+		for _, cmd := range []*parallelCmd{&cli.A, &cli.B, &cli.C, &cli.D} {
+			cmd.running, cmd.peak = &running, &peak
+		}
+		ctx, err := mustNew(t, &cli).Parse(args)
+		assert.NoError(t, err)
+		ctxs = append(ctxs, ctx)
+	}
+	err := kong.RunInvocations(ctxs, kong.RunParallel, 2)
+	assert.Error(t, err)
+	assert.True(t, atomic.LoadInt32(&peak) <= 2)
+	var errs kong.InvocationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, "b", errs[0].Command)
+
+	var cli runnerCLI
+	shared, err := mustNew(t, &cli).ParseInvocations([]string{"build", "+", "test"}, "+")
+	assert.NoError(t, err)
+	err = kong.RunInvocations(shared, kong.RunParallel, 2, &[]string{})
+	assert.EqualError(t, err, "invocations sharing a grammar can't be run in parallel")
+}
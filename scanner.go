@@ -2,6 +2,7 @@ package kong
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -111,8 +112,9 @@ func (t Token) IsValue() bool {
 //
 //	[{FlagToken, "foo"}, {FlagValueToken, "bar"}]
 type Scanner struct {
-	allowHyphenated bool
-	args            []Token
+	allowHyphenated      bool
+	allowNegativeNumbers bool
+	args                 []Token
 }
 
 // ScanAsType creates a new Scanner from args with the given type.
@@ -142,6 +144,14 @@ func (s *Scanner) AllowHyphenPrefixedParameters(enable bool) *Scanner {
 	return s
 }
 
+// AllowNegativeNumbers enables or disables treating untyped tokens such as "-1" and "-1.5" as values on this Scanner.
+//
+// Disabled by default.
+func (s *Scanner) AllowNegativeNumbers(enable bool) *Scanner {
+	s.allowNegativeNumbers = enable
+	return s
+}
+
 // Len returns the number of input arguments.
 func (s *Scanner) Len() int {
 	return len(s.args)
@@ -158,12 +168,13 @@ func (s *Scanner) Pop() Token {
 }
 
 type expectedError struct {
-	context string
-	token   Token
+	context  string
+	token    Token
+	messages catalog
 }
 
 func (e *expectedError) Error() string {
-	return fmt.Sprintf("expected %s value but got %q (%s)", e.context, e.token, e.token.InferredType())
+	return e.messages.sprintf(MessageExpectedValue, e.context, e.token, e.token.InferredType())
 }
 
 // PopValue pops a value token, or returns an error.
@@ -171,8 +182,8 @@ func (e *expectedError) Error() string {
 // "context" is used to assist the user if the value can not be popped, eg. "expected <context> value but got <type>"
 func (s *Scanner) PopValue(context string) (Token, error) {
 	t := s.Pop()
-	if !s.allowHyphenated && !t.IsValue() {
-		return t, &expectedError{context, t}
+	if !s.allowHyphenated && !s.isValue(t) {
+		return t, &expectedError{context: context, token: t}
 	}
 	return t, nil
 }
@@ -188,6 +199,21 @@ func (s *Scanner) PopValueInto(context string, target any) error {
 	return jsonTranscode(t.Value, target)
 }
 
+// PopValues pops consecutive value tokens into a new Scanner with the same settings.
+func (s *Scanner) PopValues() *Scanner {
+	child := ScanFromTokens(s.PopWhile(s.isValue)...)
+	child.allowNegativeNumbers = s.allowNegativeNumbers
+	return child
+}
+
+func (s *Scanner) isValue(t Token) bool {
+	return t.IsValue() || (s.allowNegativeNumbers && t.InferredType() == ShortFlagToken && isNegativeNumber(t.String()))
+}
+
+var negativeNumberRe = regexp.MustCompile(`^-(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+func isNegativeNumber(s string) bool { return negativeNumberRe.MatchString(s) }
+
 // PopWhile predicate returns true.
 func (s *Scanner) PopWhile(predicate func(Token) bool) (values []Token) {
 	for predicate(s.Peek()) {
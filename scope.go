@@ -0,0 +1,101 @@
+package kong
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// enterScope changes the working directory and environment for the Run() method of "node", as configured by the
+// "chdir" and "setenv" tags of the node and its ancestors.
+//
+// ${name} references in the tags are expanded from the values of flags and positional arguments, with dashes in
+// their names replaced by underscores, and then from Vars.
+//
+// The returned function restores the previous working directory and environment.
+func (c *Context) enterScope(node *Node) (restore func(), err error) {
+	dir := ""
+	env := map[string]string{}
+	nodes := []*Node{}
+	for n := node; n != nil; n = n.Parent {
+		nodes = append([]*Node{n}, nodes...)
+	}
+	var vars Vars
+	for _, n := range nodes {
+		if n.Tag == nil || (n.Tag.Chdir == "" && len(n.Tag.SetEnv) == 0) {
+			continue
+		}
+		if vars == nil {
+			vars = c.scopeVars(node)
+		}
+		if n.Tag.Chdir != "" {
+			if dir, err = interpolate(n.Tag.Chdir, vars, nil); err != nil {
+				return nil, fmt.Errorf("%s: chdir: %w", n.Name, err)
+			}
+		}
+		for _, set := range n.Tag.SetEnv {
+			key, value, _ := strings.Cut(set, "=")
+			if env[key], err = interpolate(value, vars, nil); err != nil {
+				return nil, fmt.Errorf("%s: setenv: %w", n.Name, err)
+			}
+		}
+	}
+	restores := []func(){}
+	restore = func() {
+		for i := len(restores) - 1; i >= 0; i-- {
+			restores[i]()
+		}
+	}
+	if dir != "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chdir(ExpandPath(dir)); err != nil {
+			return nil, err
+		}
+		restores = append(restores, func() { _ = os.Chdir(wd) })
+	}
+	for key, value := range env {
+		key := key
+		previous, ok := os.LookupEnv(key)
+		if err := os.Setenv(key, value); err != nil {
+			restore()
+			return nil, err
+		}
+		restores = append(restores, func() {
+			if ok {
+				_ = os.Setenv(key, previous)
+			} else {
+				_ = os.Unsetenv(key)
+			}
+		})
+	}
+	return restore, nil
+}
+
+// scopeVars returns the variables available to "chdir" and "setenv" tags of "node" and its ancestors.
+func (c *Context) scopeVars(node *Node) Vars {
+	vars := c.Kong.vars.CloneWith(node.Vars())
+	seen := map[string]bool{}
+	for n := node; n != nil; n = n.Parent {
+		values := []*Value{}
+		for _, flag := range n.Flags {
+			values = append(values, flag.Value)
+		}
+		values = append(values, n.Positional...)
+		for _, value := range values {
+			name := strings.ReplaceAll(value.Name, "-", "_")
+			if seen[name] || !value.Target.IsValid() {
+				continue
+			}
+			seen[name] = true
+			// Values are not redacted, as secrets are commonly passed on through the environment.
+			if target := reflect.Indirect(value.Target); target.IsValid() {
+				vars[name] = fmt.Sprintf("%v", target.Interface())
+			}
+		}
+	}
+	return vars
+}
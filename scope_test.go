@@ -0,0 +1,57 @@
+package kong_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type scopedCmd struct {
+	Target string `arg:""`
+}
+
+func (s *scopedCmd) Run(out *[]string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	*out = append(*out, filepath.Base(wd), os.Getenv("KONG_TEST_PROJECT"), os.Getenv("KONG_TEST_TARGET"))
+	return nil
+}
+
+func TestCommandScope(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(root, "project"), 0o700))
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	t.Setenv("KONG_TEST_PROJECT", "previous")
+
+	var cli struct {
+		Workdir string    `default:"."`
+		Build   scopedCmd `cmd:"" chdir:"${workdir}" setenv:"KONG_TEST_PROJECT=${workdir}" setenv:"KONG_TEST_TARGET=${target}"`
+	}
+	p := mustNew(t, &cli)
+	ctx, err := p.Parse([]string{"--workdir", filepath.Join(root, "project"), "build", "all"})
+	assert.NoError(t, err)
+	out := []string{}
+	err = ctx.Run(&out)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"project", filepath.Join(root, "project"), "all"}, out)
+
+	after, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.Equal(t, wd, after)
+	assert.Equal(t, "previous", os.Getenv("KONG_TEST_PROJECT"))
+	_, ok := os.LookupEnv("KONG_TEST_TARGET")
+	assert.False(t, ok)
+
+	var bad struct {
+		Flag string `chdir:"/tmp"`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, "<anonymous struct>.Flag: chdir and setenv only make sense for commands")
+}
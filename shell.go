@@ -0,0 +1,106 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ShellCompletion enables completion of commands and flags for shells using the COMP_LINE protocol.
+//
+// When the COMP_LINE envar is set, Parse() prints completion candidates for it and exits. The
+// snippet printed by ShellInitCommand registers the application with the shell in this way.
+func ShellCompletion() Option {
+	return OptionFunc(func(k *Kong) error {
+		k.shellCompletion = true
+		return nil
+	})
+}
+
+// DetectShell returns the name of the user's shell, eg. "zsh", from the SHELL envar.
+func DetectShell() string {
+	return strings.TrimSuffix(filepath.Base(os.Getenv("SHELL")), ".exe")
+}
+
+// ShellInitCommand prints a snippet that enables completion and envar defaults for the application.
+//
+// Add it to a CLI, eg. as a "shell-init" command, then users need only add the following to their rc file:
+//
+//	eval "$(app shell-init)"
+//
+// Completion requires the ShellCompletion() option.
+type ShellInitCommand struct {
+	Shell string `arg:"" optional:"" help:"Shell to generate the snippet for, one of bash, zsh or fish. Detected from $$SHELL if omitted."`
+}
+
+// Run writes the snippet to Kong.Stdout.
+func (s ShellInitCommand) Run(app *Kong) error {
+	shell := s.Shell
+	if shell == "" {
+		shell = DetectShell()
+	}
+	name := app.Model.Name
+	executable, err := os.Executable()
+	if err != nil {
+		executable = name
+	}
+	defaults := envDefaults(app.Model)
+	w := app.Stdout
+	switch shell {
+	case "bash", "zsh":
+		if shell == "zsh" {
+			fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+		}
+		fmt.Fprintf(w, "complete -C %s %s\n", shellQuote(executable), shellQuote(name))
+		for _, env := range defaults {
+			fmt.Fprintf(w, "[ -n \"${%s+x}\" ] || export %s=%s\n", env[0], env[0], shellQuote(env[1]))
+		}
+	case "fish":
+		fmt.Fprintf(w, "complete -c %s -f -a '(env COMP_LINE=(commandline -cp) %s)'\n", shellQuote(name), strconv.Quote(executable))
+		for _, env := range defaults {
+			fmt.Fprintf(w, "set -q %s; or set -gx %s %s\n", env[0], env[0], strconv.Quote(env[1]))
+		}
+	default:
+		return fmt.Errorf("unsupported shell %q, expected one of bash, zsh or fish", shell)
+	}
+	return nil
+}
+
+// envDefaults returns the first envar and default value of each flag that has both.
+//
+// Secret flags are excluded.
+func envDefaults(app *Application) (out [][2]string) {
+	_ = Visit(app, func(node Visitable, next Next) error {
+		if flag, ok := node.(*Flag); ok && len(flag.Envs) > 0 && flag.HasDefault && !flag.Tag.Secret {
+			out = append(out, [2]string{flag.Envs[0], flag.Default})
+		}
+		return next(nil)
+	})
+	return out
+}
+
+// printCompletions writes completion candidates for a COMP_LINE, one per line.
+func (k *Kong) printCompletions(line string) {
+	if point, err := strconv.Atoi(os.Getenv("COMP_POINT")); err == nil && point >= 0 && point <= len(line) {
+		line = line[:point]
+	}
+	// Strip the command name.
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		line = strings.TrimLeft(line[i:], " \t")
+	} else {
+		line = ""
+	}
+	for _, candidate := range Complete(k, line) {
+		fmt.Fprintln(k.Stdout, candidate)
+	}
+}
+
+// shellQuote quotes "s" for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
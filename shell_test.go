@@ -0,0 +1,61 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestShellInitCommand(t *testing.T) {
+	var cli struct {
+		Port      int                   `env:"APP_PORT" default:"8080"`
+		Token     string                `env:"APP_TOKEN" default:"x" secret:""`
+		ShellInit kong.ShellInitCommand `cmd:""`
+	}
+	executable, err := os.Executable()
+	assert.NoError(t, err)
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	stdout := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Name("app"), kong.Writers(stdout, stdout))
+	ctx, err := p.Parse([]string{"shell-init"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.Equal(t, "autoload -U +X bashcompinit && bashcompinit\n"+
+		"complete -C '"+executable+"' 'app'\n"+
+		`[ -n "${APP_PORT+x}" ] || export APP_PORT='8080'`+"\n", stdout.String())
+
+	stdout.Reset()
+	p = mustNew(t, &cli, kong.Name("my app"), kong.Writers(stdout, stdout))
+	ctx, err = p.Parse([]string{"shell-init", "fish"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.True(t, strings.HasPrefix(stdout.String(), "complete -c 'my app' -f -a "), stdout.String())
+
+	ctx, err = p.Parse([]string{"shell-init", "tcsh"})
+	assert.NoError(t, err)
+	assert.EqualError(t, ctx.Run(), `unsupported shell "tcsh", expected one of bash, zsh or fish`)
+}
+
+func TestShellCompletion(t *testing.T) {
+	var cli struct {
+		User struct {
+			Create struct{} `cmd:""`
+			Delete struct{} `cmd:""`
+		} `cmd:""`
+	}
+	stdout := &bytes.Buffer{}
+	exited := -1
+	p := mustNew(t, &cli, kong.ShellCompletion(), kong.Writers(stdout, stdout), kong.Exit(func(code int) { exited = code }))
+	t.Setenv("COMP_LINE", "app user de")
+	_, _ = p.Parse(nil)
+	assert.Equal(t, 0, exited)
+	assert.Equal(t, []string{"delete"}, strings.Fields(stdout.String()))
+}
@@ -0,0 +1,142 @@
+package kong
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Sources that may be given in a `source:"..."` tag.
+const (
+	sourceFlag   = "flag"
+	sourceEnv    = "env"
+	sourceConfig = "config"
+	sourcePrompt = "prompt"
+	sourceFile   = "file:"
+)
+
+func parseSources(tag string) ([]string, error) {
+	sources := strings.FieldsFunc(tag, tagSplitFn)
+	for _, source := range sources {
+		switch {
+		case source == sourceFlag, source == sourceEnv, source == sourceConfig, source == sourcePrompt:
+		case strings.HasPrefix(source, sourceFile) && len(source) > len(sourceFile):
+		default:
+			return nil, fmt.Errorf("invalid source %q, must be one of flag, env, config, prompt or file:PATH", source)
+		}
+	}
+	return sources, nil
+}
+
+// resolveSourced resolves a flag tagged with `source:"..."` from the sources preceding "prompt" in its chain.
+//
+// It returns nil if no source provided a value, or if the value was given on the command-line and "flag" is the
+// first source to provide one.
+func (c *Context) resolveSourced(path *Path, flag *Flag, resolvers []Resolver) (*Path, error) {
+	onCommandLine := false
+	for _, p := range c.Path {
+		onCommandLine = onCommandLine || (p.Flag == flag && !p.Resolved)
+	}
+	allowed := false
+	for _, source := range flag.Tag.Sources {
+		allowed = allowed || source == sourceFlag
+	}
+	if onCommandLine && !allowed {
+		return nil, fmt.Errorf("%s can't be given on the command-line", flag.ShortSummary())
+	}
+	if _, ok := c.values[flag.Value]; ok && !onCommandLine {
+		return nil, nil // Resolved by a previous call, see reapply.
+	}
+	return c.resolveSources(path, flag, flag.Tag.Sources, onCommandLine, resolvers)
+}
+
+// resolveSources tries each source in turn, stopping at "prompt", and returns the Path of the first value found.
+func (c *Context) resolveSources(path *Path, flag *Flag, sources []string, onCommandLine bool, resolvers []Resolver) (*Path, error) {
+	for _, source := range sources {
+		var value any
+		switch {
+		case source == sourceFlag:
+			if onCommandLine {
+				return nil, nil
+			}
+
+		case source == sourceEnv:
+			for _, env := range flag.Tag.Envs {
+				envar, ok := os.LookupEnv(env)
+				if !ok {
+					continue
+				}
+				if flag.Tag.ExpandEnv {
+					envar = os.ExpandEnv(envar)
+				}
+				resolved, err := c.resolvedPath(flag, envar)
+				if err != nil {
+					return nil, fmt.Errorf("%s (from envar %s=%q)", err, env, flag.Redact(envar))
+				}
+				flag.EnvVar = env
+				return resolved, nil
+			}
+
+		case source == sourceConfig:
+			for _, resolver := range resolvers {
+				s, err := resolver.Resolve(c, path, flag)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", flag.ShortSummary(), err)
+				}
+				if s != nil {
+					value = s
+				}
+			}
+
+		case source == sourcePrompt:
+			return nil, nil
+
+		default:
+			data, err := os.ReadFile(ExpandPath(strings.TrimPrefix(source, sourceFile)))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("%s: %w", flag.ShortSummary(), err)
+			}
+			value = strings.TrimRight(string(data), "\r\n")
+		}
+		if value != nil {
+			return c.resolvedPath(flag, value)
+		}
+	}
+	return nil, nil
+}
+
+// promptSourced prompts for a flag with "prompt" in its `source:"..."` tag that has no value yet, falling back to
+// the sources after "prompt" if stdin is not a terminal or --no-input is given.
+func (c *Context) promptSourced(path *Path, flag *Flag, prompt func(string) (string, error)) (*Path, error) {
+	if _, ok := c.values[flag.Value]; ok {
+		return nil, nil
+	}
+	for i, source := range flag.Tag.Sources {
+		if source != sourcePrompt {
+			continue
+		}
+		if !c.noInput() {
+			value, err := prompt(fmt.Sprintf("Enter --%s: ", flag.Name))
+			if err == nil {
+				return c.resolvedPath(flag, value)
+			} else if !errors.Is(err, errNotTerminal) {
+				return nil, fmt.Errorf("%s: %w", flag.ShortSummary(), err)
+			}
+		}
+		return c.resolveSources(path, flag, flag.Tag.Sources[i+1:], false, c.combineResolvers())
+	}
+	return nil, nil
+}
+
+// resolvedPath parses a resolved value into the flag, replacing any value given on the command-line.
+func (c *Context) resolvedPath(flag *Flag, value any) (*Path, error) {
+	delete(c.values, flag.Value)
+	if err := flag.Parse(Scan().PushTyped(value, FlagValueToken), c.getValue(flag.Value)); err != nil {
+		return nil, err
+	}
+	return &Path{Flag: flag, Resolved: true, remainder: c.scan.PeekAll()}, nil
+}
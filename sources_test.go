@@ -0,0 +1,78 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestSourceTag(t *testing.T) {
+	var cli struct {
+		Token string `env:"KONG_TEST_TOKEN" source:"flag,env,prompt,file:testdata/missing.txt,file:testdata/file.txt"`
+	}
+	prompts := 0
+	p := mustNew(t, &cli, kong.NoInputFlag(), kong.PasswordPrompt(func(prompt string) (string, error) {
+		prompts++
+		return "from-prompt", nil
+	}))
+
+	_, err := p.Parse([]string{"--token=from-flag"})
+	assert.NoError(t, err)
+	assert.Equal(t, "from-flag", cli.Token)
+
+	_, err = p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-prompt", cli.Token)
+	assert.Equal(t, 1, prompts)
+
+	_, err = p.Parse([]string{"--no-input"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world.", cli.Token)
+
+	t.Setenv("KONG_TEST_TOKEN", "from-env")
+	_, err = p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", cli.Token)
+	assert.Equal(t, 1, prompts)
+}
+
+func TestSourceTagOverridesOrdering(t *testing.T) {
+	t.Setenv("KONG_TEST_TOKEN", "from-env")
+	var cli struct {
+		Token string `env:"KONG_TEST_TOKEN" source:"env,config,flag"`
+		Key   string `env:"KONG_TEST_TOKEN" source:"config"`
+		Other string `env:"KONG_TEST_TOKEN"`
+	}
+	resolver := kong.ResolverFunc(func(_ *kong.Context, _ *kong.Path, flag *kong.Flag) (any, error) {
+		if flag.Name != "token" && flag.Name != "key" {
+			return nil, nil
+		}
+		return "from-config-" + flag.Name, nil
+	})
+	p := mustNew(t, &cli, kong.Resolvers(resolver))
+
+	_, err := p.Parse([]string{"--other=from-flag"})
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", cli.Token)
+	assert.Equal(t, "from-config-key", cli.Key)
+	assert.Equal(t, "from-flag", cli.Other)
+
+	_, err = p.Parse([]string{"--key=from-flag"})
+	assert.EqualError(t, err, "--key can't be given on the command-line")
+}
+
+func TestSourceTagInvalid(t *testing.T) {
+	var cli struct {
+		Token string `source:"flag,vault"`
+	}
+	_, err := kong.New(&cli)
+	assert.EqualError(t, err, `<anonymous struct>.Token: invalid source "vault", must be one of flag, env, config, prompt or file:PATH`)
+
+	var arg struct {
+		Token string `arg:"" source:"env"`
+	}
+	_, err = kong.New(&arg)
+	assert.EqualError(t, err, "<anonymous struct>.Token: source only makes sense for flags")
+}
@@ -0,0 +1,124 @@
+package kong
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Stability of a command or flag, set with the "stability" tag.
+type Stability string
+
+// Stability levels.
+const (
+	// StabilityStable is the default. Stable commands and flags are covered by compatibility guarantees.
+	StabilityStable Stability = "stable"
+	// StabilityBeta commands and flags are shown in help, but warn when used.
+	StabilityBeta Stability = "beta"
+	// StabilityAlpha commands and flags are hidden unless --help-all is used, and warn when used.
+	StabilityAlpha Stability = "alpha"
+)
+
+func (s Stability) unstable() bool { return s == StabilityAlpha || s == StabilityBeta }
+
+func parseStability(s string) (Stability, error) {
+	switch stability := Stability(s); stability {
+	case "":
+		return StabilityStable, nil
+	case StabilityStable, StabilityBeta, StabilityAlpha:
+		return stability, nil
+	default:
+		return "", fmt.Errorf("invalid stability %q, must be one of alpha, beta or stable", s)
+	}
+}
+
+// Help-all flag, shown when there are alpha commands or flags.
+type helpAllFlag bool
+
+func (h helpAllFlag) IgnoreDefault() {}
+
+func (h helpAllFlag) BeforeReset(ctx *Context) error {
+	// Temporarily reveal alpha commands and flags that aren't otherwise hidden.
+	revealed := []*bool{}
+	_ = Visit(ctx.Model, func(node Visitable, next Next) error {
+		switch node := node.(type) {
+		case *Node:
+			if node.Stability == StabilityAlpha && node.Hidden && !node.Tag.Hidden {
+				revealed = append(revealed, &node.Hidden)
+			}
+		case *Flag:
+			if node.Stability == StabilityAlpha && node.Hidden && !node.Tag.Hidden {
+				revealed = append(revealed, &node.Hidden)
+			}
+		}
+		return next(nil)
+	})
+	for _, hidden := range revealed {
+		*hidden = false
+	}
+	defer func() {
+		for _, hidden := range revealed {
+			*hidden = true
+		}
+	}()
+	options := ctx.Kong.helpOptions
+	options.Summary = false
+	if err := ctx.Kong.help(options, ctx); err != nil {
+		return err
+	}
+	ctx.Kong.Exit(0)
+	return nil
+}
+
+// addHelpAllFlag adds a --help-all flag to the root if the model has any alpha commands or flags.
+func (k *Kong) addHelpAllFlag() {
+	if k.noDefaultHelp {
+		return
+	}
+	alpha := false
+	_ = Visit(k.Model, func(node Visitable, next Next) error {
+		switch node := node.(type) {
+		case *Node:
+			alpha = alpha || node.Stability == StabilityAlpha
+		case *Flag:
+			alpha = alpha || node.Stability == StabilityAlpha
+		}
+		return next(nil)
+	})
+	for _, flag := range k.Model.Flags {
+		if flag.Name == "help-all" {
+			return
+		}
+	}
+	if !alpha {
+		return
+	}
+	var target helpAllFlag
+	value := reflect.ValueOf(&target).Elem()
+	flag := &Flag{
+		Value: &Value{
+			Name:         "help-all",
+			Help:         "Show context-sensitive help, including alpha commands and flags.",
+			OrigHelp:     "Show context-sensitive help, including alpha commands and flags.",
+			Target:       value,
+			Tag:          &Tag{},
+			Mapper:       k.registry.ForValue(value),
+			DefaultValue: reflect.ValueOf(false),
+		},
+	}
+	flag.Flag = flag
+	k.Model.Flags = append(k.Model.Flags, flag)
+}
+
+// warnUnstable writes a warning to Kong.Stderr for each alpha or beta command or flag used.
+func (k *Kong) warnUnstable(ctx *Context) {
+	for _, path := range ctx.Path {
+		switch {
+		case path.Command != nil && path.Command.Stability.unstable():
+			formatMultilineMessage(k.Stderr, []string{k.Model.Name, "warning"}, "command %q is %s and may change or be removed",
+				path.Command.Name, path.Command.Stability)
+		case path.Flag != nil && !path.Resolved && path.Flag.Stability.unstable():
+			formatMultilineMessage(k.Stderr, []string{k.Model.Name, "warning"}, "flag --%s is %s and may change or be removed",
+				path.Flag.Name, path.Flag.Stability)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+//go:build !kong_minimal
+// +build !kong_minimal
+
+package kong_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestStability(t *testing.T) {
+	var cli struct {
+		Fast    bool     `stability:"alpha" help:"Go faster."`
+		Compact bool     `stability:"beta" help:"Compact output."`
+		Lab     struct{} `cmd:"" stability:"alpha" help:"Experiments."`
+		Run     struct{} `cmd:"" help:"Run."`
+	}
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	p := mustNew(t, &cli, kong.Name("app"), kong.Writers(stdout, stderr), kong.Exit(func(int) {}),
+		kong.ConfigureHelp(kong.HelpOptions{Compact: true}))
+	_, _ = p.Parse([]string{"--help"})
+	assert.Equal(t, `Usage: app <command> [flags]
+
+Flags:
+  -h, --help        Show context-sensitive help.
+      --compact     Compact output.
+      --help-all    Show context-sensitive help, including alpha commands and
+                    flags.
+
+Commands:
+  run    Run.
+
+Run "app <command> --help" for more information on a command.
+`, stdout.String())
+
+	stdout.Reset()
+	_, _ = p.Parse([]string{"--help-all"})
+	assert.Contains(t, stdout.String(), "--fast")
+	assert.Contains(t, stdout.String(), "lab    Experiments.")
+
+	// Alpha items are hidden again afterwards.
+	assert.True(t, p.Model.Children[0].Hidden)
+
+	_, err := p.Parse([]string{"--fast", "--compact", "lab"})
+	assert.NoError(t, err)
+	assert.Equal(t, `app: warning: flag --fast is alpha and may change or be removed
+app: warning: flag --compact is beta and may change or be removed
+app: warning: command "lab" is alpha and may change or be removed
+`, stderr.String())
+
+	var bad struct {
+		Flag bool `stability:"gamma"`
+	}
+	_, err = kong.New(&bad)
+	assert.EqualError(t, err, `<anonymous struct>.Flag: invalid stability "gamma", must be one of alpha, beta or stable`)
+}
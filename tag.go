@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -27,6 +28,7 @@ type Tag struct {
 	Cmd             bool
 	Arg             bool
 	Required        bool
+	RequiredIf      []string // Full paths of commands that make the flag required when selected, eg. requiredif:"cmd=deploy,cluster deploy".
 	Optional        bool
 	Name            string
 	Help            string
@@ -37,10 +39,20 @@ type Tag struct {
 	Format          string
 	PlaceHolder     string
 	Envs            []string
+	ExpandEnv       bool // Expand ${VAR} references in envar values.
+	Expand          bool // Expand ~, ~user, $VAR and %VAR% in path values.
 	Short           rune
 	Hidden          bool
-	Sep             rune
-	MapSep          rune
+	Enabled         string // Feature gate condition, eg. "${experimental}".
+	Stability       Stability
+	Since           string // Version the flag or argument was introduced in, for documentation.
+	RemovedIn       string // Version the flag or argument will be removed in, for documentation.
+	ErrHelp         string // Guidance appended to errors for this value.
+	Sep             rune   // First rune of Separator, or -1 if slice values are not split.
+	MapSep          rune   // First rune of MapSeparator, or -1 if map values are not split.
+	Separator       string // Separator between slice elements, or empty if values are not split.
+	MapSeparator    string // Separator between map entries, or empty if values are not split.
+	Quote           bool   // Separators within single or double quotes do not split values.
 	Enum            string
 	Group           string
 	Xor             []string
@@ -50,10 +62,34 @@ type Tag struct {
 	EnvPrefix       string
 	XorPrefix       string // Optional prefix on XOR/AND groups.
 	Embed           bool
+	Instances       int // Number of elements of an embedded slice of structs, eg. for "--listener.{i}.port".
 	Aliases         []string
 	Negatable       string
-	Passthrough     bool // Deprecated: use PassthroughMode instead.
+	Secret          bool
+	NonInterspersed bool           // Flags are only matched before positional arguments.
+	Rest            bool           // Field receives all arguments after the first bare "--".
+	MaxCount        int            // Maximum number of values a slice, map or counter flag accepts. Zero is unlimited.
+	Override        bool           // Flag replaces an ancestor's flag of the same name within its subtree.
+	Local           bool           // Flag is not inherited by subcommands.
+	EnumFrom        string         // Name of the EnumProvider supplying allowed values.
+	Sources         []string       // Where the value may come from, in order of preference, eg. "flag,env,prompt".
+	Pattern         *regexp.Regexp // Regular expression each value or element must match entirely.
+	Min             *float64       // Minimum of each numeric value or element.
+	Max             *float64       // Maximum of each numeric value or element.
+	Unit            string         // Unit of a numeric field, eg. "ms". Values may be given in other units of its family.
+	AtFile          bool           // Values of the form @file and @- are read from the file or stdin.
+	Chdir           string         // Working directory for the command's Run() method.
+	SetEnv          []string       // Envars in the form KEY=VALUE set for the command's Run() method.
+	Passthrough     bool           // Deprecated: use PassthroughMode instead.
 	PassthroughMode PassthroughMode
+	Retry           *RetryPolicy // Retries of the command's Run() method on temporary errors.
+	UnknownArgs     string       // "capture" to capture the first unknown argument and all after it into the rest field.
+
+	// Set when an ancestor command has an envprefix, in which case flags without envars derive them.
+	envPrefixed bool
+	// Set for fields of an instance of an embedded slice of structs, to the slice and the index of the instance.
+	indexed  *indexedGroup
+	instance int
 
 	// Storage for all tag keys for arbitrary lookups.
 	items map[string][]string
@@ -69,6 +105,8 @@ func (t *Tag) String() string {
 	return strings.Join(out, " ")
 }
 
+const unknownArgsCapture = "capture"
+
 type tagChars struct {
 	sep, quote, assign rune
 	needsUnquote       bool
@@ -198,7 +236,7 @@ func parseTagString(s string) (*Tag, error) {
 	return t, nil
 }
 
-func parseTag(parent reflect.Value, ft reflect.StructField) (*Tag, error) {
+func parseTag(k *Kong, parent reflect.Value, ft reflect.StructField) (*Tag, error) {
 	if ft.Tag.Get("kong") == "-" {
 		t := newEmptyTag()
 		t.Ignored = true
@@ -208,6 +246,17 @@ func parseTag(parent reflect.Value, ft reflect.StructField) (*Tag, error) {
 	if err != nil {
 		return nil, err
 	}
+	for key, values := range k.typeTagsFor(ft.Type) {
+		if _, ok := items[key]; !ok {
+			items[key] = append([]string{}, values...)
+		}
+	}
+	// Slices of floats can't be separated by a decimal comma.
+	if _, ok := items["sep"]; !ok && k.decimalComma && ft.Type.Kind() == reflect.Slice {
+		if kind := elementType(ft.Type).Kind(); kind == reflect.Float32 || kind == reflect.Float64 {
+			items["sep"] = []string{";"}
+		}
+	}
 	t := &Tag{
 		items: items,
 	}
@@ -221,11 +270,9 @@ func parseTag(parent reflect.Value, ft reflect.StructField) (*Tag, error) {
 func hydrateTag(t *Tag, typ reflect.Type) error { //nolint: gocyclo
 	var typeName string
 	var isBool bool
-	var isBoolPtr bool
 	if typ != nil {
-		typeName = typ.Name()
-		isBool = typ.Kind() == reflect.Bool
-		isBoolPtr = typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Bool
+		typeName, _, _ = strings.Cut(typ.Name(), "[") // Without type arguments.
+		isBool = isBoolType(typ)
 	}
 	var err error
 	t.Cmd = t.Has("cmd")
@@ -237,6 +284,21 @@ func hydrateTag(t *Tag, typ reflect.Type) error { //nolint: gocyclo
 	}
 	t.Required = required
 	t.Optional = optional
+	if t.Has("requiredif") {
+		key, cmds, ok := strings.Cut(t.Get("requiredif"), "=")
+		if !ok || key != "cmd" || cmds == "" {
+			return fmt.Errorf("requiredif should be in the form cmd=<command>[,<command>...] but got %q", t.Get("requiredif"))
+		}
+		if required || t.Arg {
+			return fmt.Errorf("requiredif only makes sense for flags that are not already required")
+		}
+		// Commands are named by their full path, eg. "cluster deploy", so only commas separate them.
+		for _, cmd := range strings.Split(cmds, ",") {
+			if cmd = strings.Join(strings.Fields(cmd), " "); cmd != "" {
+				t.RequiredIf = append(t.RequiredIf, cmd)
+			}
+		}
+	}
 	t.HasDefault = t.Has("default")
 	t.Default = t.Get("default")
 	// Arguments with defaults are always optional.
@@ -252,14 +314,26 @@ func hydrateTag(t *Tag, typ reflect.Type) error { //nolint: gocyclo
 	for _, env := range t.GetAll("env") {
 		t.Envs = append(t.Envs, strings.FieldsFunc(env, tagSplitFn)...)
 	}
+	t.ExpandEnv = t.Has("expandenv")
+	t.Expand = t.Has("expand")
 	t.Short, err = t.GetRune("short")
 	if err != nil && t.Get("short") != "" {
 		return fmt.Errorf("invalid short flag name %q: %s", t.Get("short"), err)
 	}
 	t.Hidden = t.Has("hidden")
+	t.Enabled = t.Get("enabled")
+	t.ErrHelp = t.Get("errhelp")
+	if t.Stability, err = parseStability(t.Get("stability")); err != nil {
+		return err
+	}
+	t.Since = t.Get("since")
+	t.RemovedIn = t.Get("removedin")
 	t.Format = t.Get("format")
 	t.Sep, _ = t.GetSep("sep", ',')
 	t.MapSep, _ = t.GetSep("mapsep", ';')
+	t.Separator = t.getSeparator("sep", ",")
+	t.MapSeparator = t.getSeparator("mapsep", ";")
+	t.Quote = t.Has("quote")
 	t.Group = t.Get("group")
 	for _, xor := range t.GetAll("xor") {
 		t.Xor = append(t.Xor, strings.FieldsFunc(xor, tagSplitFn)...)
@@ -271,8 +345,14 @@ func hydrateTag(t *Tag, typ reflect.Type) error { //nolint: gocyclo
 	t.EnvPrefix = t.Get("envprefix")
 	t.XorPrefix = t.Get("xorprefix")
 	t.Embed = t.Has("embed")
+	if t.Has("instances") {
+		if t.Instances, err = strconv.Atoi(t.Get("instances")); err != nil || t.Instances < 1 {
+			return fmt.Errorf("invalid instances %q, must be a positive integer", t.Get("instances"))
+		}
+	}
+	t.Secret = t.Has("secret") || t.Type == "password"
 	if t.Has("negatable") {
-		if !isBool && !isBoolPtr {
+		if !isBool {
 			return fmt.Errorf("negatable can only be set on booleans")
 		}
 		negatable := t.Get("negatable")
@@ -299,6 +379,101 @@ func hydrateTag(t *Tag, typ reflect.Type) error { //nolint: gocyclo
 	if t.Enum != "" && !(t.Required || t.HasDefault) && scalarType {
 		return fmt.Errorf("enum value is only valid if it is either required or has a valid default value")
 	}
+	if t.Has("pattern") {
+		if t.Pattern, err = regexp.Compile("^(?:" + t.Get("pattern") + ")$"); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", t.Get("pattern"), err)
+		}
+	}
+	for _, bound := range []struct {
+		name   string
+		target **float64
+	}{{"min", &t.Min}, {"max", &t.Max}} {
+		if !t.Has(bound.name) {
+			continue
+		}
+		n, err := strconv.ParseFloat(t.Get(bound.name), 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q, must be a number", bound.name, t.Get(bound.name))
+		}
+		if typ != nil {
+			if kind := elementType(typ).Kind(); kind < reflect.Int || kind > reflect.Float64 || kind == reflect.Uintptr {
+				return fmt.Errorf("%s only makes sense for numbers", bound.name)
+			}
+		}
+		*bound.target = &n
+	}
+	t.Unit = t.Get("unit")
+	if t.Unit != "" {
+		if unitFamily(t.Unit) == nil {
+			return fmt.Errorf("unknown unit %q", t.Unit)
+		}
+		if typ != nil && (typ.Kind() < reflect.Int || typ.Kind() > reflect.Float64 || typ.Kind() == reflect.Uintptr) {
+			return fmt.Errorf("unit only makes sense for numbers")
+		}
+	}
+	t.EnumFrom = t.Get("enumfrom")
+	if t.Enum != "" && t.EnumFrom != "" {
+		return fmt.Errorf("enum and enumfrom are mutually exclusive")
+	}
+	if t.Has("maxcount") {
+		if t.MaxCount, err = strconv.Atoi(t.Get("maxcount")); err != nil || t.MaxCount < 1 {
+			return fmt.Errorf("invalid maxcount %q, must be a positive integer", t.Get("maxcount"))
+		}
+		if typ != nil && typ.Kind() != reflect.Slice && typ.Kind() != reflect.Map && t.Type != "counter" {
+			return fmt.Errorf("maxcount only makes sense for slices, maps and counters")
+		}
+	}
+	t.AtFile = t.Has("atfile")
+	if t.AtFile && typ != nil && typ.Kind() != reflect.String {
+		return fmt.Errorf("atfile only makes sense for strings")
+	}
+	t.Local = t.Has("local")
+	if t.Local && (t.Arg || t.Cmd) {
+		return fmt.Errorf("local only makes sense for flags")
+	}
+	t.Override = t.Has("override")
+	if t.Override && (t.Arg || t.Cmd) {
+		return fmt.Errorf("override only makes sense for flags")
+	}
+	t.Rest = t.Has("rest")
+	if t.Rest && typ != nil && typ != reflect.TypeOf([]string{}) {
+		return fmt.Errorf("rest must be a []string")
+	}
+	t.Chdir = t.Get("chdir")
+	t.SetEnv = t.GetAll("setenv")
+	for _, set := range t.SetEnv {
+		if key, _, ok := strings.Cut(set, "="); !ok || key == "" {
+			return fmt.Errorf("setenv should be in the form KEY=VALUE but got %q", set)
+		}
+	}
+	if (t.Chdir != "" || len(t.SetEnv) > 0) && !t.Cmd {
+		return fmt.Errorf("chdir and setenv only make sense for commands")
+	}
+	t.UnknownArgs = t.Get("unknownargs")
+	if t.UnknownArgs != "" && t.UnknownArgs != unknownArgsCapture {
+		return fmt.Errorf("invalid unknownargs %q, must be %q", t.UnknownArgs, unknownArgsCapture)
+	}
+	if t.UnknownArgs != "" && !t.Cmd {
+		return fmt.Errorf("unknownargs only makes sense for commands")
+	}
+	if t.Sources, err = parseSources(t.Get("source")); err != nil {
+		return err
+	}
+	if len(t.Sources) > 0 && (t.Arg || t.Cmd) {
+		return fmt.Errorf("source only makes sense for flags")
+	}
+	if t.Has("retry") {
+		if !t.Cmd {
+			return fmt.Errorf("retry only makes sense for commands")
+		}
+		if t.Retry, err = parseRetry(t.Get("retry")); err != nil {
+			return err
+		}
+	}
+	t.NonInterspersed = t.Has("noninterspersed")
+	if t.NonInterspersed && !t.Cmd {
+		return fmt.Errorf("noninterspersed only makes sense for commands")
+	}
 	passthrough := t.Has("passthrough")
 	if passthrough && !t.Arg && !t.Cmd {
 		return fmt.Errorf("passthrough only makes sense for positional arguments or commands")
@@ -384,3 +559,24 @@ func (t *Tag) GetSep(k string, dflt rune) (rune, error) {
 	}
 	return r, nil
 }
+
+// getSeparator returns the separator in the given tag, which may be several characters, allowing for a default or
+// none.
+func (t *Tag) getSeparator(k string, dflt string) string {
+	switch tv := t.Get(k); tv {
+	case "none":
+		return ""
+	case "":
+		return dflt
+	default:
+		return tv
+	}
+}
+
+// elementType returns the type of the elements of slices and values of maps in "typ", through any pointers.
+func elementType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	return typ
+}
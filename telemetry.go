@@ -0,0 +1,39 @@
+package kong
+
+import "sort"
+
+// UsageSummary is an anonymised summary of a successfully parsed command-line.
+//
+// It contains no values, so is safe to report for measuring which commands and flags are used.
+type UsageSummary struct {
+	// Command is the selected command path, eg. "user create <id>".
+	Command string `json:"command,omitempty"`
+	// Flags contains the names of flags explicitly set on the command-line, sorted.
+	Flags []string `json:"flags,omitempty"`
+}
+
+// NewUsageSummary creates a UsageSummary from a parsed Context.
+func NewUsageSummary(ctx *Context) UsageSummary {
+	summary := UsageSummary{Command: ctx.Command()}
+	seen := map[string]bool{}
+	for _, path := range ctx.Path {
+		if path.Flag == nil || path.Resolved || seen[path.Flag.Name] {
+			continue
+		}
+		seen[path.Flag.Name] = true
+		summary.Flags = append(summary.Flags, path.Flag.Name)
+	}
+	sort.Strings(summary.Flags)
+	return summary
+}
+
+// UsageTelemetry calls "report" with a UsageSummary after each successful parse.
+//
+// This is opt-in, and "report" is responsible for any consent checks and for delivery. It should
+// not block, as it is called synchronously from Parse().
+func UsageTelemetry(report func(UsageSummary)) Option {
+	return OptionFunc(func(k *Kong) error {
+		k.usageTelemetry = report
+		return nil
+	})
+}
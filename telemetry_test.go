@@ -0,0 +1,35 @@
+package kong_test
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestUsageTelemetry(t *testing.T) {
+	var cli struct {
+		Verbose bool
+		Region  string `env:"KONG_TEST_REGION"`
+		User    struct {
+			Create struct {
+				ID    string `arg:""`
+				Admin bool
+				Token string
+			} `cmd:""`
+		} `cmd:""`
+	}
+	t.Setenv("KONG_TEST_REGION", "eu")
+	var reports []kong.UsageSummary
+	p := mustNew(t, &cli, kong.UsageTelemetry(func(summary kong.UsageSummary) {
+		reports = append(reports, summary)
+	}))
+	_, err := p.Parse([]string{"user", "create", "alice", "--token=hunter2", "--verbose", "--admin"})
+	assert.NoError(t, err)
+	_, err = p.Parse([]string{"user", "create"})
+	assert.Error(t, err)
+	assert.Equal(t, []kong.UsageSummary{
+		{Command: "user create <id>", Flags: []string{"admin", "token", "verbose"}},
+	}, reports)
+}
@@ -0,0 +1,43 @@
+package kong
+
+import (
+	"io"
+	"os"
+)
+
+// Term describes the capabilities of the terminal that Kong's stdout and stderr write to.
+//
+// It is detected once per Context and bound for injection into Run() methods and hooks, so commands can make the
+// same decisions as help rendering, eg. whether to draw progress bars or use color.
+type Term struct {
+	StdoutTTY   bool // Stdout is a terminal.
+	StderrTTY   bool // Stderr is a terminal.
+	Width       int  // Width of stdout in columns, from $COLUMNS or the terminal, or 80 if unknown.
+	Color       bool // ANSI color may be written to stdout.
+	StderrColor bool // ANSI color may be written to stderr.
+}
+
+// detectTerm probes stdout and stderr.
+//
+// Color is enabled for terminals unless $NO_COLOR is set or $TERM is "dumb", and is forced by $FORCE_COLOR.
+func detectTerm(stdout, stderr io.Writer) Term {
+	term := Term{
+		StdoutTTY: isTerminal(stdout),
+		StderrTTY: isTerminal(stderr),
+		Width:     guessWidth(stdout),
+	}
+	color := os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+	force := os.Getenv("FORCE_COLOR") != ""
+	term.Color = force || (color && term.StdoutTTY)
+	term.StderrColor = force || (color && term.StderrTTY)
+	return term
+}
+
+// Term returns the capabilities of the terminal, detected when the Context was created.
+func (c *Context) Term() Term {
+	if c.term == nil {
+		term := detectTerm(c.Stdout, c.Stderr)
+		c.term = &term
+	}
+	return *c.term
+}
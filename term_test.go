@@ -0,0 +1,43 @@
+package kong_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type termCmd struct {
+	term kong.Term
+}
+
+func (c *termCmd) Run(term kong.Term) error {
+	c.term = term
+	return nil
+}
+
+func TestTermBinding(t *testing.T) {
+	t.Setenv("COLUMNS", "123")
+	t.Setenv("FORCE_COLOR", "")
+	var cli struct {
+		Cmd termCmd `cmd:""`
+	}
+	p := mustNew(t, &cli, kong.Writers(&bytes.Buffer{}, &bytes.Buffer{}))
+	ctx, err := p.Parse([]string{"cmd"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.Equal(t, kong.Term{Width: 123}, cli.Cmd.term)
+	assert.Equal(t, cli.Cmd.term, ctx.Term())
+}
+
+func TestTermForceColor(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	t.Setenv("FORCE_COLOR", "1")
+	var cli struct{}
+	p := mustNew(t, &cli, kong.Writers(&bytes.Buffer{}, &bytes.Buffer{}))
+	ctx, err := p.Parse(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, kong.Term{Width: 80, Color: true, StderrColor: true}, ctx.Term())
+}
@@ -0,0 +1,84 @@
+package kong
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Tristate is a flag value that is auto, true or false, for settings where "unset" and "disabled" must be
+// distinguished from a third, automatic, state, eg. colour output.
+//
+// It is auto unless set. "--flag" sets it to true, "--flag=auto", "--flag=true" and "--flag=false" set it
+// explicitly, and if the flag is negatable "--no-flag" sets it to false.
+type Tristate int
+
+// Tristate values.
+const (
+	TristateAuto Tristate = iota
+	TristateTrue
+	TristateFalse
+)
+
+var (
+	_ BoolMapperValue = (*Tristate)(nil)
+
+	tristateType = reflect.TypeOf(TristateAuto)
+)
+
+// Decode implements MapperValue.
+func (t *Tristate) Decode(ctx *DecodeContext) error {
+	if ctx.Scan.Peek().Type != FlagValueToken {
+		*t = TristateTrue
+		return nil
+	}
+	token := ctx.Scan.Pop()
+	switch v := token.Value.(type) {
+	case string:
+		switch strings.ToLower(v) {
+		case "auto":
+			*t = TristateAuto
+		case "true", "1", "yes":
+			*t = TristateTrue
+		case "false", "0", "no":
+			*t = TristateFalse
+		default:
+			return fmt.Errorf("value must be auto, true or false but got %q", v)
+		}
+	case bool:
+		if v {
+			*t = TristateTrue
+		} else {
+			*t = TristateFalse
+		}
+	default:
+		return fmt.Errorf("expected auto, true or false but got %q (%T)", token.Value, token.Value)
+	}
+	return nil
+}
+
+// IsBool implements BoolMapperValue, so the flag may be given without a value.
+func (t Tristate) IsBool() bool { return true }
+
+// Bool returns the value of the Tristate, or "auto" if it is TristateAuto.
+func (t Tristate) Bool(auto bool) bool {
+	switch t {
+	case TristateTrue:
+		return true
+	case TristateFalse:
+		return false
+	default:
+		return auto
+	}
+}
+
+func (t Tristate) String() string {
+	switch t {
+	case TristateTrue:
+		return "true"
+	case TristateFalse:
+		return "false"
+	default:
+		return "auto"
+	}
+}
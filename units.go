@@ -0,0 +1,119 @@
+package kong
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// unitFamilies lists the units accepted by the "unit" tag, each family mapping units to their size in its smallest
+// unit. Values may be given in any unit of the family of the tagged unit.
+var unitFamilies = []map[string]float64{
+	{"ns": 1, "us": 1e3, "µs": 1e3, "ms": 1e6, "s": 1e9, "m": 60e9, "h": 3600e9},
+	{"B": 1, "KB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12, "KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40},
+}
+
+func unitFamily(unit string) map[string]float64 {
+	for _, family := range unitFamilies {
+		if _, ok := family[unit]; ok {
+			return family
+		}
+	}
+	return nil
+}
+
+// unitMapper decodes numbers with an optional unit suffix, eg. "250ms" or "2s", into a numeric field holding a
+// quantity of "unit". Numbers without a suffix are in "unit".
+func unitMapper(unit string) MapperFunc {
+	family := unitFamily(unit)
+	return func(ctx *DecodeContext, target reflect.Value) error {
+		t, err := ctx.Scan.PopValue("value")
+		if err != nil {
+			return err
+		}
+		raw := fmt.Sprintf("%v", t.Value)
+		number, suffix := raw, unit
+		if i := strings.LastIndexFunc(raw, func(r rune) bool { return !unicode.IsLetter(r) }); i < len(raw)-1 {
+			number, suffix = raw[:i+1], raw[i+1:]
+		}
+		factor, ok := family[suffix]
+		if !ok {
+			return ctx.errorf(MessageUnknownUnit, suffix, raw, strings.Join(familyUnits(family), ","))
+		}
+		n, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return ctx.errorf(MessageUnitNumber, raw)
+		}
+		n = n * factor / family[unit]
+		if r := math.Round(n); math.Abs(n-r) < 1e-9*math.Max(1, math.Abs(r)) {
+			n = r
+		}
+		// Ranges are checked before converting, as converting an out of range float is implementation-defined.
+		switch target.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if target.OverflowFloat(n) {
+				return ctx.errorf(MessageOutOfRange, raw, target.Type())
+			}
+			target.SetFloat(n)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n != math.Trunc(n) || n < -math.MaxInt64-1 || n >= math.MaxInt64 || target.OverflowInt(int64(n)) {
+				return ctx.errorf(MessageUnitWholeNumber, raw, unit, target.Type())
+			}
+			target.SetInt(int64(n))
+		default:
+			if n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 || target.OverflowUint(uint64(n)) {
+				return ctx.errorf(MessageUnitWholeNumber, raw, unit, target.Type())
+			}
+			target.SetUint(uint64(n))
+		}
+		return nil
+	}
+}
+
+// familyUnits returns the units of "family" from smallest to largest.
+func familyUnits(family map[string]float64) []string {
+	units := []string{}
+	for unit := range family {
+		units = append(units, unit)
+	}
+	sort.Slice(units, func(i, j int) bool {
+		if family[units[i]] != family[units[j]] {
+			return family[units[i]] < family[units[j]]
+		}
+		return units[i] < units[j]
+	})
+	return units
+}
+
+// unitFormatter formats quantities of "unit" in the largest unit of its family that represents them as a whole
+// number, eg. 5400000 with unit "ms" as "90m", or 1048576 with unit "B" as "1MiB".
+func unitFormatter(unit string) func(reflect.Value) string {
+	family := unitFamily(unit)
+	return func(v reflect.Value) string {
+		var n float64
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			n = v.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(v.Uint())
+		default:
+			return fmt.Sprintf("%v%s", v.Interface(), unit)
+		}
+		best := unit
+		if n != 0 && n == math.Trunc(n) {
+			total := n * family[unit]
+			for _, candidate := range familyUnits(family) {
+				if family[candidate] > family[best] && math.Mod(total, family[candidate]) == 0 {
+					best = candidate
+				}
+			}
+		}
+		return strconv.FormatFloat(n*family[unit]/family[best], 'f', -1, 64) + best
+	}
+}
@@ -0,0 +1,67 @@
+package kong
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+// checkUnusedConfig applies the UnusedConfigKeys policy to the resolvers of "ctx".
+func (k *Kong) checkUnusedConfig(ctx *Context) error {
+	if k.unusedConfig == IgnoreUnusedConfig {
+		return nil
+	}
+	unused := []string{}
+	for _, resolver := range ctx.combineResolvers() {
+		if reporter, ok := resolver.(UnusedKeysReporter); ok {
+			unused = append(unused, reporter.UnusedKeys(ctx)...)
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	return k.reportUnused(k.unusedConfig, k.messages.sprintf(MessageUnusedConfig, strings.Join(unused, ", ")))
+}
+
+// checkUnusedEnvars applies the UnusedEnvars policy to the environment.
+func (k *Kong) checkUnusedEnvars() error {
+	if k.unusedEnvars == IgnoreUnusedConfig {
+		return nil
+	}
+	known := map[string]bool{}
+	_ = Visit(k.Model, func(node Visitable, next Next) error {
+		switch node := node.(type) {
+		case *Value:
+			for _, env := range node.Tag.Envs {
+				known[env] = true
+			}
+		case *Flag:
+			for _, env := range node.Tag.Envs {
+				known[env] = true
+			}
+		}
+		return next(nil)
+	})
+	unused := []string{}
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, k.unusedEnvPrefix) && !known[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	return k.reportUnused(k.unusedEnvars, k.messages.sprintf(MessageUnusedEnvars, strings.Join(unused, ", ")))
+}
+
+// reportUnused returns "msg" as an error, or writes it as a warning, according to "policy".
+func (k *Kong) reportUnused(policy UnusedConfigPolicy, msg string) error {
+	if policy == RejectUnusedConfig {
+		return errors.New(msg)
+	}
+	formatMultilineMessage(k.Stderr, []string{k.Model.Name, "warning"}, "%s", msg)
+	return nil
+}
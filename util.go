@@ -27,6 +27,8 @@ func (c ConfigFlag) BeforeResolve(kong *Kong, ctx *Context, trace *Path) error {
 }
 
 // VersionFlag is a flag type that can be used to display a version number, stored in the "version" variable.
+//
+// Use the AutoVersion() option to populate the "version" variable from the binary's build information.
 type VersionFlag bool
 
 // BeforeReset writes the version variable and terminates with a 0 exit status.
@@ -55,3 +57,24 @@ func (c ChangeDirFlag) Decode(ctx *DecodeContext) error {
 	ctx.Value.Target.Set(reflect.ValueOf(ChangeDirFlag(path)))
 	return os.Chdir(path)
 }
+
+// DryRun is bound for injection into Run() methods, and is true if a DryRunFlag was set.
+type DryRun bool
+
+// DryRunFlag is a flag type that asks commands to report what they would do, without doing it.
+//
+// Run() methods observe it by accepting a kong.DryRun parameter, so it can be declared once on the application and
+// honoured by every command. A standard help is used if the flag has none.
+type DryRunFlag bool
+
+const dryRunHelp = "Show what would be done, without making any changes."
+
+// dryRun returns whether any DryRunFlag in the Context is set.
+func (c *Context) dryRun() DryRun {
+	for _, flag := range c.Flags() {
+		if set, ok := flag.Target.Interface().(DryRunFlag); ok && bool(set) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package kong
+
+import (
+	"log/slog"
+)
+
+// VerbosityFlags are standard flags controlling the level of log messages. Embed them in the application.
+//
+// After the flags are applied, a *slog.Logger writing to Kong's stderr at the selected level is bound for
+// injection into Run() methods and later hooks.
+type VerbosityFlags struct {
+	Verbose  int    `short:"v" type:"counter" xor:"verbosity" help:"Log more detail, repeat for more (-vv)."`
+	Quiet    bool   `short:"q" xor:"verbosity" help:"Only log errors."`
+	LogLevel string `enum:"debug,info,warn,error" default:"info" placeholder:"LEVEL" help:"Minimum level of log messages (${enum})."`
+}
+
+// Level returns the minimum level of log messages selected by the flags.
+//
+// Each -v lowers the level given by --log-level by one step, eg. from info to debug.
+func (v VerbosityFlags) Level() slog.Level {
+	if v.Quiet {
+		return slog.LevelError
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(v.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+	return level - slog.Level(4*v.Verbose)
+}
+
+// AfterApply binds a *slog.Logger at the selected level.
+func (v *VerbosityFlags) AfterApply(ctx *Context) error {
+	ctx.Bind(slog.New(slog.NewTextHandler(ctx.Kong.Stderr, &slog.HandlerOptions{Level: v.Level()})))
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build go1.21
+// +build go1.21
+
+package kong_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/kong"
+)
+
+type verbosityCmd struct{}
+
+func (verbosityCmd) Run(logger *slog.Logger) error {
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+	return nil
+}
+
+func TestVerbosityFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		level    slog.Level
+		messages []string
+	}{
+		{"Default", nil, slog.LevelInfo, []string{"info", "error"}},
+		{"Verbose", []string{"-v"}, slog.LevelDebug, []string{"debug", "info", "error"}},
+		{"VeryVerbose", []string{"-vv"}, slog.LevelDebug - 4, []string{"debug", "info", "error"}},
+		{"Quiet", []string{"--quiet"}, slog.LevelError, []string{"error"}},
+		{"LogLevel", []string{"--log-level=warn"}, slog.LevelWarn, []string{"error"}},
+		{"LogLevelVerbose", []string{"--log-level=error", "-v"}, slog.LevelWarn, []string{"error"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cli struct {
+				kong.VerbosityFlags
+				Cmd verbosityCmd `cmd:"" default:"1"`
+			}
+			stderr := &bytes.Buffer{}
+			p := mustNew(t, &cli, kong.Writers(&bytes.Buffer{}, stderr))
+			ctx, err := p.Parse(test.args)
+			assert.NoError(t, err)
+			assert.Equal(t, test.level, cli.Level())
+			assert.NoError(t, ctx.Run())
+			for _, message := range []string{"debug", "info", "error"} {
+				expected := false
+				for _, m := range test.messages {
+					expected = expected || m == message
+				}
+				assert.Equal(t, expected, bytes.Contains(stderr.Bytes(), []byte("msg="+message)), message)
+			}
+		})
+	}
+}
+
+func TestVerbosityFlagsConflict(t *testing.T) {
+	var cli struct {
+		kong.VerbosityFlags
+	}
+	p := mustNew(t, &cli)
+	_, err := p.Parse([]string{"-v", "--quiet"})
+	assert.EqualError(t, err, "--verbose and --quiet can't be used together")
+}
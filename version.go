@@ -0,0 +1,89 @@
+package kong
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+)
+
+// AutoVersion populates the "version", "commit" and "build_date" variables from the build information embedded in
+// the binary by the Go toolchain, if they are not otherwise supplied via Vars.
+//
+// The commit and build date are only available if the binary was built with VCS stamping enabled (the default
+// when building from within a repository).
+func AutoVersion() Option {
+	return OptionFunc(func(k *Kong) error {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return nil
+		}
+		for key, value := range buildInfoVars(info) {
+			if _, ok := k.vars[key]; !ok {
+				k.vars[key] = value
+			}
+		}
+		return nil
+	})
+}
+
+func buildInfoVars(info *debug.BuildInfo) Vars {
+	vars := Vars{}
+	settings := map[string]string{}
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+	if revision := settings["vcs.revision"]; revision != "" {
+		if settings["vcs.modified"] == "true" {
+			revision += "-dirty"
+		}
+		vars["commit"] = revision
+	}
+	if date := settings["vcs.time"]; date != "" {
+		vars["build_date"] = date
+	}
+	switch version := info.Main.Version; {
+	case version != "" && version != "(devel)":
+		vars["version"] = version
+	case vars["commit"] != "":
+		commit := vars["commit"]
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		vars["version"] = "devel-" + commit
+	default:
+		vars["version"] = "devel"
+	}
+	return vars
+}
+
+// VersionCommand is a command that displays the version information stored in the "version", "commit" and
+// "build_date" variables, as populated by Vars or AutoVersion().
+//
+//	var cli struct {
+//		Version kong.VersionCommand `cmd:"" help:"Show version information."`
+//	}
+type VersionCommand struct {
+	Output string `help:"Output format (${enum})." enum:"text,json" default:"text"`
+}
+
+// Run writes the version information to Kong.Stdout.
+func (v VersionCommand) Run(app *Kong, vars Vars) error {
+	if v.Output == "json" {
+		enc := json.NewEncoder(app.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Version   string `json:"version,omitempty"`
+			Commit    string `json:"commit,omitempty"`
+			BuildDate string `json:"build_date,omitempty"`
+		}{vars["version"], vars["commit"], vars["build_date"]})
+	}
+	out := vars["version"]
+	switch {
+	case vars["commit"] != "" && vars["build_date"] != "":
+		out += fmt.Sprintf(" (commit %s, built %s)", vars["commit"], vars["build_date"])
+	case vars["commit"] != "":
+		out += fmt.Sprintf(" (commit %s)", vars["commit"])
+	}
+	_, err := fmt.Fprintln(app.Stdout, out)
+	return err
+}
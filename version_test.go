@@ -0,0 +1,59 @@
+package kong
+
+import (
+	"bytes"
+	"runtime/debug"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestBuildInfoVars(t *testing.T) {
+	info := &debug.BuildInfo{
+		Main: debug.Module{Version: "(devel)"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+			{Key: "vcs.time", Value: "2024-01-02T03:04:05Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	assert.Equal(t, Vars{
+		"version":    "devel-0123456789ab",
+		"commit":     "0123456789abcdef0123-dirty",
+		"build_date": "2024-01-02T03:04:05Z",
+	}, buildInfoVars(info))
+
+	info = &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}}
+	assert.Equal(t, Vars{"version": "v1.2.3"}, buildInfoVars(info))
+}
+
+func TestAutoVersionDoesNotOverrideVars(t *testing.T) {
+	var cli struct{}
+	p, err := New(&cli, Vars{"version": "1.0.0"}, AutoVersion())
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", p.vars["version"])
+
+	p, err = New(&cli, AutoVersion())
+	assert.NoError(t, err)
+	assert.NotZero(t, p.vars["version"])
+}
+
+func TestVersionCommand(t *testing.T) {
+	var cli struct {
+		Version VersionCommand `cmd:""`
+	}
+	w := &bytes.Buffer{}
+	p, err := New(&cli, Writers(w, w), Vars{"version": "1.0.0", "commit": "abc"})
+	assert.NoError(t, err)
+
+	ctx, err := p.Parse([]string{"version"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.Equal(t, "1.0.0 (commit abc)\n", w.String())
+
+	w.Reset()
+	ctx, err = p.Parse([]string{"version", "--output=json"})
+	assert.NoError(t, err)
+	assert.NoError(t, ctx.Run())
+	assert.Equal(t, "{\n  \"version\": \"1.0.0\",\n  \"commit\": \"abc\"\n}\n", w.String())
+}
@@ -0,0 +1,99 @@
+package kong
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// applyVisibility evaluates "enabled" and "hidden" conditions against the interpolation
+// variables in scope, removing disabled commands and flags from the model.
+//
+// A disabled default command is no longer the default, so its parent then requires a command. A hidden default
+// command remains the default, as hidden commands are still selectable.
+func (k *Kong) applyVisibility(node *Node, vars Vars) error {
+	vars = vars.CloneWith(node.Tag.Vars)
+	flags := node.Flags[:0]
+	for _, flag := range node.Flags {
+		enabled, err := evalCondition(flag.Tag.Enabled, true, vars.CloneWith(flag.Tag.Vars))
+		if err != nil {
+			return fmt.Errorf("enabled for %s: %w", flag.Summary(), err)
+		}
+		if !enabled {
+			continue
+		}
+		if flag.Hidden, err = evalHidden(flag.Tag.Get("hidden"), flag.Hidden, vars.CloneWith(flag.Tag.Vars)); err != nil {
+			return fmt.Errorf("hidden for %s: %w", flag.Summary(), err)
+		}
+		flags = append(flags, flag)
+	}
+	node.Flags = flags
+
+	children := node.Children[:0]
+	for _, child := range node.Children {
+		childVars := vars.CloneWith(child.Tag.Vars)
+		enabled, err := evalCondition(child.Tag.Enabled, true, childVars)
+		if err != nil {
+			return fmt.Errorf("enabled for %s: %w", child.Path(), err)
+		}
+		if !enabled {
+			if node.DefaultCmd == child {
+				node.DefaultCmd = nil
+			}
+			continue
+		}
+		if child.Hidden, err = evalHidden(child.Tag.Get("hidden"), child.Hidden, childVars); err != nil {
+			return fmt.Errorf("hidden for %s: %w", child.Path(), err)
+		}
+		if err := k.applyVisibility(child, vars); err != nil {
+			return err
+		}
+		children = append(children, child)
+	}
+	node.Children = children
+	return nil
+}
+
+// evalHidden evaluates a "hidden" tag. Only "${name}" and "${!name}" are conditions; any other value, such as
+// hidden:"" or hidden:"yes", leaves "hidden" as set by the presence of the tag.
+func evalHidden(expr string, hidden bool, vars Vars) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "${") || !strings.HasSuffix(expr, "}") {
+		return hidden, nil
+	}
+	return evalCondition(expr, hidden, vars)
+}
+
+// evalCondition evaluates a feature gate expression.
+//
+// The expression may be a boolean literal, "${name}" or "${!name}". Variables are looked up in
+// "vars" and then the environment, with undefined variables treated as false. An empty
+// expression evaluates to "otherwise".
+func evalCondition(expr string, otherwise bool, vars Vars) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return otherwise, nil
+	}
+	negate := false
+	if strings.HasPrefix(expr, "${") && strings.HasSuffix(expr, "}") {
+		name := expr[2 : len(expr)-1]
+		if strings.HasPrefix(name, "!") {
+			negate = true
+			name = name[1:]
+		}
+		value, ok := vars[name]
+		if !ok {
+			value = os.Getenv(name)
+		}
+		if value == "" {
+			return negate, nil
+		}
+		expr = value
+	}
+	value, err := strconv.ParseBool(expr)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q", expr)
+	}
+	return value != negate, nil
+}